@@ -6,12 +6,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"os"
 	"runtime"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/dedene/ponto-cli/internal/auth"
+	"github.com/dedene/ponto-cli/internal/config"
 	pontoCtx "github.com/dedene/ponto-cli/internal/ctx"
 )
 
@@ -25,10 +30,68 @@ var version = "dev"
 
 // Client is the Ponto API client.
 type Client struct {
-	httpClient   *http.Client
+	httpClient *http.Client
+	baseURL    string
+	clientID   string
+	tokens     tokenSource
+	signer     *Signer
+	timeout    time.Duration
+
+	tokenMu sync.Mutex
+	token   *auth.Token
+}
+
+// tokenSource provides access tokens for a client and evicts tokens the API
+// has rejected.
+type tokenSource interface {
+	Token(ctx context.Context) (*auth.Token, error)
+	Invalidate(token *auth.Token)
+}
+
+// profileTokenSource issues client-credentials tokens for a profile, backed
+// by the in-process and persistent token caches. After a secret rotation it
+// falls back to the previous secret while the new one is rejected.
+type profileTokenSource struct {
+	profile      string
 	clientID     string
 	clientSecret string
-	timeout      time.Duration
+	store        auth.Store // nil for environment credentials
+}
+
+func (s *profileTokenSource) Token(ctx context.Context) (*auth.Token, error) {
+	token, err := auth.GetProfileToken(ctx, s.profile, s.clientID, s.clientSecret)
+	if err == nil || s.store == nil {
+		return token, err
+	}
+
+	previous := auth.PreviousSecret(s.store, s.profile)
+	if previous == "" {
+		return nil, err
+	}
+
+	slog.Warn("rotated client secret rejected, using previous secret", "profile", s.profile, "error", err)
+
+	return auth.GetProfileToken(ctx, s.profile, s.clientID, previous)
+}
+
+func (s *profileTokenSource) Invalidate(*auth.Token) {
+	auth.InvalidateToken(s.profile, s.clientID)
+}
+
+// connectTokenSource issues Ponto Connect tokens for a profile by redeeming
+// the refresh token held in the credential store.
+type connectTokenSource struct {
+	profile string
+	flow    *auth.ConnectFlow
+	store   auth.Store
+}
+
+func (s *connectTokenSource) Token(ctx context.Context) (*auth.Token, error) {
+	return auth.GetConnectToken(ctx, s.profile, s.flow, s.store)
+}
+
+func (s *connectTokenSource) Invalidate(*auth.Token) {
+	auth.InvalidateToken(s.profile, s.flow.ClientID)
 }
 
 // NewClientFromContext creates a client from context.
@@ -37,43 +100,146 @@ func NewClientFromContext(ctx context.Context) (*Client, error) {
 	timeout := pontoCtx.TimeoutFrom(ctx)
 	noRetry := pontoCtx.NoRetryFrom(ctx)
 
-	store, err := auth.OpenKeyring()
+	// The credential store is opened only when needed, so environment credentials
+	// work without one.
+	openStore := sync.OnceValues(func() (auth.Store, error) {
+		store, err := auth.OpenStore()
+		if err != nil {
+			return nil, fmt.Errorf("open credential store: %w", err)
+		}
+
+		return store, nil
+	})
+
+	source := &profileTokenSource{profile: profile}
+
+	clientID, clientSecret, ok := auth.EnvCredentials()
+	if !ok {
+		store, err := openStore()
+		if err != nil {
+			return nil, err
+		}
+
+		clientID, clientSecret, err = store.GetCredentials(profile)
+		if err != nil {
+			return nil, fmt.Errorf("get credentials for profile %q: %w\nRun 'ponto auth login' to authenticate", profile, err)
+		}
+
+		source.store = store
+	}
+
+	source.clientID, source.clientSecret = clientID, clientSecret
+
+	cfg, err := config.ReadConfig()
 	if err != nil {
-		return nil, fmt.Errorf("open keyring: %w", err)
+		return nil, err
+	}
+
+	var (
+		base   http.RoundTripper = http.DefaultTransport
+		tokens tokenSource       = source
+	)
+
+	if cp := cfg.Profiles[profile].Connect; cp != nil {
+		store, err := openStore()
+		if err != nil {
+			return nil, err
+		}
+
+		flow, err := auth.NewConnectFlow(cp, clientID, clientSecret, auth.ConnectPassphrase(store, profile))
+		if err != nil {
+			return nil, fmt.Errorf("profile %q: %w", profile, err)
+		}
+
+		base = flow.Transport
+		tokens = &connectTokenSource{profile: profile, flow: flow, store: store}
 	}
 
-	clientID, clientSecret, err := store.GetCredentials(profile)
+	signer, err := newProfileSigner(profile, cfg.Profiles[profile].Signing, openStore)
 	if err != nil {
-		return nil, fmt.Errorf("get credentials for profile %q: %w\nRun 'ponto auth login' to authenticate", profile, err)
+		return nil, err
 	}
 
-	transport := NewRetryTransport(http.DefaultTransport, noRetry)
+	settings := cfg.SettingsFor(profile)
+
+	retry := NewRetryTransport(base, noRetry)
+	if settings.MaxRetries != nil {
+		retry.MaxRetries = *settings.MaxRetries
+	}
+
+	if settings.RetryDelay > 0 {
+		retry.BaseDelay = settings.RetryDelay
+	}
+
+	apiURL := baseURL
+	if u := pontoCtx.BaseURLFrom(ctx); u != "" {
+		apiURL = strings.TrimSuffix(u, "/")
+	}
 
 	return &Client{
 		httpClient: &http.Client{
-			Transport: transport,
+			Transport: retry,
 			Timeout:   timeout,
 		},
-		clientID:     clientID,
-		clientSecret: clientSecret,
-		timeout:      timeout,
+		baseURL:  apiURL,
+		clientID: clientID,
+		tokens:   tokens,
+		signer:   signer,
+		timeout:  timeout,
 	}, nil
 }
 
-func (c *Client) do(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
-	token, err := auth.GetAccessToken(ctx, c.clientID, c.clientSecret)
+// newProfileSigner builds the request signer configured for a profile, or
+// returns nil when signing is not configured.
+func newProfileSigner(profile string, sp *config.SigningProfile, openStore func() (auth.Store, error)) (*Signer, error) {
+	if sp == nil {
+		return nil, nil
+	}
+
+	var (
+		pemKey []byte
+		err    error
+	)
+
+	if sp.KeyFile != "" {
+		pemKey, err = os.ReadFile(sp.KeyFile)
+	} else {
+		var (
+			store auth.Store
+			key   string
+		)
+
+		if store, err = openStore(); err == nil {
+			key, err = auth.SigningKey(store, profile)
+			pemKey = []byte(key)
+		}
+	}
+
 	if err != nil {
-		return nil, fmt.Errorf("get access token: %w", err)
+		return nil, fmt.Errorf("load signing key for profile %q: %w", profile, err)
 	}
 
-	u := baseURL + path
+	key, err := ParseSigningKey(pemKey)
+	if err != nil {
+		return nil, fmt.Errorf("profile %q: %w", profile, err)
+	}
+
+	return &Signer{
+		KeyID:     sp.KeyID,
+		Key:       key,
+		Algorithm: sp.Algorithm,
+		Paths:     sp.Paths,
+	}, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
+	u := c.baseURL + path
 
 	req, err := http.NewRequestWithContext(ctx, method, u, body)
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("User-Agent", fmt.Sprintf("%s/%s (%s/%s)", userAgent, version, runtime.GOOS, runtime.GOARCH))
 
@@ -81,14 +247,116 @@ func (c *Client) do(ctx context.Context, method, path string, body io.Reader) (*
 		req.Header.Set("Content-Type", "application/json")
 	}
 
-	resp, err := c.httpClient.Do(req)
+	// Buffer the body so the request can be replayed after a token refresh
+	if err := ensureReplayableBody(req); err != nil {
+		return nil, err
+	}
+
+	if c.signer != nil && c.signer.Matches(req.URL.Path) {
+		if err := c.signer.Sign(req); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, token, err := c.send(req)
 	if err != nil {
-		return nil, fmt.Errorf("request: %w", err)
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil
+	}
+
+	// The token was revoked or expired mid-run: evict it, fetch a fresh one
+	// and replay the request once.
+	slog.Debug("access token rejected, refreshing", "method", method, "path", path)
+
+	drainAndClose(resp.Body)
+	c.invalidateToken(token)
+
+	if req.GetBody != nil {
+		replay, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("reset request body: %w", err)
+		}
+
+		req.Body = replay
+	}
+
+	resp, token, err = c.send(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		c.invalidateToken(token)
 	}
 
 	return resp, nil
 }
 
+// send authorizes req with the current access token and sends it.
+func (c *Client) send(req *http.Request) (*http.Response, *auth.Token, error) {
+	token, err := c.accessToken(req.Context())
+	if err != nil {
+		return nil, nil, fmt.Errorf("get access token: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("request: %w", err)
+	}
+
+	return resp, token, nil
+}
+
+// ClientID returns the client ID the client authenticates with.
+func (c *Client) ClientID() string {
+	return c.clientID
+}
+
+// Token returns the access token the client authenticates with, fetching
+// one when there is none or it is about to expire.
+func (c *Client) Token(ctx context.Context) (*auth.Token, error) {
+	return c.accessToken(ctx)
+}
+
+// accessToken returns the client's cached token, fetching one when there is
+// none or it is about to expire.
+func (c *Client) accessToken(ctx context.Context) (*auth.Token, error) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	if c.token != nil && !c.token.IsExpired() {
+		return c.token, nil
+	}
+
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.token = token
+
+	return token, nil
+}
+
+// invalidateToken evicts a rejected token from this client and from the
+// shared caches, unless another request already replaced it.
+func (c *Client) invalidateToken(token *auth.Token) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	if c.token != nil && token != nil && c.token.AccessToken != token.AccessToken {
+		return
+	}
+
+	c.token = nil
+	c.tokens.Invalidate(token)
+}
+
 func (c *Client) get(ctx context.Context, path string) (*http.Response, error) {
 	return c.do(ctx, http.MethodGet, path, nil)
 }
@@ -218,6 +486,23 @@ const maxPageSize = 100
 
 // ListTransactions returns transactions for an account.
 func (c *Client) ListTransactions(ctx context.Context, accountID string, opts TransactionListOptions) ([]Transaction, error) {
+	var allTransactions []Transaction
+
+	err := c.EachTransactionPage(ctx, accountID, opts, func(page []Transaction) error {
+		allTransactions = append(allTransactions, page...)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return allTransactions, nil
+}
+
+// EachTransactionPage calls fn with each page of transactions as soon as it
+// is decoded, stopping once opts.Limit transactions have been delivered.
+func (c *Client) EachTransactionPage(ctx context.Context, accountID string, opts TransactionListOptions, fn func([]Transaction) error) error {
 	basePath := fmt.Sprintf("/accounts/%s/transactions", accountID)
 
 	params := url.Values{}
@@ -230,43 +515,50 @@ func (c *Client) ListTransactions(ctx context.Context, accountID string, opts Tr
 	params.Set("limit", fmt.Sprintf("%d", pageSize))
 
 	if opts.Since != "" {
-		since, err := parseDate(opts.Since)
+		since, err := ParseDate(opts.Since)
 		if err != nil {
-			return nil, fmt.Errorf("invalid since date: %w", err)
+			return fmt.Errorf("invalid since date: %w", err)
 		}
 
 		params.Set("filter[valueDate][gte]", since)
 	}
 
 	if opts.Until != "" {
-		until, err := parseDate(opts.Until)
+		until, err := ParseDate(opts.Until)
 		if err != nil {
-			return nil, fmt.Errorf("invalid until date: %w", err)
+			return fmt.Errorf("invalid until date: %w", err)
 		}
 
 		params.Set("filter[valueDate][lte]", until)
 	}
 
 	path := basePath + "?" + params.Encode()
-
-	var allTransactions []Transaction
+	delivered := 0
 
 	for {
 		resp, err := c.get(ctx, path)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		transactions, nextPath, err := decodeTransactionPage(resp)
 		if err != nil {
-			return nil, err
+			return err
+		}
+
+		// Trim the page to the desired limit
+		if opts.Limit > 0 && delivered+len(transactions) > opts.Limit {
+			transactions = transactions[:opts.Limit-delivered]
 		}
 
-		allTransactions = append(allTransactions, transactions...)
+		if err := fn(transactions); err != nil {
+			return err
+		}
+
+		delivered += len(transactions)
 
 		// Stop if we've reached the desired limit
-		if opts.Limit > 0 && len(allTransactions) >= opts.Limit {
-			allTransactions = allTransactions[:opts.Limit]
+		if opts.Limit > 0 && delivered >= opts.Limit {
 			break
 		}
 
@@ -283,7 +575,7 @@ func (c *Client) ListTransactions(ctx context.Context, accountID string, opts Tr
 		}
 	}
 
-	return allTransactions, nil
+	return nil
 }
 
 // decodeTransactionPage decodes a page of transactions and returns the next page URL.
@@ -471,11 +763,21 @@ func (c *Client) GetOrganization(ctx context.Context) (*Organization, error) {
 	return decodeResponse[Organization](resp)
 }
 
-// parseDate converts a date string to ISO 8601 format (YYYY-MM-DD).
+// ListWebhookKeys returns the keys Ponto signs webhooks with.
+func (c *Client) ListWebhookKeys(ctx context.Context) ([]WebhookKey, error) {
+	resp, err := c.get(ctx, "/webhooks/keys")
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeListResponse(resp, func(k *WebhookKey, id string) { k.ID = id })
+}
+
+// ParseDate converts a date string to ISO 8601 format (YYYY-MM-DD).
 // Supports:
 //   - ISO 8601 dates: "2024-01-15"
 //   - Relative days: "-30d" (30 days ago), "-7d" (7 days ago)
-func parseDate(s string) (string, error) {
+func ParseDate(s string) (string, error) {
 	// Check if it's a relative date like "-30d"
 	if len(s) > 1 && s[0] == '-' && s[len(s)-1] == 'd' {
 		daysStr := s[1 : len(s)-1]
@@ -1,8 +1,16 @@
 package api
 
 import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/dedene/ponto-cli/internal/auth"
 )
 
 func TestParseDate(t *testing.T) {
@@ -58,23 +66,164 @@ func TestParseDate(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			got, err := parseDate(tt.input)
+			got, err := ParseDate(tt.input)
 
 			if tt.wantErr {
 				if err == nil {
-					t.Errorf("parseDate(%q) expected error, got nil", tt.input)
+					t.Errorf("ParseDate(%q) expected error, got nil", tt.input)
 				}
 
 				return
 			}
 
 			if err != nil {
-				t.Errorf("parseDate(%q) unexpected error: %v", tt.input, err)
+				t.Errorf("ParseDate(%q) unexpected error: %v", tt.input, err)
 				return
 			}
 
 			if got != tt.want {
-				t.Errorf("parseDate(%q) = %q, want %q", tt.input, got, tt.want)
+				t.Errorf("ParseDate(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+type fakeTokenSource struct {
+	tokens      []string
+	issued      int
+	invalidated []string
+}
+
+func (s *fakeTokenSource) Token(context.Context) (*auth.Token, error) {
+	tok := s.tokens[min(s.issued, len(s.tokens)-1)]
+	s.issued++
+
+	return &auth.Token{AccessToken: tok, ExpiresAt: time.Now().Add(time.Hour)}, nil
+}
+
+func (s *fakeTokenSource) Invalidate(token *auth.Token) {
+	s.invalidated = append(s.invalidated, token.AccessToken)
+}
+
+func newTestClient(srv *httptest.Server, tokens tokenSource) *Client {
+	return &Client{
+		httpClient: &http.Client{Transport: NewRetryTransport(srv.Client().Transport, true)},
+		baseURL:    srv.URL,
+		tokens:     tokens,
+	}
+}
+
+func TestClientRefreshesTokenOn401(t *testing.T) {
+	t.Parallel()
+
+	var bodies []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"data":{"id":"sync-1","type":"synchronization","attributes":{"status":"pending"}}}`))
+	}))
+	defer srv.Close()
+
+	tokens := &fakeTokenSource{tokens: []string{"stale", "fresh"}}
+	client := newTestClient(srv, tokens)
+
+	resp, err := client.post(context.Background(), "/synchronizations", strings.NewReader(`{"x":1}`))
+	if err != nil {
+		t.Fatalf("post() error = %v", err)
+	}
+
+	sync, err := decodeResponse[Synchronization](resp)
+	if err != nil {
+		t.Fatalf("decodeResponse() error = %v", err)
+	}
+
+	if sync.ID != "sync-1" {
+		t.Errorf("sync.ID = %q, want sync-1", sync.ID)
+	}
+
+	if len(bodies) != 2 || bodies[1] != `{"x":1}` {
+		t.Errorf("request bodies = %q, want the body replayed once", bodies)
+	}
+
+	if len(tokens.invalidated) != 1 || tokens.invalidated[0] != "stale" {
+		t.Errorf("invalidated = %v, want [stale]", tokens.invalidated)
+	}
+}
+
+func TestClientRetriesOnlyOnceOn401(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(srv, &fakeTokenSource{tokens: []string{"a", "b", "c"}})
+
+	resp, err := client.get(context.Background(), "/accounts")
+	if err != nil {
+		t.Fatalf("get() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", resp.StatusCode)
+	}
+
+	if calls != 2 {
+		t.Errorf("server calls = %d, want 2", calls)
+	}
+}
+
+func TestRetryTransportMaxRetries(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		maxRetries int
+		want       int
+	}{
+		{name: "zero disables retries", maxRetries: 0, want: 1},
+		{name: "one retry", maxRetries: 1, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var requests atomic.Int32
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				requests.Add(1)
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+			}))
+			defer srv.Close()
+
+			retry := NewRetryTransport(srv.Client().Transport, false)
+			retry.MaxRetries = tt.maxRetries
+			retry.BaseDelay = time.Millisecond
+
+			resp, err := (&http.Client{Transport: retry}).Get(srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp.Body.Close()
+
+			if got := int(requests.Load()); got != tt.want {
+				t.Errorf("requests = %d, want %d", got, tt.want)
 			}
 		})
 	}
@@ -0,0 +1,201 @@
+package api
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// Signature algorithms supported by Signer.
+const (
+	AlgorithmRSAPSSSHA512 = "rsa-pss-sha512"
+	AlgorithmRSAv15SHA256 = "rsa-v1_5-sha256"
+)
+
+const (
+	signatureLabel         = "sig1"
+	signatureRequestTarget = "@request-target"
+	signatureDigestHeader  = "digest"
+	signatureParamsName    = "@signature-params"
+)
+
+// DefaultSignedPaths are the Ponto Connect payment endpoints that require
+// signed requests.
+var DefaultSignedPaths = []string{
+	"/accounts/*/payments",
+	"/accounts/*/payments/*",
+	"/accounts/*/bulk-payments",
+	"/accounts/*/bulk-payments/*",
+	"/accounts/*/payment-activation-requests",
+}
+
+var errNoPEMKey = errors.New("no PEM private key found")
+
+// Signer signs requests with an RSA key using the Digest, Signature-Input
+// and Signature headers.
+type Signer struct {
+	KeyID     string
+	Key       *rsa.PrivateKey
+	Algorithm string   // defaults to rsa-pss-sha512
+	Paths     []string // path patterns to sign; defaults to DefaultSignedPaths
+
+	now func() time.Time
+}
+
+// ParseSigningKey parses a PEM encoded RSA private key in PKCS#1 or PKCS#8
+// form.
+func ParseSigningKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errNoPEMKey
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse signing key: %w", err)
+	}
+
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key is %T, want RSA", parsed)
+	}
+
+	return key, nil
+}
+
+// Matches reports whether requests to urlPath must be signed.
+func (s *Signer) Matches(urlPath string) bool {
+	patterns := s.Paths
+	if len(patterns) == 0 {
+		patterns = DefaultSignedPaths
+	}
+
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, urlPath); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Sign adds the Digest, Signature-Input and Signature headers to req. The
+// request body must be replayable (GetBody set) or nil.
+func (s *Signer) Sign(req *http.Request) error {
+	body, err := requestBody(req)
+	if err != nil {
+		return err
+	}
+
+	digest := Digest(body)
+
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+
+	params := fmt.Sprintf(`(%q %q);created=%d;keyid=%q;algorithm=%q`,
+		signatureRequestTarget, signatureDigestHeader, now().Unix(), s.KeyID, s.algorithm())
+
+	sig, err := s.signBase(SignatureBase(req, digest, params))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Digest", digest)
+	req.Header.Set("Signature-Input", signatureLabel+"="+params)
+	req.Header.Set("Signature", signatureLabel+"=:"+base64.StdEncoding.EncodeToString(sig)+":")
+
+	return nil
+}
+
+func (s *Signer) algorithm() string {
+	if s.Algorithm == "" {
+		return AlgorithmRSAPSSSHA512
+	}
+
+	return s.Algorithm
+}
+
+func (s *Signer) signBase(base string) ([]byte, error) {
+	switch s.algorithm() {
+	case AlgorithmRSAPSSSHA512:
+		sum := sha512.Sum512([]byte(base))
+
+		sig, err := rsa.SignPSS(rand.Reader, s.Key, crypto.SHA512, sum[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		if err != nil {
+			return nil, fmt.Errorf("sign request: %w", err)
+		}
+
+		return sig, nil
+	case AlgorithmRSAv15SHA256:
+		sum := sha256.Sum256([]byte(base))
+
+		sig, err := rsa.SignPKCS1v15(rand.Reader, s.Key, crypto.SHA256, sum[:])
+		if err != nil {
+			return nil, fmt.Errorf("sign request: %w", err)
+		}
+
+		return sig, nil
+	default:
+		return nil, fmt.Errorf("unsupported signature algorithm %q", s.Algorithm)
+	}
+}
+
+// Digest returns the SHA-512 Digest header value for body.
+func Digest(body []byte) string {
+	sum := sha512.Sum512(body)
+
+	return "SHA-512=" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// SignatureBase returns the string that is signed for req: the covered
+// components followed by the signature parameters.
+func SignatureBase(req *http.Request, digest, params string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%q: %s %s\n", signatureRequestTarget, strings.ToLower(req.Method), req.URL.RequestURI())
+	fmt.Fprintf(&b, "%q: %s\n", signatureDigestHeader, digest)
+	fmt.Fprintf(&b, "%q: %s", signatureParamsName, params)
+
+	return b.String()
+}
+
+func requestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	if req.GetBody == nil {
+		return nil, errors.New("sign request: body is not replayable")
+	}
+
+	rc, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("read request body: %w", err)
+	}
+	defer rc.Close()
+
+	b, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("read request body: %w", err)
+	}
+
+	return b, nil
+}
@@ -20,9 +20,10 @@ const (
 
 // RetryTransport wraps an http.RoundTripper with retry logic.
 type RetryTransport struct {
-	Base      http.RoundTripper
-	NoRetry   bool
-	BaseDelay time.Duration
+	Base       http.RoundTripper
+	NoRetry    bool
+	BaseDelay  time.Duration
+	MaxRetries int // per error class; negative uses the defaults
 }
 
 // NewRetryTransport creates a RetryTransport with defaults.
@@ -32,9 +33,10 @@ func NewRetryTransport(base http.RoundTripper, noRetry bool) *RetryTransport {
 	}
 
 	return &RetryTransport{
-		Base:      base,
-		NoRetry:   noRetry,
-		BaseDelay: rateLimitBaseDelay,
+		Base:       base,
+		NoRetry:    noRetry,
+		BaseDelay:  rateLimitBaseDelay,
+		MaxRetries: -1,
 	}
 }
 
@@ -80,7 +82,7 @@ func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 
 		// Rate limit (429)
 		if resp.StatusCode == http.StatusTooManyRequests {
-			if retries429 >= maxRetries429 {
+			if retries429 >= t.maxRetries(maxRetries429) {
 				return resp, nil
 			}
 
@@ -102,7 +104,7 @@ func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 
 		// Server error (5xx)
 		if resp.StatusCode >= 500 {
-			if retries5xx >= maxRetries5xx {
+			if retries5xx >= t.maxRetries(maxRetries5xx) {
 				return resp, nil
 			}
 
@@ -126,6 +128,14 @@ func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	}
 }
 
+func (t *RetryTransport) maxRetries(fallback int) int {
+	if t.MaxRetries >= 0 {
+		return t.MaxRetries
+	}
+
+	return fallback
+}
+
 func (t *RetryTransport) calculateBackoff(attempt int, resp *http.Response) time.Duration {
 	// Check Retry-After header
 	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
@@ -10,6 +10,9 @@ type Account struct {
 	CurrentBalance   float64 `json:"currentBalance"`
 	AvailableBalance float64 `json:"availableBalance"`
 	Deprecated       bool    `json:"deprecated"`
+
+	AuthorizedAt           string `json:"authorizedAt,omitempty"`
+	AuthorizationExpiresAt string `json:"authorizationExpirationExpectedAt,omitempty"`
 }
 
 // Transaction represents a Ponto transaction.
@@ -64,6 +67,18 @@ type FinancialInstitution struct {
 	MaintenanceTo   string `json:"maintenanceTo,omitempty"`
 }
 
+// WebhookKey is a public key Ponto signs webhooks with, in JWK form. Its ID
+// is the key ID ("kid") of the signatures it verifies.
+type WebhookKey struct {
+	ID     string `json:"id"`
+	Kty    string `json:"kty"`
+	Alg    string `json:"alg"`
+	Use    string `json:"use"`
+	N      string `json:"n"`
+	E      string `json:"e"`
+	Status string `json:"status"`
+}
+
 // Organization represents the user's organization.
 type Organization struct {
 	ID   string `json:"id"`
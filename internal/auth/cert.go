@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+var errMissingKey = errors.New("missing private key (use --key, or a PKCS#12 bundle)")
+
+// IsPKCS12 reports whether path looks like a PKCS#12 bundle.
+func IsPKCS12(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".p12", ".pfx":
+		return true
+	default:
+		return false
+	}
+}
+
+// LoadClientCertificate loads a TLS client certificate from a PEM
+// certificate and key pair, or from a PKCS#12 bundle when keyFile is empty.
+func LoadClientCertificate(certFile, keyFile, passphrase string) (tls.Certificate, error) {
+	if keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return tls.Certificate{}, fmt.Errorf("load client certificate: %w", err)
+		}
+
+		return cert, nil
+	}
+
+	if !IsPKCS12(certFile) {
+		return tls.Certificate{}, errMissingKey
+	}
+
+	data, err := os.ReadFile(certFile)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("read client certificate: %w", err)
+	}
+
+	key, leaf, chain, err := pkcs12.DecodeChain(data, passphrase)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("decode PKCS#12 bundle: %w", err)
+	}
+
+	cert := tls.Certificate{
+		Certificate: [][]byte{leaf.Raw},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}
+
+	for _, c := range chain {
+		cert.Certificate = append(cert.Certificate, c.Raw)
+	}
+
+	return cert, nil
+}
+
+// NewMTLSTransport returns a transport that presents cert to the server.
+func NewMTLSTransport(cert tls.Certificate) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	return transport
+}
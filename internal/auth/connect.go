@@ -0,0 +1,327 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/dedene/ponto-cli/internal/config"
+)
+
+const (
+	// ConnectAuthorizeURL is the Ponto Connect authorization endpoint.
+	ConnectAuthorizeURL = "https://authorization.myponto.com/oauth2/auth"
+	// ConnectSandboxAuthorizeURL is the sandbox authorization endpoint.
+	ConnectSandboxAuthorizeURL = "https://sandbox-authorization.myponto.com/oauth2/auth"
+	// DefaultConnectRedirectURI is the loopback redirect used by login.
+	DefaultConnectRedirectURI = "http://127.0.0.1:8765/callback"
+	// DefaultConnectScope requests account and payment access plus a refresh token.
+	DefaultConnectScope = "ai pi name offline_access"
+
+	secretRefreshToken = "refresh_token"
+	secretPassphrase   = "cert_passphrase"
+	secretSigningKey   = "signing_key"
+)
+
+var (
+	errNoRefreshToken = errors.New("no refresh token stored; run 'ponto auth login --connect'")
+	errStateMismatch  = errors.New("authorization callback state mismatch")
+)
+
+// ConnectFlow runs the Ponto Connect authorization-code flow with PKCE over
+// a mutually authenticated TLS connection.
+type ConnectFlow struct {
+	ClientID     string
+	ClientSecret string
+	AuthorizeURL string
+	RedirectURI  string
+	Scope        string
+	Transport    *http.Transport // presents the client certificate
+}
+
+// NewConnectFlow builds a flow for a Connect profile, loading its client
+// certificate.
+func NewConnectFlow(cp *config.ConnectProfile, clientID, clientSecret, passphrase string) (*ConnectFlow, error) {
+	cert, err := LoadClientCertificate(cp.CertFile, cp.KeyFile, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	flow := &ConnectFlow{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		AuthorizeURL: cp.AuthorizeURL,
+		RedirectURI:  cp.RedirectURI,
+		Scope:        cp.Scope,
+		Transport:    NewMTLSTransport(cert),
+	}
+
+	if flow.AuthorizeURL == "" {
+		flow.AuthorizeURL = ConnectAuthorizeURL
+	}
+
+	if flow.RedirectURI == "" {
+		flow.RedirectURI = DefaultConnectRedirectURI
+	}
+
+	if flow.Scope == "" {
+		flow.Scope = DefaultConnectScope
+	}
+
+	return flow, nil
+}
+
+// PKCE holds a code verifier and its S256 challenge.
+type PKCE struct {
+	Verifier  string
+	Challenge string
+}
+
+// NewPKCE generates a random code verifier and its challenge.
+func NewPKCE() (PKCE, error) {
+	verifier, err := randomToken(32)
+	if err != nil {
+		return PKCE{}, err
+	}
+
+	sum := sha256.Sum256([]byte(verifier))
+
+	return PKCE{
+		Verifier:  verifier,
+		Challenge: base64.RawURLEncoding.EncodeToString(sum[:]),
+	}, nil
+}
+
+// NewState generates a random OAuth state value.
+func NewState() (string, error) {
+	return randomToken(16)
+}
+
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate random token: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// AuthCodeURL returns the URL the user opens to authorize the integration.
+func (f *ConnectFlow) AuthCodeURL(state string, pkce PKCE) string {
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", f.ClientID)
+	q.Set("redirect_uri", f.RedirectURI)
+	q.Set("scope", f.Scope)
+	q.Set("state", state)
+	q.Set("code_challenge", pkce.Challenge)
+	q.Set("code_challenge_method", "S256")
+
+	return f.AuthorizeURL + "?" + q.Encode()
+}
+
+// Exchange redeems an authorization code for an access and refresh token.
+func (f *ConnectFlow) Exchange(ctx context.Context, code string, pkce PKCE) (*Token, error) {
+	data := url.Values{}
+	data.Set("grant_type", "authorization_code")
+	data.Set("code", code)
+	data.Set("redirect_uri", f.RedirectURI)
+	data.Set("code_verifier", pkce.Verifier)
+
+	return requestToken(ctx, f.httpClient(), tokenEndpoint(ctx), f.ClientID, f.ClientSecret, data)
+}
+
+// Refresh redeems a refresh token. Ponto rotates refresh tokens, so callers
+// must store the RefreshToken of the result.
+func (f *ConnectFlow) Refresh(ctx context.Context, refreshToken string) (*Token, error) {
+	data := url.Values{}
+	data.Set("grant_type", "refresh_token")
+	data.Set("refresh_token", refreshToken)
+
+	return requestToken(ctx, f.httpClient(), tokenEndpoint(ctx), f.ClientID, f.ClientSecret, data)
+}
+
+func (f *ConnectFlow) httpClient() *http.Client {
+	return &http.Client{Transport: f.Transport, Timeout: 30 * time.Second}
+}
+
+// GetConnectToken returns an access token for a Connect profile, reusing a
+// persisted token until it expires and otherwise redeeming the refresh
+// token held in the keyring.
+func GetConnectToken(ctx context.Context, profile string, flow *ConnectFlow, store Store) (*Token, error) {
+	cache, err := OpenTokenCache()
+	if err != nil {
+		slog.Debug("token cache unavailable", "error", err)
+	}
+
+	if cache != nil {
+		if token, ok := cache.Get(profile, flow.ClientID, flow.ClientSecret); ok {
+			return token, nil
+		}
+	}
+
+	refresh, err := store.GetSecret(profile, secretRefreshToken)
+	if err != nil {
+		return nil, errNoRefreshToken
+	}
+
+	token, err := flow.Refresh(ctx, refresh)
+	if err != nil {
+		return nil, fmt.Errorf("refresh access token: %w", err)
+	}
+
+	if token.RefreshToken != "" && token.RefreshToken != refresh {
+		if err := store.SetSecret(profile, secretRefreshToken, token.RefreshToken); err != nil {
+			return nil, fmt.Errorf("store rotated refresh token: %w", err)
+		}
+	}
+
+	if cache != nil {
+		// The refresh token lives in the keyring only
+		cached := *token
+		cached.RefreshToken = ""
+
+		if err := cache.Put(profile, flow.ClientID, flow.ClientSecret, &cached); err != nil {
+			slog.Debug("persist access token", "error", err)
+		}
+	}
+
+	return token, nil
+}
+
+// SaveConnectSecrets stores the refresh token and, for PKCS#12 bundles, the
+// certificate passphrase of a Connect profile.
+func SaveConnectSecrets(store Store, profile, refreshToken, passphrase string) error {
+	if err := store.SetSecret(profile, secretRefreshToken, refreshToken); err != nil {
+		return err
+	}
+
+	if passphrase == "" {
+		return nil
+	}
+
+	return store.SetSecret(profile, secretPassphrase, passphrase)
+}
+
+// ConnectPassphrase returns the stored certificate passphrase of a profile,
+// or "" when none is stored.
+func ConnectPassphrase(store Store, profile string) string {
+	passphrase, err := store.GetSecret(profile, secretPassphrase)
+	if err != nil {
+		return ""
+	}
+
+	return passphrase
+}
+
+// CallbackServer receives the authorization code on the loopback redirect URI.
+type CallbackServer struct {
+	srv    *http.Server
+	ln     net.Listener
+	result chan callbackResult
+}
+
+type callbackResult struct {
+	code string
+	err  error
+}
+
+// StartCallbackServer listens on the host and port of redirectURI, which
+// must be a loopback address, and waits for a callback carrying state.
+func StartCallbackServer(redirectURI, state string) (*CallbackServer, error) {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return nil, fmt.Errorf("parse redirect URI: %w", err)
+	}
+
+	if ip := net.ParseIP(u.Hostname()); u.Hostname() != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil, fmt.Errorf("redirect URI %q must use a loopback address", redirectURI)
+	}
+
+	ln, err := net.Listen("tcp", u.Host)
+	if err != nil {
+		return nil, fmt.Errorf("listen on %s: %w", u.Host, err)
+	}
+
+	s := &CallbackServer{ln: ln, result: make(chan callbackResult, 1)}
+
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+
+		var res callbackResult
+
+		switch {
+		case q.Get("error") != "":
+			res.err = fmt.Errorf("authorization failed: %s %s", q.Get("error"), q.Get("error_description"))
+		case q.Get("state") != state:
+			res.err = errStateMismatch
+		case q.Get("code") == "":
+			res.err = errors.New("authorization callback without code")
+		default:
+			res.code = q.Get("code")
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+		if res.err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "<p>Ponto authorization failed: %s</p>", html.EscapeString(res.err.Error()))
+		} else {
+			fmt.Fprint(w, "<p>Ponto authorization complete. You can close this window.</p>")
+		}
+
+		select {
+		case s.result <- res:
+		default:
+		}
+	})
+
+	s.srv = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() { _ = s.srv.Serve(ln) }()
+
+	return s, nil
+}
+
+// Wait blocks until the callback arrives or ctx is done.
+func (s *CallbackServer) Wait(ctx context.Context) (string, error) {
+	select {
+	case res := <-s.result:
+		return res.code, res.err
+	case <-ctx.Done():
+		return "", fmt.Errorf("waiting for authorization: %w", ctx.Err())
+	}
+}
+
+// Close stops the callback server.
+func (s *CallbackServer) Close() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_ = s.srv.Shutdown(ctx)
+}
+
+// SigningKey returns the PEM request-signing key stored for a profile.
+func SigningKey(store Store, profile string) (string, error) {
+	return store.GetSecret(profile, secretSigningKey)
+}
+
+// SaveSigningKey stores a PEM request-signing key for a profile.
+func SaveSigningKey(store Store, profile, pemKey string) error {
+	return store.SetSecret(profile, secretSigningKey, pemKey)
+}
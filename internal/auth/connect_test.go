@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+func TestNewPKCE(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewPKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sum := sha256.Sum256([]byte(p.Verifier))
+	if want := base64.RawURLEncoding.EncodeToString(sum[:]); p.Challenge != want {
+		t.Errorf("Challenge = %q, want %q", p.Challenge, want)
+	}
+
+	if len(p.Verifier) < 43 {
+		t.Errorf("Verifier length = %d, want >= 43", len(p.Verifier))
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	t.Parallel()
+
+	flow := &ConnectFlow{
+		ClientID:     "client",
+		AuthorizeURL: ConnectAuthorizeURL,
+		RedirectURI:  DefaultConnectRedirectURI,
+		Scope:        DefaultConnectScope,
+	}
+
+	u, err := url.Parse(flow.AuthCodeURL("xyz", PKCE{Challenge: "chal"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q := u.Query()
+
+	for key, want := range map[string]string{
+		"response_type":         "code",
+		"client_id":             "client",
+		"redirect_uri":          DefaultConnectRedirectURI,
+		"state":                 "xyz",
+		"code_challenge":        "chal",
+		"code_challenge_method": "S256",
+	} {
+		if got := q.Get(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestCallbackServer(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr := ln.Addr().String()
+	ln.Close()
+
+	redirect := "http://" + addr + "/callback"
+
+	srv, err := StartCallbackServer(redirect, "good")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	resp, err := http.Get(redirect + "?state=good&code=abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	code, err := srv.Wait(ctx)
+	if err != nil || code != "abc" {
+		t.Errorf("Wait() = %q, %v, want abc", code, err)
+	}
+
+	if _, err := StartCallbackServer("http://example.com/callback", "x"); err == nil {
+		t.Error("StartCallbackServer() accepted a non-loopback redirect URI")
+	}
+}
+
+func TestLoadClientCertificate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "ponto-cli test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	p12File := filepath.Join(dir, "bundle.p12")
+
+	writeFile(t, certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	writeFile(t, keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+
+	p12, err := pkcs12.Modern.Encode(key, leaf, nil, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, p12File, p12)
+
+	tests := []struct {
+		name       string
+		cert, key  string
+		passphrase string
+		wantErr    bool
+	}{
+		{name: "pem pair", cert: certFile, key: keyFile},
+		{name: "pkcs12", cert: p12File, passphrase: "secret"},
+		{name: "pkcs12 wrong passphrase", cert: p12File, passphrase: "nope", wantErr: true},
+		{name: "pem without key", cert: certFile, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cert, err := LoadClientCertificate(tt.cert, tt.key, tt.passphrase)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("LoadClientCertificate() expected error, got nil")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("LoadClientCertificate() error = %v", err)
+			}
+
+			if len(cert.Certificate) == 0 || cert.PrivateKey == nil {
+				t.Errorf("LoadClientCertificate() = %+v, want certificate and key", cert)
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,26 @@
+package auth
+
+import (
+	"os"
+	"strings"
+)
+
+// Environment variables that supply credentials directly, bypassing the
+// keyring (e.g. in CI).
+const (
+	ClientIDEnv     = "PONTO_CLIENT_ID"
+	ClientSecretEnv = "PONTO_CLIENT_SECRET"
+)
+
+// EnvCredentials returns the credentials set in the environment; ok is
+// false unless both the client ID and secret are set.
+func EnvCredentials() (clientID, clientSecret string, ok bool) {
+	clientID = strings.TrimSpace(os.Getenv(ClientIDEnv))
+	clientSecret = strings.TrimSpace(os.Getenv(ClientSecretEnv))
+
+	if clientID == "" || clientSecret == "" {
+		return "", "", false
+	}
+
+	return clientID, clientSecret, true
+}
@@ -0,0 +1,31 @@
+package auth
+
+import "testing"
+
+func TestEnvCredentials(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		secret     string
+		wantOK     bool
+		wantID     string
+		wantSecret string
+	}{
+		{name: "both set", id: " id ", secret: "secret\n", wantOK: true, wantID: "id", wantSecret: "secret"},
+		{name: "only id", id: "id"},
+		{name: "only secret", secret: "secret"},
+		{name: "none"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(ClientIDEnv, tt.id)
+			t.Setenv(ClientSecretEnv, tt.secret)
+
+			id, secret, ok := EnvCredentials()
+			if ok != tt.wantOK || id != tt.wantID || secret != tt.wantSecret {
+				t.Errorf("EnvCredentials() = %q, %q, %v, want %q, %q, %v", id, secret, ok, tt.wantID, tt.wantSecret, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// execBackend delegates to a credential helper in the style of git and
+// docker credential helpers. The helper is invoked as
+// "<command> [args] get|store|erase" with a JSON request on stdin:
+//
+//	{"key": "ponto:<profile>:<name>", "profile": "...", "name": "...", "secret": "..."}
+//
+// "get" writes {"secret": "..."} to stdout. For an unknown key it writes
+// nothing, or exits non-zero with "not found" on stderr like docker
+// credential helpers; any other failure is an error. "erase" of an unknown
+// key must succeed.
+type execBackend struct {
+	command string
+	args    []string
+}
+
+type helperRequest struct {
+	Key     string `json:"key"`
+	Profile string `json:"profile"`
+	Name    string `json:"name"`
+	Secret  string `json:"secret,omitempty"`
+}
+
+type helperResponse struct {
+	Secret string `json:"secret"`
+}
+
+func (b *execBackend) get(profile, name string) (string, error) {
+	out, err := b.run("get", profile, name, "")
+	if err != nil && strings.Contains(err.Error(), "not found") {
+		return "", errSecretNotFound
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if len(bytes.TrimSpace(out)) == 0 {
+		return "", errSecretNotFound
+	}
+
+	var resp helperResponse
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return "", fmt.Errorf("parse credential helper response: %w", err)
+	}
+
+	return resp.Secret, nil
+}
+
+func (b *execBackend) set(profile, name, value string) error {
+	_, err := b.run("store", profile, name, value)
+
+	return err
+}
+
+func (b *execBackend) remove(profile, name string) error {
+	_, err := b.run("erase", profile, name, "")
+
+	return err
+}
+
+func (b *execBackend) run(action, profile, name, secret string) ([]byte, error) {
+	req, err := json.Marshal(helperRequest{
+		Key:     secretKey(profile, name),
+		Profile: profile,
+		Name:    name,
+		Secret:  secret,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal credential helper request: %w", err)
+	}
+
+	args := append(append([]string{}, b.args...), action)
+
+	return runHelper(req, b.command, args...)
+}
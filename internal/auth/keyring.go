@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"runtime"
 	"strings"
@@ -29,36 +30,45 @@ var (
 	keyringOpenTimeout = 5 * time.Second
 )
 
-// Store provides credential storage.
+// Store provides credential storage, plus auxiliary per-profile secrets
+// such as Ponto Connect refresh tokens.
 type Store interface {
 	GetCredentials(profile string) (clientID, clientSecret string, err error)
 	SetCredentials(profile, clientID, clientSecret string) error
 	DeleteCredentials(profile string) error
+	GetSecret(profile, name string) (string, error)
+	SetSecret(profile, name, value string) error
+	DeleteSecret(profile, name string) error
+	// Backend names the backend holding the credentials.
+	Backend() string
 }
 
 // KeyringStore stores credentials in the OS keyring.
 type KeyringStore struct {
-	ring keyring.Keyring
+	ring    keyring.Keyring
+	backend string
 }
 
-// OpenKeyring opens the default keyring store.
-func OpenKeyring() (Store, error) {
-	ring, err := openKeyring()
+func openKeyringStore(backend string) (*KeyringStore, error) {
+	ring, err := openKeyring(backend)
 	if err != nil {
 		return nil, err
 	}
 
-	return &KeyringStore{ring: ring}, nil
+	return &KeyringStore{ring: ring, backend: backend}, nil
 }
 
-func openKeyring() (keyring.Keyring, error) {
+// Backend implements Store.
+func (s *KeyringStore) Backend() string {
+	return "keyring (" + s.backend + ")"
+}
+
+func openKeyring(backendInfo string) (keyring.Keyring, error) {
 	keyringDir, err := config.EnsureKeyringDir()
 	if err != nil {
 		return nil, fmt.Errorf("ensure keyring dir: %w", err)
 	}
 
-	backendInfo := resolveKeyringBackend()
-
 	backends, err := allowedBackends(backendInfo)
 	if err != nil {
 		return nil, err
@@ -118,19 +128,21 @@ func openKeyringWithTimeout(cfg keyring.Config) (keyring.Keyring, error) {
 	}
 }
 
-func resolveKeyringBackend() string {
+func resolveKeyringBackend(cfg config.File) string {
 	if v := os.Getenv(keyringBackendEnv); v != "" {
 		return strings.ToLower(strings.TrimSpace(v))
 	}
 
-	cfg, err := config.ReadConfig()
-	if err == nil && cfg.KeyringBackend != "" {
+	if cfg.KeyringBackend != "" {
 		return strings.ToLower(strings.TrimSpace(cfg.KeyringBackend))
 	}
 
 	return keyringBackendAuto
 }
 
+// allowedBackends validates a backend name and returns the keyring library
+// backends it selects. External backends bypass the keyring library and
+// select none.
 func allowedBackends(backend string) ([]keyring.BackendType, error) {
 	switch backend {
 	case "", keyringBackendAuto:
@@ -139,8 +151,11 @@ func allowedBackends(backend string) ([]keyring.BackendType, error) {
 		return []keyring.BackendType{keyring.KeychainBackend}, nil
 	case "file":
 		return []keyring.BackendType{keyring.FileBackend}, nil
+	case backendExec, backendVault, backendPass, backendOnePassword:
+		return nil, nil
 	default:
-		return nil, fmt.Errorf("%w: %q", errInvalidBackend, backend)
+		return nil, fmt.Errorf("%w: %q (valid: auto, keychain, file, %s, %s, %s, %s)",
+			errInvalidBackend, backend, backendExec, backendVault, backendPass, backendOnePassword)
 	}
 }
 
@@ -252,7 +267,7 @@ func (s *KeyringStore) DeleteCredentials(profile string) error {
 	}
 
 	// Remove new key
-	if err := s.ring.Remove(credentialsKey(profile)); err != nil && !errors.Is(err, keyring.ErrKeyNotFound) {
+	if err := s.ring.Remove(credentialsKey(profile)); err != nil && !isKeyNotFound(err) {
 		return fmt.Errorf("delete credentials: %w", err)
 	}
 
@@ -260,5 +275,68 @@ func (s *KeyringStore) DeleteCredentials(profile string) error {
 	_ = s.ring.Remove(legacyClientIDKey(profile))
 	_ = s.ring.Remove(legacyClientSecretKey(profile))
 
+	// And Ponto Connect and request-signing secrets
+	for _, name := range profileSecrets {
+		if err := s.DeleteSecret(profile, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func secretKey(profile, name string) string {
+	return fmt.Sprintf("ponto:%s:%s", profile, name)
+}
+
+// GetSecret retrieves an auxiliary profile secret from the keyring.
+func (s *KeyringStore) GetSecret(profile, name string) (string, error) {
+	profile = strings.TrimSpace(profile)
+	if profile == "" {
+		return "", errMissingProfile
+	}
+
+	item, err := s.ring.Get(secretKey(profile, name))
+	if err != nil {
+		return "", fmt.Errorf("get %s: %w", name, err)
+	}
+
+	return string(item.Data), nil
+}
+
+// SetSecret stores an auxiliary profile secret in the keyring.
+func (s *KeyringStore) SetSecret(profile, name, value string) error {
+	profile = strings.TrimSpace(profile)
+	if profile == "" {
+		return errMissingProfile
+	}
+
+	if err := s.ring.Set(keyring.Item{
+		Key:  secretKey(profile, name),
+		Data: []byte(value),
+	}); err != nil {
+		return fmt.Errorf("store %s: %w", name, err)
+	}
+
 	return nil
 }
+
+// DeleteSecret removes an auxiliary profile secret from the keyring.
+func (s *KeyringStore) DeleteSecret(profile, name string) error {
+	profile = strings.TrimSpace(profile)
+	if profile == "" {
+		return errMissingProfile
+	}
+
+	if err := s.ring.Remove(secretKey(profile, name)); err != nil && !isKeyNotFound(err) {
+		return fmt.Errorf("delete %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// isKeyNotFound reports whether err means the key does not exist; the file
+// backend reports missing keys as filesystem errors.
+func isKeyNotFound(err error) bool {
+	return errors.Is(err, keyring.ErrKeyNotFound) || errors.Is(err, fs.ErrNotExist)
+}
@@ -6,11 +6,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
 	"sync"
 	"time"
+
+	pontoCtx "github.com/dedene/ponto-cli/internal/ctx"
 )
 
 const (
@@ -20,11 +23,12 @@ const (
 
 // Token represents an OAuth2 access token.
 type Token struct {
-	AccessToken string `json:"access_token"`
-	TokenType   string `json:"token_type"`
-	ExpiresIn   int    `json:"expires_in"`
-	Scope       string `json:"scope"`
-	ExpiresAt   time.Time
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type"`
+	ExpiresIn    int       `json:"expires_in"`
+	Scope        string    `json:"scope"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at"`
 }
 
 // IsExpired checks if the token is expired or about to expire.
@@ -65,6 +69,65 @@ func GetAccessToken(ctx context.Context, clientID, clientSecret string) (*Token,
 	return token, nil
 }
 
+// GetProfileToken retrieves an access token for a profile, reusing a token
+// persisted by an earlier invocation until it expires.
+func GetProfileToken(ctx context.Context, profile, clientID, clientSecret string) (*Token, error) {
+	tokenCacheMu.RLock()
+	if cached, ok := tokenCache[clientID]; ok && !cached.IsExpired() {
+		tokenCacheMu.RUnlock()
+
+		return cached, nil
+	}
+	tokenCacheMu.RUnlock()
+
+	cache, err := OpenTokenCache()
+	if err != nil {
+		slog.Debug("token cache unavailable", "error", err)
+	}
+
+	if cache != nil {
+		if token, ok := cache.Get(profile, clientID, clientSecret); ok {
+			slog.Debug("using cached access token", "profile", profile, "expires_at", token.ExpiresAt)
+
+			tokenCacheMu.Lock()
+			tokenCache[clientID] = token
+			tokenCacheMu.Unlock()
+
+			return token, nil
+		}
+	}
+
+	token, err := GetAccessToken(ctx, clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	if cache != nil {
+		if err := cache.Put(profile, clientID, clientSecret, token); err != nil {
+			slog.Debug("persist access token", "error", err)
+		}
+	}
+
+	return token, nil
+}
+
+// InvalidateToken evicts the token for a profile and client ID from both the
+// in-process and persistent caches, e.g. after the API rejected it.
+func InvalidateToken(profile, clientID string) {
+	tokenCacheMu.Lock()
+	delete(tokenCache, clientID)
+	tokenCacheMu.Unlock()
+
+	cache, err := OpenTokenCache()
+	if err != nil {
+		return
+	}
+
+	if err := cache.Delete(profile, clientID); err != nil {
+		slog.Debug("invalidate access token", "error", err)
+	}
+}
+
 // ClearTokenCache clears the token cache.
 func ClearTokenCache() {
 	tokenCacheMu.Lock()
@@ -76,7 +139,22 @@ func fetchToken(ctx context.Context, clientID, clientSecret string) (*Token, err
 	data := url.Values{}
 	data.Set("grant_type", "client_credentials")
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(data.Encode()))
+	return requestToken(ctx, &http.Client{Timeout: 30 * time.Second}, tokenEndpoint(ctx), clientID, clientSecret, data)
+}
+
+// tokenEndpoint returns the token URL for the API base URL in ctx.
+func tokenEndpoint(ctx context.Context) string {
+	if base := pontoCtx.BaseURLFrom(ctx); base != "" {
+		return strings.TrimSuffix(base, "/") + "/oauth2/token"
+	}
+
+	return tokenURL
+}
+
+// requestToken posts a token request authenticated with the client's
+// credentials and decodes the resulting token.
+func requestToken(ctx context.Context, client *http.Client, endpoint, clientID, clientSecret string, data url.Values) (*Token, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(data.Encode()))
 	if err != nil {
 		return nil, fmt.Errorf("create token request: %w", err)
 	}
@@ -86,8 +164,6 @@ func fetchToken(ctx context.Context, clientID, clientSecret string) (*Token, err
 	req.Header.Set("Authorization", "Basic "+auth)
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	client := &http.Client{Timeout: 30 * time.Second}
-
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("token request: %w", err)
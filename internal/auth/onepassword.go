@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dedene/ponto-cli/internal/config"
+)
+
+// onePasswordBackend stores each secret as a Secure Note titled
+// "ponto:<profile>:<name>" using the 1Password CLI. Values are passed on
+// stdin so they never appear in the process list.
+type onePasswordBackend struct {
+	bin     string
+	vault   string
+	account string
+}
+
+func newOnePasswordBackend(cfg config.OnePasswordBackend) *onePasswordBackend {
+	return &onePasswordBackend{bin: "op", vault: cfg.Vault, account: cfg.Account}
+}
+
+type opField struct {
+	ID      string `json:"id,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Purpose string `json:"purpose,omitempty"`
+	Label   string `json:"label,omitempty"`
+	Value   string `json:"value"`
+}
+
+type opItem struct {
+	Title    string    `json:"title"`
+	Category string    `json:"category"`
+	Fields   []opField `json:"fields"`
+}
+
+func (b *onePasswordBackend) args(args ...string) []string {
+	if b.vault != "" {
+		args = append(args, "--vault", b.vault)
+	}
+
+	if b.account != "" {
+		args = append(args, "--account", b.account)
+	}
+
+	return args
+}
+
+func (b *onePasswordBackend) get(profile, name string) (string, error) {
+	out, err := runHelper(nil, b.bin, b.args("item", "get", secretKey(profile, name), "--fields", "label=notesPlain", "--format", "json")...)
+	if err != nil && strings.Contains(err.Error(), "isn't an item") {
+		return "", errSecretNotFound
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	var field opField
+	if err := json.Unmarshal(out, &field); err != nil {
+		return "", fmt.Errorf("parse 1Password item: %w", err)
+	}
+
+	return field.Value, nil
+}
+
+// set creates the new item before deleting the old ones, so a failed create
+// leaves the previous value in place.
+func (b *onePasswordBackend) set(profile, name, value string) error {
+	old, err := b.itemIDs(profile, name)
+	if err != nil {
+		return err
+	}
+
+	item, err := json.Marshal(opItem{
+		Title:    secretKey(profile, name),
+		Category: "SECURE_NOTE",
+		Fields: []opField{{
+			ID:      "notesPlain",
+			Type:    "STRING",
+			Purpose: "NOTES",
+			Label:   "notesPlain",
+			Value:   value,
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("marshal 1Password item: %w", err)
+	}
+
+	if _, err := runHelper(item, b.bin, b.args("item", "create")...); err != nil {
+		return err
+	}
+
+	for _, id := range old {
+		if _, err := runHelper(nil, b.bin, b.args("item", "delete", id)...); err != nil {
+			return fmt.Errorf("delete previous 1Password item: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// itemIDs returns the IDs of the items holding the secret; more than one
+// only if an earlier set failed to delete the previous item.
+func (b *onePasswordBackend) itemIDs(profile, name string) ([]string, error) {
+	out, err := runHelper(nil, b.bin, b.args("item", "list", "--categories", "Secure Note", "--format", "json")...)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []struct {
+		ID    string `json:"id"`
+		Title string `json:"title"`
+	}
+
+	if err := json.Unmarshal(out, &items); err != nil {
+		return nil, fmt.Errorf("parse 1Password items: %w", err)
+	}
+
+	var ids []string
+
+	for _, item := range items {
+		if item.Title == secretKey(profile, name) {
+			ids = append(ids, item.ID)
+		}
+	}
+
+	return ids, nil
+}
+
+func (b *onePasswordBackend) remove(profile, name string) error {
+	_, err := runHelper(nil, b.bin, b.args("item", "delete", secretKey(profile, name))...)
+	if err != nil && strings.Contains(err.Error(), "isn't an item") {
+		return nil
+	}
+
+	return err
+}
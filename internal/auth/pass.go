@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"path"
+	"strings"
+
+	"github.com/dedene/ponto-cli/internal/config"
+)
+
+// passBackend stores secrets in the pass password store at
+// <prefix>/<profile>/<name>.
+type passBackend struct {
+	bin    string
+	prefix string
+}
+
+func newPassBackend(cfg config.PassBackend) *passBackend {
+	prefix := strings.Trim(cfg.Prefix, "/")
+	if prefix == "" {
+		prefix = config.AppName
+	}
+
+	return &passBackend{bin: "pass", prefix: prefix}
+}
+
+func (b *passBackend) entry(profile, name string) string {
+	return path.Join(b.prefix, profile, name)
+}
+
+func (b *passBackend) get(profile, name string) (string, error) {
+	out, err := runHelper(nil, b.bin, "show", b.entry(profile, name))
+	if err != nil && strings.Contains(err.Error(), "is not in the password store") {
+		return "", errSecretNotFound
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func (b *passBackend) set(profile, name, value string) error {
+	_, err := runHelper([]byte(value), b.bin, "insert", "--multiline", "--force", b.entry(profile, name))
+
+	return err
+}
+
+func (b *passBackend) remove(profile, name string) error {
+	_, err := runHelper(nil, b.bin, "rm", "--force", b.entry(profile, name))
+	if err != nil && strings.Contains(err.Error(), "is not in the password store") {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"context"
+	"errors"
+)
+
+// secretPreviousClientSecret holds the pre-rotation client secret, kept as
+// a fallback until the new secret has been seen to work.
+const secretPreviousClientSecret = "previous_client_secret"
+
+// VerifyCredentials requests a fresh token for the credentials, bypassing
+// all token caches.
+func VerifyCredentials(ctx context.Context, clientID, clientSecret string) (*Token, error) {
+	return fetchToken(ctx, clientID, clientSecret)
+}
+
+// RotateSecret swaps a profile's client secret for newSecret, keeping the
+// current secret as a fallback. On failure the stored credentials are left
+// unchanged.
+func RotateSecret(store Store, profile, newSecret string) error {
+	clientID, oldSecret, err := store.GetCredentials(profile)
+	if err != nil {
+		return err
+	}
+
+	if err := store.SetSecret(profile, secretPreviousClientSecret, oldSecret); err != nil {
+		return err
+	}
+
+	if err := store.SetCredentials(profile, clientID, newSecret); err != nil {
+		return errors.Join(err, store.DeleteSecret(profile, secretPreviousClientSecret))
+	}
+
+	return nil
+}
+
+// PreviousSecret returns the fallback client secret of a profile, or ""
+// when none is kept.
+func PreviousSecret(store Store, profile string) string {
+	secret, err := store.GetSecret(profile, secretPreviousClientSecret)
+	if err != nil {
+		return ""
+	}
+
+	return secret
+}
+
+// DropPreviousSecret removes the fallback client secret of a profile once
+// the rotated secret has been verified.
+func DropPreviousSecret(store Store, profile string) error {
+	return store.DeleteSecret(profile, secretPreviousClientSecret)
+}
@@ -0,0 +1,57 @@
+package auth
+
+import "testing"
+
+func TestRotateSecret(t *testing.T) {
+	t.Parallel()
+
+	store, _ := newMemoryStore()
+
+	if err := store.SetCredentials("default", "id", "old"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RotateSecret(store, "default", "new"); err != nil {
+		t.Fatalf("RotateSecret() error = %v", err)
+	}
+
+	if _, secret, _ := store.GetCredentials("default"); secret != "new" {
+		t.Errorf("secret after rotation = %q, want new", secret)
+	}
+
+	if got := PreviousSecret(store, "default"); got != "old" {
+		t.Errorf("PreviousSecret() = %q, want old", got)
+	}
+
+	if err := DropPreviousSecret(store, "default"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := PreviousSecret(store, "default"); got != "" {
+		t.Errorf("PreviousSecret() after drop = %q, want empty", got)
+	}
+}
+
+func TestRotateSecretFailureKeepsCredentials(t *testing.T) {
+	t.Parallel()
+
+	store, mem := newMemoryStore()
+
+	if err := store.SetCredentials("default", "id", "old"); err != nil {
+		t.Fatal(err)
+	}
+
+	mem.failSet = credentialsName
+
+	if err := RotateSecret(store, "default", "new"); err == nil {
+		t.Fatal("RotateSecret() expected error")
+	}
+
+	if _, secret, _ := store.GetCredentials("default"); secret != "old" {
+		t.Errorf("secret after failed rotation = %q, want old", secret)
+	}
+
+	if got := PreviousSecret(store, "default"); got != "" {
+		t.Errorf("PreviousSecret() after failed rotation = %q, want empty", got)
+	}
+}
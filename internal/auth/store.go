@@ -0,0 +1,231 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/dedene/ponto-cli/internal/config"
+)
+
+// External credential backends selectable with keyring_backend.
+const (
+	backendExec        = "exec"
+	backendVault       = "vault"
+	backendPass        = "pass"
+	backendOnePassword = "1password"
+
+	credentialsName = "credentials"
+)
+
+var errSecretNotFound = errors.New("secret not found")
+
+// IsNotFound reports whether err means the credentials or secret are not
+// stored, as opposed to the store failing to answer.
+func IsNotFound(err error) bool {
+	return errors.Is(err, errSecretNotFound) || isKeyNotFound(err)
+}
+
+// profileSecrets are the auxiliary secrets a profile may hold besides its
+// credentials.
+var profileSecrets = []string{secretRefreshToken, secretPassphrase, secretSigningKey, secretPreviousClientSecret}
+
+// OpenStore opens the credential store selected by PONTO_KEYRING_BACKEND or
+// keyring_backend in the config file.
+func OpenStore() (Store, error) {
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	backend := resolveKeyringBackend(cfg)
+
+	if _, err := allowedBackends(backend); err != nil {
+		return nil, err
+	}
+
+	switch backend {
+	case backendExec:
+		if cfg.Backends.Exec.Command == "" {
+			return nil, errors.New("exec backend requires backends.exec.command in the config file")
+		}
+
+		return &backendStore{name: backendExec, secrets: &execBackend{
+			command: cfg.Backends.Exec.Command,
+			args:    cfg.Backends.Exec.Args,
+		}}, nil
+	case backendVault:
+		vault, err := newVaultBackend(cfg.Backends.Vault)
+		if err != nil {
+			return nil, err
+		}
+
+		return &backendStore{name: backendVault, secrets: vault}, nil
+	case backendPass:
+		return &backendStore{name: backendPass, secrets: newPassBackend(cfg.Backends.Pass)}, nil
+	case backendOnePassword:
+		return &backendStore{name: backendOnePassword, secrets: newOnePasswordBackend(cfg.Backends.OnePassword)}, nil
+	default:
+		return openKeyringStore(backend)
+	}
+}
+
+// secretBackend stores opaque values by profile and name. Removing a
+// missing value is not an error.
+type secretBackend interface {
+	get(profile, name string) (string, error)
+	set(profile, name, value string) error
+	remove(profile, name string) error
+}
+
+// backendStore implements Store on top of an external secret backend,
+// using the same layout as the keyring: one JSON credentials entry per
+// profile plus named auxiliary secrets.
+type backendStore struct {
+	name    string
+	secrets secretBackend
+}
+
+// Backend implements Store.
+func (s *backendStore) Backend() string {
+	return s.name
+}
+
+// GetCredentials implements Store.
+func (s *backendStore) GetCredentials(profile string) (string, string, error) {
+	data, err := s.GetSecret(profile, credentialsName)
+	if err != nil {
+		return "", "", fmt.Errorf("get credentials: %w", err)
+	}
+
+	var creds credentials
+	if err := json.Unmarshal([]byte(data), &creds); err != nil {
+		return "", "", fmt.Errorf("parse credentials: %w", err)
+	}
+
+	return creds.ClientID, creds.ClientSecret, nil
+}
+
+// SetCredentials implements Store.
+func (s *backendStore) SetCredentials(profile, clientID, clientSecret string) error {
+	data, err := json.Marshal(credentials{ClientID: clientID, ClientSecret: clientSecret})
+	if err != nil {
+		return fmt.Errorf("marshal credentials: %w", err)
+	}
+
+	if err := s.SetSecret(profile, credentialsName, string(data)); err != nil {
+		return fmt.Errorf("store credentials: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteCredentials implements Store.
+func (s *backendStore) DeleteCredentials(profile string) error {
+	for _, name := range append([]string{credentialsName}, profileSecrets...) {
+		if err := s.DeleteSecret(profile, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetSecret implements Store.
+func (s *backendStore) GetSecret(profile, name string) (string, error) {
+	profile = strings.TrimSpace(profile)
+	if profile == "" {
+		return "", errMissingProfile
+	}
+
+	value, err := s.secrets.get(profile, name)
+	if err != nil {
+		return "", fmt.Errorf("get %s from %s: %w", name, s.name, err)
+	}
+
+	return value, nil
+}
+
+// SetSecret implements Store.
+func (s *backendStore) SetSecret(profile, name, value string) error {
+	profile = strings.TrimSpace(profile)
+	if profile == "" {
+		return errMissingProfile
+	}
+
+	if err := s.secrets.set(profile, name, value); err != nil {
+		return fmt.Errorf("store %s in %s: %w", name, s.name, err)
+	}
+
+	return nil
+}
+
+// DeleteSecret implements Store.
+func (s *backendStore) DeleteSecret(profile, name string) error {
+	profile = strings.TrimSpace(profile)
+	if profile == "" {
+		return errMissingProfile
+	}
+
+	if err := s.secrets.remove(profile, name); err != nil {
+		return fmt.Errorf("delete %s from %s: %w", name, s.name, err)
+	}
+
+	return nil
+}
+
+// CopyProfile copies the credentials and auxiliary secrets of profile src
+// to dst. Secrets src does not have are skipped; any other failure is
+// returned, leaving dst partially copied.
+func CopyProfile(store Store, src, dst string) error {
+	clientID, clientSecret, err := store.GetCredentials(src)
+	if err != nil {
+		return err
+	}
+
+	if err := store.SetCredentials(dst, clientID, clientSecret); err != nil {
+		return err
+	}
+
+	for _, name := range profileSecrets {
+		value, err := store.GetSecret(src, name)
+		if IsNotFound(err) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := store.SetSecret(dst, name, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runHelper runs an external command with stdin and returns its stdout,
+// folding stderr into the error.
+func runHelper(stdin []byte, name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+
+	var stdout, stderr bytes.Buffer
+
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %w: %s", name, err, msg)
+		}
+
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return stdout.Bytes(), nil
+}
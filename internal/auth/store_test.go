@@ -0,0 +1,395 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAllowedBackends(t *testing.T) {
+	t.Parallel()
+
+	for _, backend := range []string{"", "auto", "keychain", "file", "exec", "vault", "pass", "1password"} {
+		if _, err := allowedBackends(backend); err != nil {
+			t.Errorf("allowedBackends(%q) error = %v", backend, err)
+		}
+	}
+
+	if _, err := allowedBackends("lastpass"); !errors.Is(err, errInvalidBackend) {
+		t.Errorf("allowedBackends(lastpass) error = %v, want errInvalidBackend", err)
+	}
+}
+
+// testStore exercises a Store round trip: credentials, a secret and deletion.
+func testStore(t *testing.T, store Store) {
+	t.Helper()
+
+	if err := store.SetCredentials("default", "id", "secret"); err != nil {
+		t.Fatalf("SetCredentials() error = %v", err)
+	}
+
+	id, secret, err := store.GetCredentials("default")
+	if err != nil || id != "id" || secret != "secret" {
+		t.Fatalf("GetCredentials() = %q, %q, %v, want id, secret", id, secret, err)
+	}
+
+	if err := store.SetSecret("default", secretRefreshToken, "multi\nline"); err != nil {
+		t.Fatalf("SetSecret() error = %v", err)
+	}
+
+	if got, err := store.GetSecret("default", secretRefreshToken); err != nil || got != "multi\nline" {
+		t.Errorf("GetSecret() = %q, %v, want multi\\nline", got, err)
+	}
+
+	if err := store.DeleteCredentials("default"); err != nil {
+		t.Fatalf("DeleteCredentials() error = %v", err)
+	}
+
+	if _, _, err := store.GetCredentials("default"); !IsNotFound(err) {
+		t.Errorf("GetCredentials() after delete error = %v, want not found", err)
+	}
+
+	if _, err := store.GetSecret("default", secretRefreshToken); !IsNotFound(err) {
+		t.Errorf("GetSecret() after delete error = %v, want not found", err)
+	}
+}
+
+func TestExecBackend(t *testing.T) {
+	t.Parallel()
+
+	// The test binary doubles as the credential helper
+	testStore(t, &backendStore{name: backendExec, secrets: &execBackend{
+		command: os.Args[0],
+		args:    []string{"-test.run=^TestExecHelperProcess$", "--", t.TempDir()},
+	}})
+}
+
+// TestExecHelperProcess is a credential helper keeping one file per key in
+// the directory given as its first argument.
+func TestExecHelperProcess(t *testing.T) {
+	args := flag.Args()
+	if len(args) != 2 {
+		return
+	}
+
+	dir, action := args[0], args[1]
+
+	var req helperRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	path := filepath.Join(dir, strings.ReplaceAll(req.Key, ":", "_"))
+
+	switch action {
+	case "get":
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "credentials not found")
+			os.Exit(1)
+		}
+
+		_ = json.NewEncoder(os.Stdout).Encode(helperResponse{Secret: string(data)})
+	case "store":
+		_ = os.WriteFile(path, []byte(req.Secret), 0o600)
+	case "erase":
+		_ = os.Remove(path)
+	}
+
+	os.Exit(0)
+}
+
+func TestVaultBackend(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu      sync.Mutex
+		secrets = map[string]string{}
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		key := strings.TrimPrefix(r.URL.Path, "/v1/secret/")
+
+		switch r.Method {
+		case http.MethodGet:
+			value, ok := secrets[strings.TrimPrefix(key, "data/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"data": map[string]string{"value": value}}})
+		case http.MethodPost:
+			var body struct {
+				Data map[string]string `json:"data"`
+			}
+
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			secrets[strings.TrimPrefix(key, "data/")] = body.Data["value"]
+		case http.MethodDelete:
+			delete(secrets, strings.TrimPrefix(key, "metadata/"))
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	vault := &vaultBackend{address: srv.URL, mount: "secret", path: "ponto", token: "token", client: srv.Client()}
+
+	testStore(t, &backendStore{name: backendVault, secrets: vault})
+
+	for _, profile := range []string{"a/b", "../x", ".."} {
+		if err := vault.set(profile, secretRefreshToken, "v"); err == nil {
+			t.Errorf("set(%q) succeeded, want the profile name refused", profile)
+		}
+	}
+
+	if err := vault.set("a b?c", secretRefreshToken, "v"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := vault.get("a b?c", secretRefreshToken); err != nil || got != "v" {
+		t.Errorf("get(a b?c) = %q, %v, want v", got, err)
+	}
+
+	if err := vault.remove("a b?c", secretRefreshToken); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(secrets) != 0 {
+		t.Errorf("secrets left after delete: %v", secrets)
+	}
+}
+
+func TestOnePasswordBackend(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("needs sh")
+	}
+
+	// The test binary, behind a wrapper script, doubles as the op CLI
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "op")
+	script := fmt.Sprintf("#!/bin/sh\nexec %q -test.run='^TestOnePasswordHelperProcess$' -- %q \"$@\"\n", os.Args[0], dir)
+
+	if err := os.WriteFile(bin, []byte(script), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	op := &onePasswordBackend{bin: bin}
+
+	testStore(t, &backendStore{name: backendOnePassword, secrets: op})
+
+	for _, v := range []string{"v1", "v2"} {
+		if err := op.set("default", secretRefreshToken, v); err != nil {
+			t.Fatalf("set(%s) error = %v", v, err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "fail-create"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := op.set("default", secretRefreshToken, "v3"); err == nil {
+		t.Fatal("set() succeeded, want the create failure")
+	}
+
+	if got, err := op.get("default", secretRefreshToken); err != nil || got != "v2" {
+		t.Errorf("get() after a failed set = %q, %v, want v2 kept", got, err)
+	}
+}
+
+// TestOnePasswordHelperProcess is a fake op CLI keeping one file per item in
+// the directory given as its first argument.
+func TestOnePasswordHelperProcess(t *testing.T) {
+	args := flag.Args()
+	if len(args) < 3 || args[1] != "item" {
+		return
+	}
+
+	dir, action, rest := args[0], args[2], args[3:]
+
+	type item struct {
+		ID    string `json:"id"`
+		Title string `json:"title"`
+		Value string `json:"value"`
+	}
+
+	var items []item
+
+	files, _ := filepath.Glob(filepath.Join(dir, "item-*.json"))
+	for _, f := range files {
+		var it item
+
+		b, _ := os.ReadFile(f)
+		_ = json.Unmarshal(b, &it)
+		items = append(items, it)
+	}
+
+	fail := func(msg string) {
+		fmt.Fprintln(os.Stderr, msg)
+		os.Exit(1)
+	}
+
+	switch action {
+	case "list":
+		_ = json.NewEncoder(os.Stdout).Encode(items)
+	case "create":
+		if _, err := os.Stat(filepath.Join(dir, "fail-create")); err == nil {
+			fail("session expired")
+		}
+
+		var req opItem
+		if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+			fail(err.Error())
+		}
+
+		id := strconv.FormatInt(time.Now().UnixNano(), 36)
+		b, _ := json.Marshal(item{ID: id, Title: req.Title, Value: req.Fields[0].Value})
+		_ = os.WriteFile(filepath.Join(dir, "item-"+id+".json"), b, 0o600)
+	case "get", "delete":
+		var found []item
+
+		for _, it := range items {
+			if it.ID == rest[0] || it.Title == rest[0] {
+				found = append(found, it)
+			}
+		}
+
+		switch {
+		case len(found) == 0:
+			fail(fmt.Sprintf("%q isn't an item in any vault", rest[0]))
+		case len(found) > 1:
+			fail("More than one item matches")
+		case action == "get":
+			_ = json.NewEncoder(os.Stdout).Encode(opField{Value: found[0].Value})
+		default:
+			_ = os.Remove(filepath.Join(dir, "item-"+found[0].ID+".json"))
+		}
+	}
+
+	os.Exit(0)
+}
+
+// memoryBackend is an in-memory secretBackend for tests.
+type memoryBackend struct {
+	mu      sync.Mutex
+	secrets map[string]string
+	failSet string // name whose set fails
+	failGet string // name whose get fails
+}
+
+func newMemoryStore() (*backendStore, *memoryBackend) {
+	mem := &memoryBackend{secrets: map[string]string{}}
+
+	return &backendStore{name: "memory", secrets: mem}, mem
+}
+
+func (m *memoryBackend) get(profile, name string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if name == m.failGet {
+		return "", errors.New("get failed")
+	}
+
+	v, ok := m.secrets[secretKey(profile, name)]
+	if !ok {
+		return "", errSecretNotFound
+	}
+
+	return v, nil
+}
+
+func (m *memoryBackend) set(profile, name, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if name == m.failSet {
+		return errors.New("set failed")
+	}
+
+	m.secrets[secretKey(profile, name)] = value
+
+	return nil
+}
+
+func (m *memoryBackend) remove(profile, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.secrets, secretKey(profile, name))
+
+	return nil
+}
+
+func TestCopyProfile(t *testing.T) {
+	t.Parallel()
+
+	store, _ := newMemoryStore()
+
+	if err := store.SetCredentials("src", "id", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.SetSecret("src", secretRefreshToken, "refresh"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := CopyProfile(store, "src", "dst"); err != nil {
+		t.Fatalf("CopyProfile() error = %v", err)
+	}
+
+	if id, secret, err := store.GetCredentials("dst"); err != nil || id != "id" || secret != "secret" {
+		t.Errorf("GetCredentials(dst) = %q, %q, %v, want id, secret", id, secret, err)
+	}
+
+	if got, _ := store.GetSecret("dst", secretRefreshToken); got != "refresh" {
+		t.Errorf("GetSecret(dst) = %q, want refresh", got)
+	}
+
+	if _, err := store.GetSecret("dst", secretSigningKey); err == nil {
+		t.Error("CopyProfile() copied a secret the source does not have")
+	}
+}
+
+func TestCopyProfileBackendFailure(t *testing.T) {
+	t.Parallel()
+
+	store, mem := newMemoryStore()
+
+	if err := store.SetCredentials("src", "id", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	mem.failGet = secretSigningKey
+
+	if err := CopyProfile(store, "src", "dst"); err == nil || IsNotFound(err) {
+		t.Errorf("CopyProfile() error = %v, want the backend failure", err)
+	}
+}
@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dedene/ponto-cli/internal/config"
+)
+
+const tokenFileExt = ".token"
+
+var errTokenCacheCorrupt = errors.New("token cache entry is corrupt")
+
+// TokenCache persists access tokens across invocations. Each entry is a
+// 0600 file under config.Dir(), encrypted with AES-GCM using a key derived
+// from the client credentials, so it is useless without the secret that is
+// held in the credential store.
+type TokenCache struct {
+	dir string
+}
+
+// OpenTokenCache opens the token cache in the config directory.
+func OpenTokenCache() (*TokenCache, error) {
+	dir, err := config.EnsureTokenCacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenCache{dir: dir}, nil
+}
+
+// Get returns the cached token for the profile and client ID if it exists,
+// decrypts with the given secret and has not expired.
+func (c *TokenCache) Get(profile, clientID, clientSecret string) (*Token, bool) {
+	b, err := os.ReadFile(c.path(profile, clientID))
+	if err != nil {
+		return nil, false
+	}
+
+	plain, err := decryptToken(tokenKey(clientID, clientSecret), b)
+	if err != nil {
+		return nil, false
+	}
+
+	var token Token
+	if err := json.Unmarshal(plain, &token); err != nil {
+		return nil, false
+	}
+
+	if token.IsExpired() {
+		return nil, false
+	}
+
+	return &token, true
+}
+
+// Put stores a token for the profile and client ID.
+func (c *TokenCache) Put(profile, clientID, clientSecret string, token *Token) error {
+	plain, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("marshal token: %w", err)
+	}
+
+	sealed, err := encryptToken(tokenKey(clientID, clientSecret), plain)
+	if err != nil {
+		return err
+	}
+
+	path := c.path(profile, clientID)
+
+	// A temp file of its own per writer, so concurrent invocations for the
+	// same profile never rename each other's partial writes into place.
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("write token cache: %w", err)
+	}
+
+	_, err = tmp.Write(sealed)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return fmt.Errorf("write token cache: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return fmt.Errorf("commit token cache: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the cached token for the profile and client ID.
+func (c *TokenCache) Delete(profile, clientID string) error {
+	if err := os.Remove(c.path(profile, clientID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("delete token cache: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteProfile removes all cached tokens for a profile.
+func (c *TokenCache) DeleteProfile(profile string) error {
+	matches, err := filepath.Glob(filepath.Join(c.dir, hashName(profile)+"-*"+tokenFileExt))
+	if err != nil {
+		return fmt.Errorf("list token cache: %w", err)
+	}
+
+	for _, m := range matches {
+		if err := os.Remove(m); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("delete token cache: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// path hashes the profile and client ID so neither leaks into file names.
+func (c *TokenCache) path(profile, clientID string) string {
+	return filepath.Join(c.dir, hashName(profile)+"-"+hashName(clientID)+tokenFileExt)
+}
+
+func hashName(s string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(s)))
+
+	return hex.EncodeToString(sum[:8])
+}
+
+func tokenKey(clientID, clientSecret string) []byte {
+	sum := sha256.Sum256([]byte("ponto-token-cache\x00" + clientID + "\x00" + clientSecret))
+
+	return sum[:]
+}
+
+func encryptToken(key, plain []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+
+	return gcm.Seal(nonce, nonce, plain, nil), nil
+}
+
+func decryptToken(key, sealed []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errTokenCacheCorrupt
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+
+	plain, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errTokenCacheCorrupt
+	}
+
+	return plain, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create gcm: %w", err)
+	}
+
+	return gcm, nil
+}
@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTokenCache(t *testing.T) {
+	t.Parallel()
+
+	cache := &TokenCache{dir: t.TempDir()}
+	token := &Token{AccessToken: "abc", Scope: "ai pi", ExpiresAt: time.Now().Add(time.Hour)}
+
+	if err := cache.Put("default", "client", "secret", token); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	got, ok := cache.Get("default", "client", "secret")
+	if !ok {
+		t.Fatal("Get() miss after Put")
+	}
+
+	if got.AccessToken != "abc" || got.Scope != "ai pi" {
+		t.Errorf("Get() = %+v, want access token abc with scope", got)
+	}
+
+	if _, ok := cache.Get("default", "client", "rotated-secret"); ok {
+		t.Error("Get() with a different secret should miss")
+	}
+
+	if _, ok := cache.Get("other", "client", "secret"); ok {
+		t.Error("Get() for another profile should miss")
+	}
+
+	entries, _ := os.ReadDir(cache.dir)
+	for _, e := range entries {
+		info, _ := e.Info()
+		if perm := info.Mode().Perm(); perm != 0o600 {
+			t.Errorf("%s has mode %o, want 600", e.Name(), perm)
+		}
+	}
+
+	if err := cache.DeleteProfile("default"); err != nil {
+		t.Fatalf("DeleteProfile() error = %v", err)
+	}
+
+	if _, ok := cache.Get("default", "client", "secret"); ok {
+		t.Error("Get() hit after DeleteProfile")
+	}
+}
+
+func TestTokenCacheExpired(t *testing.T) {
+	t.Parallel()
+
+	cache := &TokenCache{dir: t.TempDir()}
+	token := &Token{AccessToken: "old", ExpiresAt: time.Now().Add(30 * time.Second)}
+
+	if err := cache.Put("default", "client", "secret", token); err != nil {
+		t.Fatal(err)
+	}
+
+	// Within the refresh buffer counts as expired
+	if _, ok := cache.Get("default", "client", "secret"); ok {
+		t.Error("Get() returned a token inside the expiry buffer")
+	}
+}
+
+func TestTokenCacheConcurrentPut(t *testing.T) {
+	t.Parallel()
+
+	cache := &TokenCache{dir: t.TempDir()}
+
+	var wg sync.WaitGroup
+
+	for i := range 20 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			token := &Token{AccessToken: fmt.Sprintf("token-%d", i), ExpiresAt: time.Now().Add(time.Hour)}
+			if err := cache.Put("default", "client", "secret", token); err != nil {
+				t.Errorf("Put() error = %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if _, ok := cache.Get("default", "client", "secret"); !ok {
+		t.Error("Get() miss after concurrent Puts, want one complete token")
+	}
+
+	if entries, _ := os.ReadDir(cache.dir); len(entries) != 1 {
+		t.Errorf("cache dir has %d entries, want only the token (temp files left behind)", len(entries))
+	}
+}
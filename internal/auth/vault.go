@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dedene/ponto-cli/internal/config"
+)
+
+const (
+	vaultAddrEnv      = "VAULT_ADDR"
+	vaultTokenEnv     = "VAULT_TOKEN"
+	vaultNamespaceEnv = "VAULT_NAMESPACE"
+)
+
+var errNoVaultToken = errors.New("no Vault token; set VAULT_TOKEN or run 'vault login'")
+
+// vaultBackend stores secrets in a HashiCorp Vault KV v2 secrets engine at
+// <mount>/<path>/<profile>/<name>, each under the field "value".
+type vaultBackend struct {
+	address   string
+	mount     string
+	path      string
+	token     string
+	namespace string
+	client    *http.Client
+}
+
+func newVaultBackend(cfg config.VaultBackend) (*vaultBackend, error) {
+	b := &vaultBackend{
+		address:   strings.TrimRight(cfg.Address, "/"),
+		mount:     strings.Trim(cfg.Mount, "/"),
+		path:      strings.Trim(cfg.Path, "/"),
+		token:     os.Getenv(vaultTokenEnv),
+		namespace: os.Getenv(vaultNamespaceEnv),
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}
+
+	if b.address == "" {
+		b.address = strings.TrimRight(os.Getenv(vaultAddrEnv), "/")
+	}
+
+	if b.address == "" {
+		return nil, fmt.Errorf("vault backend requires backends.vault.address or %s", vaultAddrEnv)
+	}
+
+	if b.mount == "" {
+		b.mount = "secret"
+	}
+
+	if b.path == "" {
+		b.path = config.AppName
+	}
+
+	if b.token == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			if data, err := os.ReadFile(filepath.Join(home, ".vault-token")); err == nil {
+				b.token = strings.TrimSpace(string(data))
+			}
+		}
+	}
+
+	if b.token == "" {
+		return nil, errNoVaultToken
+	}
+
+	return b, nil
+}
+
+type vaultSecret struct {
+	Data struct {
+		Data map[string]string `json:"data"`
+	} `json:"data"`
+}
+
+func (b *vaultBackend) get(profile, name string) (string, error) {
+	resp, err := b.do(http.MethodGet, "data", profile, name, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", errSecretNotFound
+	}
+
+	if err := vaultError(resp); err != nil {
+		return "", err
+	}
+
+	var secret vaultSecret
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return "", fmt.Errorf("parse vault response: %w", err)
+	}
+
+	value, ok := secret.Data.Data["value"]
+	if !ok {
+		return "", errSecretNotFound
+	}
+
+	return value, nil
+}
+
+func (b *vaultBackend) set(profile, name, value string) error {
+	body, err := json.Marshal(map[string]any{"data": map[string]string{"value": value}})
+	if err != nil {
+		return fmt.Errorf("marshal vault secret: %w", err)
+	}
+
+	resp, err := b.do(http.MethodPost, "data", profile, name, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return vaultError(resp)
+}
+
+func (b *vaultBackend) remove(profile, name string) error {
+	// Deleting the metadata removes all versions of the secret
+	resp, err := b.do(http.MethodDelete, "metadata", profile, name, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+
+	return vaultError(resp)
+}
+
+func (b *vaultBackend) do(method, kind, profile, name string, body []byte) (*http.Response, error) {
+	profileSeg, err := vaultSegment(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	nameSeg, err := vaultSegment(name)
+	if err != nil {
+		return nil, err
+	}
+
+	u := fmt.Sprintf("%s/v1/%s/%s/%s/%s/%s", b.address, b.mount, kind, b.path, profileSeg, nameSeg)
+
+	req, err := http.NewRequest(method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create vault request: %w", err)
+	}
+
+	req.Header.Set("X-Vault-Token", b.token)
+
+	if b.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", b.namespace)
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault request: %w", err)
+	}
+
+	return resp, nil
+}
+
+// vaultSegment escapes a profile or secret name for use as one segment of a
+// Vault path. Vault decodes the path before routing, so names that would
+// still address another secret, like "a/b" or "..", are refused.
+func vaultSegment(s string) (string, error) {
+	if s == "" || s == "." || s == ".." || strings.ContainsAny(s, `/\`) {
+		return "", fmt.Errorf("profile or secret name %q cannot be stored in Vault", s)
+	}
+
+	return url.PathEscape(s), nil
+}
+
+func vaultError(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	return fmt.Errorf("vault: %s - %s", resp.Status, strings.TrimSpace(string(body)))
+}
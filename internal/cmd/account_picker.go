@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+
+	"github.com/dedene/ponto-cli/internal/api"
+	"github.com/dedene/ponto-cli/internal/config"
+	pontoCtx "github.com/dedene/ponto-cli/internal/ctx"
+	"github.com/dedene/ponto-cli/internal/output"
+	"github.com/dedene/ponto-cli/internal/tui"
+)
+
+// canPickAccount reports whether the user can be asked to choose an
+// account: stdin and stderr must be terminals, and the command must not be
+// running for several profiles at once.
+func canPickAccount(ctx context.Context) bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) &&
+		term.IsTerminal(int(os.Stderr.Fd())) &&
+		!output.Collecting(ctx)
+}
+
+// pickAccount lets the user choose one of accounts and offers to save the
+// choice as the profile's default account.
+func pickAccount(ctx context.Context, accounts []api.Account) (string, error) {
+	items := make([]tui.Item, len(accounts))
+	for i, a := range accounts {
+		items[i] = tui.Item{
+			Title:  firstNonEmpty(a.Description, a.Reference, a.ID),
+			Detail: fmt.Sprintf("%s  %.2f %s", a.Reference, a.CurrentBalance, a.Currency),
+		}
+	}
+
+	i, err := tui.Pick(os.Stdin, os.Stderr, "Select an account:", items)
+	if errors.Is(err, tui.ErrCancelled) {
+		return "", errors.New("no account selected; specify --account-id or run 'ponto config set account-id <id>'")
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("select account: %w", err)
+	}
+
+	account := accounts[i]
+	profile := pontoCtx.ProfileFrom(ctx)
+
+	fmt.Fprintf(os.Stderr, "Using account %s (%s)\n", items[i].Title, account.ID)
+
+	if confirm(fmt.Sprintf("Save as default account for profile %q?", profile), false) {
+		if err := saveDefaultAccount(profile, account.ID); err != nil {
+			return "", err
+		}
+
+		fmt.Fprintf(os.Stderr, "Default account saved for profile %q\n", profile)
+	}
+
+	return account.ID, nil
+}
+
+func saveDefaultAccount(profile, accountID string) error {
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	if cfg.Profiles == nil {
+		cfg.Profiles = make(map[string]config.Profile)
+	}
+
+	p := cfg.Profiles[profile]
+	p.AccountID = accountID
+	cfg.Profiles[profile] = p
+
+	if err := config.WriteConfig(cfg); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+
+	return nil
+}
@@ -2,29 +2,35 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/dedene/ponto-cli/internal/api"
 	"github.com/dedene/ponto-cli/internal/config"
 	pontoCtx "github.com/dedene/ponto-cli/internal/ctx"
 )
 
+// accountIDPattern matches Ponto account IDs (UUIDs).
+var accountIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
 // ResolveAccountID resolves an account ID from flag, config, or auto-detection.
-// Priority: flag > config > single account auto-detect.
+// Priority: flag > config > single account auto-detect > interactive picker
+// (on a terminal, when there are several accounts). Flag and config values
+// may be an account ID, an alias, an IBAN or IBAN suffix, or part of the
+// account name.
 func ResolveAccountID(ctx context.Context, flagValue string) (string, error) {
+	p := profileConfig(ctx)
+
 	// 1. Flag takes precedence
 	if flagValue != "" {
-		return flagValue, nil
+		return resolveAccountRef(ctx, p, flagValue)
 	}
 
 	// 2. Check config
-	profile := pontoCtx.ProfileFrom(ctx)
-
-	cfg, err := config.ReadConfig()
-	if err == nil {
-		if p, ok := cfg.Profiles[profile]; ok && p.AccountID != "" {
-			return p.AccountID, nil
-		}
+	if p.AccountID != "" {
+		return resolveAccountRef(ctx, p, p.AccountID)
 	}
 
 	// 3. Auto-detect single account
@@ -46,5 +52,210 @@ func ResolveAccountID(ctx context.Context, flagValue string) (string, error) {
 		return "", fmt.Errorf("no accounts found; link an account first")
 	}
 
+	if canPickAccount(ctx) {
+		return pickAccount(ctx, accounts)
+	}
+
 	return "", fmt.Errorf("multiple accounts found; specify --account-id or run 'ponto config set account-id <id>'")
 }
+
+// resolveAccountIDs resolves a repeatable --account-id flag, or every
+// account of the profile with --all-accounts. Without either it falls back
+// to ResolveAccountID.
+func resolveAccountIDs(ctx context.Context, refs []string, all bool) ([]string, error) {
+	if all {
+		if len(refs) > 0 {
+			return nil, errors.New("--account-id and --all-accounts are mutually exclusive")
+		}
+
+		client, err := api.NewClientFromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		accounts, err := client.ListAccounts(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list accounts: %w", err)
+		}
+
+		if len(accounts) == 0 {
+			return nil, fmt.Errorf("no accounts found; link an account first")
+		}
+
+		ids := make([]string, len(accounts))
+		for i, a := range accounts {
+			ids[i] = a.ID
+		}
+
+		return ids, nil
+	}
+
+	if len(refs) <= 1 {
+		ref := ""
+		if len(refs) == 1 {
+			ref = refs[0]
+		}
+
+		id, err := ResolveAccountID(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+
+		return []string{id}, nil
+	}
+
+	p := profileConfig(ctx)
+	seen := make(map[string]bool)
+
+	var ids []string
+
+	for _, ref := range refs {
+		id, err := resolveAccountRef(ctx, p, ref)
+		if err != nil {
+			return nil, err
+		}
+
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+// resolveAccountRef resolves an account reference. Aliases and account IDs
+// resolve without an API call; anything else is matched against the
+// profile's accounts.
+func resolveAccountRef(ctx context.Context, p config.Profile, ref string) (string, error) {
+	return resolveAccountRefWith(ctx, p, ref, func(ctx context.Context) ([]api.Account, error) {
+		client, err := api.NewClientFromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return client.ListAccounts(ctx)
+	})
+}
+
+// resolveAccountRefWith is resolveAccountRef with the accounts coming from
+// list, which is only called when the reference needs them.
+func resolveAccountRefWith(ctx context.Context, p config.Profile, ref string, list func(context.Context) ([]api.Account, error)) (string, error) {
+	ref = strings.TrimSpace(ref)
+
+	if id, ok := lookupAlias(p.Aliases, ref); ok {
+		return id, nil
+	}
+
+	if accountIDPattern.MatchString(ref) {
+		return ref, nil
+	}
+
+	accounts, err := list(ctx)
+	if err != nil {
+		return "", fmt.Errorf("list accounts: %w", err)
+	}
+
+	account, err := matchAccount(accounts, ref)
+	if err != nil {
+		return "", err
+	}
+
+	return account.ID, nil
+}
+
+// profileConfig returns the config of the profile in ctx, or an empty
+// profile when the config cannot be read.
+func profileConfig(ctx context.Context) config.Profile {
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		return config.Profile{}
+	}
+
+	return cfg.Profiles[pontoCtx.ProfileFrom(ctx)]
+}
+
+func lookupAlias(aliases map[string]string, name string) (string, bool) {
+	for alias, id := range aliases {
+		if strings.EqualFold(alias, name) {
+			return id, true
+		}
+	}
+
+	return "", false
+}
+
+// matchAccount finds the account ref refers to, trying increasingly loose
+// matches: ID, IBAN, IBAN suffix, exact name, name substring, and finally
+// the name's letters in order. The first tier with matches decides; more
+// than one match there is an error listing the candidates.
+func matchAccount(accounts []api.Account, ref string) (api.Account, error) {
+	iban := normalizeIBAN(ref)
+	name := strings.ToLower(ref)
+
+	tiers := []func(api.Account) bool{
+		func(a api.Account) bool { return strings.EqualFold(a.ID, ref) },
+		func(a api.Account) bool { return normalizeIBAN(a.Reference) == iban },
+		func(a api.Account) bool { return len(iban) >= 4 && strings.HasSuffix(normalizeIBAN(a.Reference), iban) },
+		func(a api.Account) bool { return strings.EqualFold(a.Description, ref) },
+		func(a api.Account) bool { return strings.Contains(strings.ToLower(a.Description), name) },
+		func(a api.Account) bool { return isSubsequence(name, strings.ToLower(a.Description)) },
+	}
+
+	for _, match := range tiers {
+		var found []api.Account
+
+		for _, a := range accounts {
+			if match(a) {
+				found = append(found, a)
+			}
+		}
+
+		switch len(found) {
+		case 0:
+			continue
+		case 1:
+			return found[0], nil
+		default:
+			return api.Account{}, ambiguousAccountError(ref, found)
+		}
+	}
+
+	return api.Account{}, fmt.Errorf("no account matches %q; run 'ponto accounts list'", ref)
+}
+
+func ambiguousAccountError(ref string, candidates []api.Account) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "account %q is ambiguous; candidates:", ref)
+
+	for _, a := range candidates {
+		fmt.Fprintf(&b, "\n  %s  %s  %s", a.ID, a.Reference, a.Description)
+	}
+
+	return errors.New(b.String())
+}
+
+func normalizeIBAN(s string) string {
+	return strings.ToUpper(strings.ReplaceAll(s, " ", ""))
+}
+
+// isSubsequence reports whether the letters of sub appear in s in order.
+func isSubsequence(sub, s string) bool {
+	if sub == "" {
+		return false
+	}
+
+	rest := []rune(sub)
+
+	for _, r := range s {
+		if r == rest[0] {
+			rest = rest[1:]
+			if len(rest) == 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dedene/ponto-cli/internal/api"
+	"github.com/dedene/ponto-cli/internal/config"
+)
+
+func TestMatchAccount(t *testing.T) {
+	t.Parallel()
+
+	accounts := []api.Account{
+		{ID: "id-main", Reference: "BE68539007547034", Description: "Main Account"},
+		{ID: "id-savings", Reference: "BE71096123456769", Description: "Savings"},
+		{ID: "id-ops", Reference: "BE62510007547034", Description: "Operations Account"},
+	}
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    string
+		wantErr string
+	}{
+		{name: "id", ref: "id-savings", want: "id-savings"},
+		{name: "iban with spaces", ref: "be68 5390 0754 7034", want: "id-main"},
+		{name: "iban suffix", ref: "6769", want: "id-savings"},
+		{name: "ambiguous iban suffix", ref: "7034", wantErr: "ambiguous"},
+		{name: "exact name", ref: "savings", want: "id-savings"},
+		{name: "name substring", ref: "operations", want: "id-ops"},
+		{name: "ambiguous name", ref: "account", wantErr: "candidates:\n  id-main"},
+		{name: "fuzzy name", ref: "opsacc", want: "id-ops"},
+		{name: "no match", ref: "payroll", wantErr: "no account matches"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := matchAccount(accounts, tt.ref)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("matchAccount(%q) error = %v, want %q", tt.ref, err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("matchAccount(%q) error = %v", tt.ref, err)
+			}
+
+			if got.ID != tt.want {
+				t.Errorf("matchAccount(%q) = %s, want %s", tt.ref, got.ID, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveAccountRefOffline(t *testing.T) {
+	t.Parallel()
+
+	p := config.Profile{Aliases: map[string]string{"Ops": "alias-target"}}
+
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{ref: "ops", want: "alias-target"},
+		{ref: "0b5ae8a4-8b7c-4f6e-9d3a-2c1b0a9f8e7d", want: "0b5ae8a4-8b7c-4f6e-9d3a-2c1b0a9f8e7d"},
+	}
+
+	for _, tt := range tests {
+		got, err := resolveAccountRef(context.Background(), p, tt.ref)
+		if err != nil {
+			t.Fatalf("resolveAccountRef(%q) error = %v", tt.ref, err)
+		}
+
+		if got != tt.want {
+			t.Errorf("resolveAccountRef(%q) = %q, want %q", tt.ref, got, tt.want)
+		}
+	}
+}
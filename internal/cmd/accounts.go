@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/dedene/ponto-cli/internal/api"
@@ -13,11 +14,14 @@ type AccountsCmd struct {
 	List AccountsListCmd `cmd:"" help:"List all accounts"`
 	Get  AccountsGetCmd  `cmd:"" help:"Get account details"`
 	Sync AccountsSyncCmd `cmd:"" help:"Trigger account synchronization"`
+
+	Alias AccountsAliasCmd `cmd:"" help:"Manage account aliases"`
 }
 
 // AccountsListCmd lists accounts.
 type AccountsListCmd struct {
 	Product string `help:"Filter by product type"`
+	Format  string `help:"Export format instead of the global output mode (xlsx)" enum:",xlsx" default:""`
 }
 
 func (c *AccountsListCmd) Run(ctx context.Context) error {
@@ -31,8 +35,6 @@ func (c *AccountsListCmd) Run(ctx context.Context) error {
 		return fmt.Errorf("list accounts: %w", err)
 	}
 
-	mode := output.ModeFrom(ctx)
-
 	if c.Product != "" {
 		filtered := make([]api.Account, 0)
 
@@ -45,12 +47,29 @@ func (c *AccountsListCmd) Run(ctx context.Context) error {
 		accounts = filtered
 	}
 
-	return output.Accounts(mode, accounts)
+	if c.Format == "xlsx" {
+		w, err := binaryWriter(ctx)
+		if err != nil {
+			return err
+		}
+
+		return output.AccountsXLSX(ctx, w, accounts)
+	}
+
+	return output.Accounts(ctx, accounts)
+}
+
+func (c *AccountsListCmd) checkFanout() error {
+	if c.Format == "xlsx" {
+		return errors.New("--format=xlsx is not supported with --profiles")
+	}
+
+	return nil
 }
 
 // AccountsGetCmd gets account details.
 type AccountsGetCmd struct {
-	ID string `arg:"" help:"Account ID (use - for stdin)"`
+	ID string `arg:"" help:"Account ID, alias, IBAN (suffix) or name (use - for stdin)"`
 }
 
 func (c *AccountsGetCmd) Run(ctx context.Context) error {
@@ -59,8 +78,6 @@ func (c *AccountsGetCmd) Run(ctx context.Context) error {
 		return err
 	}
 
-	mode := output.ModeFrom(ctx)
-
 	// Handle stdin batching
 	ids, err := ReadStdinIDs(c.ID)
 	if err != nil {
@@ -71,13 +88,20 @@ func (c *AccountsGetCmd) Run(ctx context.Context) error {
 		ids = []string{c.ID}
 	}
 
-	for _, id := range ids {
+	p := profileConfig(ctx)
+
+	for _, ref := range ids {
+		id, err := resolveAccountRef(ctx, p, ref)
+		if err != nil {
+			return err
+		}
+
 		account, err := client.GetAccount(ctx, id)
 		if err != nil {
 			return fmt.Errorf("get account %s: %w", id, err)
 		}
 
-		if err := output.Account(mode, account); err != nil {
+		if err := output.Account(ctx, account); err != nil {
 			return err
 		}
 	}
@@ -87,23 +111,26 @@ func (c *AccountsGetCmd) Run(ctx context.Context) error {
 
 // AccountsSyncCmd triggers synchronization.
 type AccountsSyncCmd struct {
-	ID   string `arg:"" help:"Account ID"`
+	ID   string `arg:"" help:"Account ID, alias, IBAN (suffix) or name"`
 	Wait bool   `help:"Wait for sync to complete"`
 }
 
 func (c *AccountsSyncCmd) Run(ctx context.Context) error {
+	id, err := resolveAccountRef(ctx, profileConfig(ctx), c.ID)
+	if err != nil {
+		return err
+	}
+
 	client, err := api.NewClientFromContext(ctx)
 	if err != nil {
 		return err
 	}
 
-	sync, err := client.CreateSync(ctx, c.ID, "accountTransactions")
+	sync, err := client.CreateSync(ctx, id, "accountTransactions")
 	if err != nil {
 		return fmt.Errorf("create sync: %w", err)
 	}
 
-	mode := output.ModeFrom(ctx)
-
 	if c.Wait {
 		sync, err = client.WaitForSync(ctx, sync.ID)
 		if err != nil {
@@ -111,5 +138,5 @@ func (c *AccountsSyncCmd) Run(ctx context.Context) error {
 		}
 	}
 
-	return output.Sync(mode, sync)
+	return output.Sync(ctx, sync)
 }
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/dedene/ponto-cli/internal/api"
+	"github.com/dedene/ponto-cli/internal/config"
+	pontoCtx "github.com/dedene/ponto-cli/internal/ctx"
+	"github.com/dedene/ponto-cli/internal/output"
+)
+
+// AccountsAliasCmd manages named aliases for accounts.
+type AccountsAliasCmd struct {
+	Set    AccountsAliasSetCmd    `cmd:"" help:"Create or update an account alias"`
+	List   AccountsAliasListCmd   `cmd:"" help:"List account aliases"`
+	Remove AccountsAliasRemoveCmd `cmd:"" help:"Remove an account alias"`
+}
+
+// AccountsAliasSetCmd creates or updates an alias.
+type AccountsAliasSetCmd struct {
+	Name    string `arg:"" help:"Alias name"`
+	Account string `arg:"" help:"Account ID, IBAN (suffix) or name"`
+}
+
+func (c *AccountsAliasSetCmd) Run(ctx context.Context) error {
+	profile := pontoCtx.ProfileFrom(ctx)
+
+	name := strings.TrimSpace(c.Name)
+	if name == "" || strings.ContainsAny(name, " \t,") {
+		return fmt.Errorf("invalid alias %q: must be non-empty without spaces or commas", c.Name)
+	}
+
+	if accountIDPattern.MatchString(name) {
+		return fmt.Errorf("invalid alias %q: looks like an account ID", name)
+	}
+
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	p := cfg.Profiles[profile]
+
+	id, err := resolveAccountRef(ctx, p, c.Account)
+	if err != nil {
+		return err
+	}
+
+	if p.Aliases == nil {
+		p.Aliases = make(map[string]string)
+	}
+
+	// Aliases match case-insensitively, so replace any differently-cased one.
+	for alias := range p.Aliases {
+		if strings.EqualFold(alias, name) {
+			delete(p.Aliases, alias)
+		}
+	}
+
+	p.Aliases[name] = id
+
+	if cfg.Profiles == nil {
+		cfg.Profiles = make(map[string]config.Profile)
+	}
+
+	cfg.Profiles[profile] = p
+
+	if err := config.WriteConfig(cfg); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+
+	if output.ModeFrom(ctx) == output.ModeTable {
+		fmt.Printf("Alias %q -> %s for profile %q\n", name, id, profile)
+	}
+
+	return nil
+}
+
+// AccountsAliasListCmd lists aliases.
+type AccountsAliasListCmd struct{}
+
+func (c *AccountsAliasListCmd) Run(ctx context.Context) error {
+	p := profileConfig(ctx)
+
+	// Account details are a convenience; aliases are listed without them
+	// when the API is unreachable.
+	byID := make(map[string]api.Account)
+
+	if len(p.Aliases) > 0 {
+		var accounts []api.Account
+
+		client, err := api.NewClientFromContext(ctx)
+		if err == nil {
+			accounts, err = client.ListAccounts(ctx)
+		}
+
+		if err != nil {
+			slog.Debug("list accounts for aliases", "error", err)
+		}
+
+		for _, a := range accounts {
+			byID[a.ID] = a
+		}
+	}
+
+	aliases := make([]output.AccountAlias, 0, len(p.Aliases))
+
+	for name, id := range p.Aliases {
+		account := byID[id]
+
+		aliases = append(aliases, output.AccountAlias{
+			Alias:       name,
+			AccountID:   id,
+			IBAN:        account.Reference,
+			Description: account.Description,
+		})
+	}
+
+	sort.Slice(aliases, func(i, j int) bool { return aliases[i].Alias < aliases[j].Alias })
+
+	return output.AccountAliases(ctx, aliases)
+}
+
+// AccountsAliasRemoveCmd removes an alias.
+type AccountsAliasRemoveCmd struct {
+	Name string `arg:"" help:"Alias name"`
+}
+
+func (c *AccountsAliasRemoveCmd) Run(ctx context.Context) error {
+	profile := pontoCtx.ProfileFrom(ctx)
+
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	p := cfg.Profiles[profile]
+
+	removed := false
+
+	for alias := range p.Aliases {
+		if strings.EqualFold(alias, c.Name) {
+			delete(p.Aliases, alias)
+
+			removed = true
+		}
+	}
+
+	if !removed {
+		return fmt.Errorf("alias %q not found for profile %q", c.Name, profile)
+	}
+
+	cfg.Profiles[profile] = p
+
+	if err := config.WriteConfig(cfg); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+
+	if output.ModeFrom(ctx) == output.ModeTable {
+		fmt.Printf("Alias %q removed from profile %q\n", c.Name, profile)
+	}
+
+	return nil
+}
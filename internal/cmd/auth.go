@@ -3,63 +3,213 @@ package cmd
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
 	"strings"
+	"time"
 
 	"golang.org/x/term"
 
+	"github.com/dedene/ponto-cli/internal/api"
 	"github.com/dedene/ponto-cli/internal/auth"
+	"github.com/dedene/ponto-cli/internal/config"
 	pontoCtx "github.com/dedene/ponto-cli/internal/ctx"
-	"github.com/dedene/ponto-cli/internal/output"
 )
 
+var errNoSecretTTY = errors.New("no terminal for the client secret prompt")
+
 // AuthCmd is the parent command for authentication.
 type AuthCmd struct {
-	Login  AuthLoginCmd  `cmd:"" help:"Store credentials in keyring"`
-	Logout AuthLogoutCmd `cmd:"" help:"Remove credentials from keyring"`
-	Status AuthStatusCmd `cmd:"" help:"Show authentication status"`
+	Login  AuthLoginCmd      `cmd:"" help:"Store credentials in the credential store"`
+	Logout AuthLogoutCmd     `cmd:"" help:"Remove credentials from the credential store"`
+	Status AuthStatusCmd     `cmd:"" help:"Show authentication status"`
+	Rotate AuthRotateCmd     `cmd:"" help:"Rotate the client secret"`
+	Sign   AuthSigningKeyCmd `cmd:"" name:"signing-key" help:"Configure request signing for payment endpoints"`
 }
 
 // AuthLoginCmd stores credentials.
-type AuthLoginCmd struct{}
+type AuthLoginCmd struct {
+	Connect      bool   `help:"Log in to a Ponto Connect integration (mTLS and authorization code)"`
+	Cert         string `help:"Client certificate (PEM, or PKCS#12 bundle)" type:"existingfile"`
+	Key          string `help:"Client private key (PEM); omit for PKCS#12 bundles" type:"existingfile"`
+	RedirectURI  string `help:"Loopback redirect URI registered for the integration" default:"${connect_redirect_uri}"`
+	AuthorizeURL string `help:"Authorization endpoint (defaults to the sandbox endpoint for --sandbox)"`
+	Scope        string `help:"OAuth scopes to request" default:"${connect_scope}"`
+	NoBrowser    bool   `help:"Print the authorization URL instead of opening a browser"`
+
+	ClientIDFile      string `help:"Read the client ID from a file" type:"existingfile"`
+	ClientSecretStdin bool   `help:"Read the client secret from stdin"`
+	NoVerify          bool   `help:"Store the credentials without verifying them"`
+}
 
 func (c *AuthLoginCmd) Run(ctx context.Context) error {
 	profile := pontoCtx.ProfileFrom(ctx)
 
+	if !c.Connect && (c.Cert != "" || c.Key != "") {
+		return fmt.Errorf("--cert and --key require --connect")
+	}
+
+	if c.Connect && c.NoVerify {
+		return fmt.Errorf("--no-verify cannot be used with --connect")
+	}
+
 	fmt.Printf("Logging in to profile: %s\n", profile)
-	fmt.Println("Enter your Ponto API credentials (from the Ponto dashboard):")
 
-	reader := bufio.NewReader(os.Stdin)
+	clientID, clientSecret, err := c.readCredentials()
+	if err != nil {
+		return err
+	}
+
+	if c.Connect {
+		return c.runConnect(ctx, profile, clientID, clientSecret)
+	}
+
+	var token *auth.Token
+
+	if !c.NoVerify {
+		// Test the credentials by fetching a token
+		fmt.Print("Verifying credentials... ")
+
+		token, err = auth.GetAccessToken(ctx, clientID, clientSecret)
+		if err != nil {
+			fmt.Println("failed")
+
+			return fmt.Errorf("authentication failed: %w", err)
+		}
 
-	fmt.Print("Client ID: ")
+		fmt.Println("ok")
+	}
 
-	clientID, err := reader.ReadString('\n')
+	// Store in the credential store
+	store, err := auth.OpenStore()
 	if err != nil {
-		return fmt.Errorf("read client ID: %w", err)
+		return fmt.Errorf("open credential store: %w", err)
+	}
+
+	if err := store.SetCredentials(profile, clientID, clientSecret); err != nil {
+		return fmt.Errorf("store credentials: %w", err)
 	}
 
-	clientID = strings.TrimSpace(clientID)
+	if err := registerProfile(profile); err != nil {
+		return err
+	}
 
-	fmt.Print("Client Secret: ")
+	fmt.Printf("Credentials stored for profile %q\n", profile)
 
-	secretBytes, err := term.ReadPassword(int(os.Stdin.Fd()))
+	if token != nil {
+		fmt.Printf("Token scopes: %s\n", token.Scope)
+	}
+
+	return nil
+}
+
+// registerProfile adds profile to the config file so 'profiles list' shows
+// it.
+func registerProfile(profile string) error {
+	cfg, err := config.ReadConfig()
 	if err != nil {
-		return fmt.Errorf("read client secret: %w", err)
+		return fmt.Errorf("read config: %w", err)
 	}
 
-	fmt.Println() // newline after hidden input
+	if _, ok := cfg.Profiles[profile]; ok {
+		return nil
+	}
+
+	if cfg.Profiles == nil {
+		cfg.Profiles = make(map[string]config.Profile)
+	}
 
-	clientSecret := strings.TrimSpace(string(secretBytes))
+	cfg.Profiles[profile] = config.Profile{}
 
-	if clientID == "" || clientSecret == "" {
-		return fmt.Errorf("client ID and secret are required")
+	if err := config.WriteConfig(cfg); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+
+	return nil
+}
+
+// runConnect authorizes a Ponto Connect integration in the browser and
+// stores its refresh token and certificate settings.
+func (c *AuthLoginCmd) runConnect(ctx context.Context, profile, clientID, clientSecret string) error {
+	if c.Cert == "" {
+		return fmt.Errorf("--connect requires --cert")
+	}
+
+	cp := &config.ConnectProfile{
+		CertFile:     absPath(c.Cert),
+		KeyFile:      absPath(c.Key),
+		AuthorizeURL: c.AuthorizeURL,
+		RedirectURI:  c.RedirectURI,
+		Scope:        c.Scope,
+	}
+
+	if cp.AuthorizeURL == "" && profile == "sandbox" {
+		cp.AuthorizeURL = auth.ConnectSandboxAuthorizeURL
+	}
+
+	var passphrase string
+
+	if cp.KeyFile == "" && auth.IsPKCS12(cp.CertFile) {
+		fmt.Print("Certificate passphrase: ")
+
+		b, err := term.ReadPassword(int(os.Stdin.Fd()))
+		if err != nil {
+			return fmt.Errorf("read certificate passphrase: %w", err)
+		}
+
+		fmt.Println()
+
+		passphrase = string(b)
+	}
+
+	flow, err := auth.NewConnectFlow(cp, clientID, clientSecret, passphrase)
+	if err != nil {
+		return err
+	}
+
+	pkce, err := auth.NewPKCE()
+	if err != nil {
+		return err
+	}
+
+	state, err := auth.NewState()
+	if err != nil {
+		return err
+	}
+
+	callback, err := auth.StartCallbackServer(flow.RedirectURI, state)
+	if err != nil {
+		return err
 	}
+	defer callback.Close()
 
-	// Test the credentials by fetching a token
-	fmt.Print("Verifying credentials... ")
+	authURL := flow.AuthCodeURL(state, pkce)
+
+	fmt.Println("Open this URL to authorize the integration:")
+	fmt.Println(authURL)
+
+	if !c.NoBrowser {
+		if err := openBrowser(authURL); err != nil {
+			fmt.Fprintf(os.Stderr, "Could not open browser: %v\n", err)
+		}
+	}
 
-	token, err := auth.GetAccessToken(ctx, clientID, clientSecret)
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	code, err := callback.Wait(waitCtx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print("Exchanging authorization code... ")
+
+	token, err := flow.Exchange(ctx, code, pkce)
 	if err != nil {
 		fmt.Println("failed")
 
@@ -68,84 +218,382 @@ func (c *AuthLoginCmd) Run(ctx context.Context) error {
 
 	fmt.Println("ok")
 
-	// Store in keyring
-	store, err := auth.OpenKeyring()
+	if token.RefreshToken == "" {
+		return fmt.Errorf("authorization server returned no refresh token (request the offline_access scope)")
+	}
+
+	store, err := auth.OpenStore()
 	if err != nil {
-		return fmt.Errorf("open keyring: %w", err)
+		return fmt.Errorf("open credential store: %w", err)
 	}
 
 	if err := store.SetCredentials(profile, clientID, clientSecret); err != nil {
 		return fmt.Errorf("store credentials: %w", err)
 	}
 
-	fmt.Printf("Credentials stored for profile %q\n", profile)
+	if err := auth.SaveConnectSecrets(store, profile, token.RefreshToken, passphrase); err != nil {
+		return fmt.Errorf("store refresh token: %w", err)
+	}
+
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	if cfg.Profiles == nil {
+		cfg.Profiles = make(map[string]config.Profile)
+	}
+
+	p := cfg.Profiles[profile]
+	p.Connect = cp
+	cfg.Profiles[profile] = p
+
+	if err := config.WriteConfig(cfg); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+
+	fmt.Printf("Ponto Connect credentials stored for profile %q\n", profile)
 	fmt.Printf("Token scopes: %s\n", token.Scope)
 
 	return nil
 }
 
-// AuthLogoutCmd removes credentials.
-type AuthLogoutCmd struct{}
+// readCredentials reads the client ID and secret from the flags, the
+// environment or, when attached to a terminal, interactive prompts.
+func (c *AuthLoginCmd) readCredentials() (string, string, error) {
+	envID, envSecret := os.Getenv(auth.ClientIDEnv), os.Getenv(auth.ClientSecretEnv)
+	interactive := term.IsTerminal(int(os.Stdin.Fd()))
 
-func (c *AuthLogoutCmd) Run(ctx context.Context) error {
-	profile := pontoCtx.ProfileFrom(ctx)
+	if c.ClientSecretStdin && c.ClientIDFile == "" && envID == "" {
+		return "", "", fmt.Errorf("--client-secret-stdin requires --client-id-file or %s", auth.ClientIDEnv)
+	}
+
+	if interactive && (c.ClientIDFile == "" && envID == "" || !c.ClientSecretStdin && envSecret == "") {
+		fmt.Println("Enter your Ponto API credentials (from the Ponto dashboard):")
+	}
+
+	var clientID string
+
+	switch {
+	case c.ClientIDFile != "":
+		b, err := os.ReadFile(c.ClientIDFile)
+		if err != nil {
+			return "", "", fmt.Errorf("read client ID: %w", err)
+		}
+
+		clientID = string(b)
+	case envID != "":
+		clientID = envID
+	case interactive:
+		fmt.Print("Client ID: ")
+
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil {
+			return "", "", fmt.Errorf("read client ID: %w", err)
+		}
+
+		clientID = line
+	default:
+		return "", "", fmt.Errorf("no terminal for the client ID prompt; use --client-id-file or %s", auth.ClientIDEnv)
+	}
+
+	clientSecret := envSecret
+
+	if c.ClientSecretStdin || envSecret == "" {
+		secret, err := readSecret("Client Secret: ", c.ClientSecretStdin)
+		if err != nil {
+			if errors.Is(err, errNoSecretTTY) {
+				return "", "", fmt.Errorf("%w; use --client-secret-stdin or %s", err, auth.ClientSecretEnv)
+			}
+
+			return "", "", err
+		}
+
+		clientSecret = secret
+	}
+
+	clientID, clientSecret = strings.TrimSpace(clientID), strings.TrimSpace(clientSecret)
+
+	if clientID == "" || clientSecret == "" {
+		return "", "", fmt.Errorf("client ID and secret are required")
+	}
+
+	return clientID, clientSecret, nil
+}
+
+// readSecret reads a secret from stdin, or from a hidden prompt when stdin
+// is a terminal.
+func readSecret(prompt string, fromStdin bool) (string, error) {
+	if fromStdin {
+		b, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("read client secret: %w", err)
+		}
+
+		return strings.TrimSpace(string(b)), nil
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", errNoSecretTTY
+	}
 
-	store, err := auth.OpenKeyring()
+	fmt.Print(prompt)
+
+	secretBytes, err := term.ReadPassword(int(os.Stdin.Fd()))
 	if err != nil {
-		return fmt.Errorf("open keyring: %w", err)
+		return "", fmt.Errorf("read client secret: %w", err)
 	}
 
-	if err := store.DeleteCredentials(profile); err != nil {
-		return fmt.Errorf("delete credentials: %w", err)
+	fmt.Println() // newline after hidden input
+
+	return strings.TrimSpace(string(secretBytes)), nil
+}
+
+func absPath(path string) string {
+	if path == "" {
+		return ""
 	}
 
-	fmt.Printf("Credentials removed for profile %q\n", profile)
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
 
-	return nil
+	return abs
+}
+
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+
+	return cmd.Start()
 }
 
-// AuthStatusCmd shows authentication status.
-type AuthStatusCmd struct{}
+// AuthRotateCmd swaps in a new client secret, keeping the previous one as a
+// fallback until the new one is verified against the API.
+type AuthRotateCmd struct {
+	ClientSecretStdin bool `help:"Read the new client secret from stdin"`
+	Finish            bool `help:"Verify an earlier rotation and drop the previous secret"`
+}
 
-func (c *AuthStatusCmd) Run(ctx context.Context) error {
+func (c *AuthRotateCmd) Run(ctx context.Context) error {
 	profile := pontoCtx.ProfileFrom(ctx)
-	mode := output.ModeFrom(ctx)
 
-	store, err := auth.OpenKeyring()
+	if _, _, ok := auth.EnvCredentials(); ok {
+		return fmt.Errorf("credentials come from %s and %s; rotate them where they are set", auth.ClientIDEnv, auth.ClientSecretEnv)
+	}
+
+	store, err := auth.OpenStore()
 	if err != nil {
-		return fmt.Errorf("open keyring: %w", err)
+		return fmt.Errorf("open credential store: %w", err)
 	}
 
-	clientID, clientSecret, err := store.GetCredentials(profile)
+	clientID, currentSecret, err := store.GetCredentials(profile)
 	if err != nil {
-		if mode == output.ModeJSON {
-			fmt.Println(`{"authenticated": false}`)
+		return fmt.Errorf("get credentials for profile %q: %w\nRun 'ponto auth login' to authenticate", profile, err)
+	}
+
+	if !c.Finish {
+		newSecret, err := readSecret("New Client Secret: ", c.ClientSecretStdin)
+		if err != nil {
+			if errors.Is(err, errNoSecretTTY) {
+				return fmt.Errorf("%w; use --client-secret-stdin", err)
+			}
 
-			return nil
+			return err
 		}
 
-		fmt.Printf("Profile: %s\n", profile)
-		fmt.Println("Status: not authenticated")
-		fmt.Println("Run 'ponto auth login' to authenticate.")
+		if newSecret == "" {
+			return fmt.Errorf("client secret is required")
+		}
+
+		if newSecret == currentSecret {
+			return fmt.Errorf("the new client secret matches the current one")
+		}
+
+		fmt.Print("Verifying new secret... ")
+
+		if _, err := auth.VerifyCredentials(ctx, clientID, newSecret); err != nil {
+			fmt.Println("failed")
+
+			return fmt.Errorf("new secret rejected, credentials unchanged: %w", err)
+		}
+
+		fmt.Println("ok")
+
+		if err := auth.RotateSecret(store, profile, newSecret); err != nil {
+			return fmt.Errorf("rotate secret: %w", err)
+		}
+
+		if err := recordRotation(profile); err != nil {
+			return err
+		}
+
+		fmt.Printf("Client secret rotated for profile %q\n", profile)
+	} else if auth.PreviousSecret(store, profile) == "" {
+		fmt.Printf("No rotation pending for profile %q\n", profile)
 
 		return nil
 	}
 
-	// Mask the credentials
-	maskedID := maskString(clientID)
-	maskedSecret := maskString(clientSecret)
+	fmt.Print("Verifying API access... ")
 
-	if mode == output.ModeJSON {
-		fmt.Printf(`{"authenticated": true, "profile": %q, "client_id": %q}`, profile, maskedID)
-		fmt.Println()
+	if err := verifyRotation(ctx, store, profile); err != nil {
+		fmt.Println("failed")
 
-		return nil
+		return fmt.Errorf("previous secret kept as a fallback, run 'ponto auth rotate --finish' to retry: %w", err)
+	}
+
+	fmt.Println("ok")
+
+	if err := auth.DropPreviousSecret(store, profile); err != nil {
+		return fmt.Errorf("drop previous secret: %w", err)
+	}
+
+	fmt.Println("Previous secret removed")
+
+	return nil
+}
+
+// verifyRotation checks that the stored (rotated) secret obtains a token
+// and that the API accepts it.
+func verifyRotation(ctx context.Context, store auth.Store, profile string) error {
+	clientID, clientSecret, err := store.GetCredentials(profile)
+	if err != nil {
+		return err
+	}
+
+	if _, err := auth.VerifyCredentials(ctx, clientID, clientSecret); err != nil {
+		return err
+	}
+
+	client, err := api.NewClientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.GetOrganization(ctx)
+
+	return err
+}
+
+func recordRotation(profile string) error {
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	if cfg.Profiles == nil {
+		cfg.Profiles = make(map[string]config.Profile)
+	}
+
+	p := cfg.Profiles[profile]
+	p.SecretRotatedAt = time.Now().UTC().Truncate(time.Second)
+	cfg.Profiles[profile] = p
+
+	if err := config.WriteConfig(cfg); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+
+	return nil
+}
+
+// AuthSigningKeyCmd configures the request-signing key of a profile.
+type AuthSigningKeyCmd struct {
+	KeyID     string   `help:"Key ID registered with Ponto" required:""`
+	Key       string   `help:"RSA private key (PEM)" type:"existingfile" required:""`
+	Keyring   bool     `help:"Store the key in the credential store instead of referencing the file"`
+	Algorithm string   `help:"Signature algorithm" enum:"rsa-pss-sha512,rsa-v1_5-sha256" default:"rsa-pss-sha512"`
+	Paths     []string `help:"Path patterns to sign (default: payment endpoints)" sep:","`
+}
+
+func (c *AuthSigningKeyCmd) Run(ctx context.Context) error {
+	profile := pontoCtx.ProfileFrom(ctx)
+
+	pemKey, err := os.ReadFile(c.Key)
+	if err != nil {
+		return fmt.Errorf("read signing key: %w", err)
+	}
+
+	if _, err := api.ParseSigningKey(pemKey); err != nil {
+		return err
+	}
+
+	sp := &config.SigningProfile{
+		KeyID:     c.KeyID,
+		KeyFile:   absPath(c.Key),
+		Algorithm: c.Algorithm,
+		Paths:     c.Paths,
 	}
 
-	fmt.Printf("Profile: %s\n", profile)
-	fmt.Println("Status: authenticated")
-	fmt.Printf("Client ID: %s\n", maskedID)
-	fmt.Printf("Client Secret: %s\n", maskedSecret)
+	if c.Keyring {
+		store, err := auth.OpenStore()
+		if err != nil {
+			return fmt.Errorf("open credential store: %w", err)
+		}
+
+		if err := auth.SaveSigningKey(store, profile, string(pemKey)); err != nil {
+			return fmt.Errorf("store signing key: %w", err)
+		}
+
+		sp.KeyFile = ""
+	}
+
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	if cfg.Profiles == nil {
+		cfg.Profiles = make(map[string]config.Profile)
+	}
+
+	p := cfg.Profiles[profile]
+	p.Signing = sp
+	cfg.Profiles[profile] = p
+
+	if err := config.WriteConfig(cfg); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+
+	fmt.Printf("Request signing configured for profile %q (key ID %s)\n", profile, c.KeyID)
+
+	return nil
+}
+
+// AuthLogoutCmd removes credentials.
+type AuthLogoutCmd struct{}
+
+func (c *AuthLogoutCmd) Run(ctx context.Context) error {
+	profile := pontoCtx.ProfileFrom(ctx)
+
+	store, err := auth.OpenStore()
+	if err != nil {
+		return fmt.Errorf("open credential store: %w", err)
+	}
+
+	if err := store.DeleteCredentials(profile); err != nil {
+		return fmt.Errorf("delete credentials: %w", err)
+	}
+
+	cache, err := auth.OpenTokenCache()
+	if err != nil {
+		return fmt.Errorf("open token cache: %w", err)
+	}
+
+	if err := cache.DeleteProfile(profile); err != nil {
+		return fmt.Errorf("clear token cache: %w", err)
+	}
+
+	fmt.Printf("Credentials removed for profile %q\n", profile)
 
 	return nil
 }
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dedene/ponto-cli/internal/api"
+	"github.com/dedene/ponto-cli/internal/auth"
+	"github.com/dedene/ponto-cli/internal/config"
+	pontoCtx "github.com/dedene/ponto-cli/internal/ctx"
+	"github.com/dedene/ponto-cli/internal/output"
+)
+
+const (
+	// secretRotationWarnAge is the client secret age after which auth status
+	// suggests a rotation.
+	secretRotationWarnAge = 90 * 24 * time.Hour
+	// consentWarnDays is how many days before expiry an account consent is
+	// reported as expiring.
+	consentWarnDays = 14
+)
+
+// AuthStatusCmd shows authentication status.
+type AuthStatusCmd struct {
+	Verify bool `help:"Fetch a token and check the organization and account consents"`
+}
+
+func (c *AuthStatusCmd) Run(ctx context.Context) error {
+	profile := pontoCtx.ProfileFrom(ctx)
+	status := &output.AuthStatus{Profile: profile, Backend: "environment"}
+
+	clientID, clientSecret, ok := auth.EnvCredentials()
+	if !ok {
+		store, err := auth.OpenStore()
+		if err != nil {
+			return fmt.Errorf("open credential store: %w", err)
+		}
+
+		status.Backend = store.Backend()
+
+		clientID, clientSecret, err = store.GetCredentials(profile)
+
+		switch {
+		case auth.IsNotFound(err):
+			clientID = ""
+		case err != nil:
+			status.StoreFailed = true
+			status.Problems = append(status.Problems, err.Error())
+
+			return finishStatus(ctx, status, c.Verify)
+		}
+	}
+
+	if clientID == "" {
+		status.Problems = append(status.Problems, "no credentials stored for this profile")
+
+		return finishStatus(ctx, status, c.Verify)
+	}
+
+	status.Authenticated = true
+	status.ClientID = maskString(clientID)
+	status.ClientSecret = maskString(clientSecret)
+
+	if cfg, err := config.ReadConfig(); err == nil {
+		if rotatedAt := cfg.Profiles[profile].SecretRotatedAt; !rotatedAt.IsZero() {
+			status.SecretRotatedAt = &rotatedAt
+
+			if time.Since(rotatedAt) > secretRotationWarnAge {
+				status.Warnings = append(status.Warnings, fmt.Sprintf(
+					"client secret is older than %d days; run 'ponto auth rotate'", int(secretRotationWarnAge.Hours()/24)))
+			}
+		}
+	}
+
+	if c.Verify {
+		verifyStatus(ctx, status, time.Now())
+	}
+
+	return finishStatus(ctx, status, c.Verify)
+}
+
+// verifyStatus fetches a token, the organization and the accounts, recording
+// anything that fails as a problem.
+func verifyStatus(ctx context.Context, status *output.AuthStatus, now time.Time) {
+	status.Verified = true
+
+	client, err := api.NewClientFromContext(ctx)
+	if err != nil {
+		status.Problems = append(status.Problems, err.Error())
+
+		return
+	}
+
+	token, err := client.Token(ctx)
+	if err != nil {
+		status.Problems = append(status.Problems, fmt.Sprintf("token request failed: %v", err))
+
+		return
+	}
+
+	status.Scopes = strings.Fields(token.Scope)
+	status.TokenExpiresAt = &token.ExpiresAt
+
+	org, err := client.GetOrganization(ctx)
+	if err != nil {
+		status.Problems = append(status.Problems, fmt.Sprintf("get organization: %v", err))
+	} else {
+		status.Organization = org.Name
+	}
+
+	accounts, err := client.ListAccounts(ctx)
+	if err != nil {
+		status.Problems = append(status.Problems, fmt.Sprintf("list accounts: %v", err))
+
+		return
+	}
+
+	for _, account := range accounts {
+		consent := accountConsent(account, now)
+
+		switch consent.Status {
+		case "expired":
+			status.Problems = append(status.Problems, fmt.Sprintf("consent for account %s expired on %s", accountLabel(account), consent.ExpiresAt))
+		case "expiring":
+			status.Warnings = append(status.Warnings, fmt.Sprintf("consent for account %s expires in %d days", accountLabel(account), *consent.DaysLeft))
+		}
+
+		status.Accounts = append(status.Accounts, consent)
+	}
+}
+
+// accountConsent classifies an account's consent expiry relative to now.
+func accountConsent(account api.Account, now time.Time) output.AccountConsent {
+	consent := output.AccountConsent{
+		ID:          account.ID,
+		Description: account.Description,
+		Reference:   account.Reference,
+		Status:      "unknown",
+	}
+
+	if account.AuthorizationExpiresAt == "" {
+		return consent
+	}
+
+	expires, err := time.Parse(time.RFC3339, account.AuthorizationExpiresAt)
+	if err != nil {
+		return consent
+	}
+
+	days := int(expires.Sub(now).Hours() / 24)
+	consent.ExpiresAt = expires.Format("2006-01-02")
+	consent.DaysLeft = &days
+
+	switch {
+	case !expires.After(now):
+		consent.Status = "expired"
+	case days < consentWarnDays:
+		consent.Status = "expiring"
+	default:
+		consent.Status = "ok"
+	}
+
+	return consent
+}
+
+func accountLabel(account api.Account) string {
+	if account.Description != "" {
+		return account.Description
+	}
+
+	return account.ID
+}
+
+// finishStatus writes the status. With --verify, problems also turn into a
+// non-zero exit; plain status only reports them.
+func finishStatus(ctx context.Context, status *output.AuthStatus, verify bool) error {
+	if err := output.Status(ctx, status); err != nil {
+		return err
+	}
+
+	if !verify || status.Healthy() {
+		return nil
+	}
+
+	return &ExitError{Code: 1, Err: errors.New("auth status: " + strings.Join(status.Problems, "; "))}
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dedene/ponto-cli/internal/api"
+)
+
+func TestAccountConsent(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		expiresAt string
+		want      string
+	}{
+		{name: "valid", expiresAt: "2024-09-01T00:00:00Z", want: "ok"},
+		{name: "expiring", expiresAt: "2024-06-10T00:00:00Z", want: "expiring"},
+		{name: "expired", expiresAt: "2024-05-01T00:00:00Z", want: "expired"},
+		{name: "missing", expiresAt: "", want: "unknown"},
+		{name: "unparseable", expiresAt: "soon", want: "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := accountConsent(api.Account{ID: "acc", AuthorizationExpiresAt: tt.expiresAt}, now)
+			if got.Status != tt.want {
+				t.Errorf("accountConsent(%q).Status = %q, want %q", tt.expiresAt, got.Status, tt.want)
+			}
+		})
+	}
+}
@@ -2,7 +2,11 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/exec"
+	"strings"
 
 	"github.com/dedene/ponto-cli/internal/config"
 	pontoCtx "github.com/dedene/ponto-cli/internal/ctx"
@@ -11,75 +15,246 @@ import (
 
 // ConfigCmd is the parent command for configuration.
 type ConfigCmd struct {
-	Get ConfigGetCmd `cmd:"" help:"Get configuration value"`
-	Set ConfigSetCmd `cmd:"" help:"Set configuration value"`
+	List  ConfigListCmd  `cmd:"" help:"List configuration keys and their values"`
+	Get   ConfigGetCmd   `cmd:"" help:"Get configuration value"`
+	Set   ConfigSetCmd   `cmd:"" help:"Set configuration value"`
+	Unset ConfigUnsetCmd `cmd:"" help:"Remove configuration value"`
+	Edit  ConfigEditCmd  `cmd:"" help:"Edit the config file in $EDITOR"`
+}
+
+// ConfigListCmd lists every config key with its effective value.
+type ConfigListCmd struct{}
+
+func (c *ConfigListCmd) Run(ctx context.Context) error {
+	profile := pontoCtx.ProfileFrom(ctx)
+
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	entries := make([]output.ConfigEntry, 0, len(config.Keys()))
+
+	for _, k := range config.Keys() {
+		value, source := k.Effective(cfg, profile)
+
+		entries = append(entries, output.ConfigEntry{
+			Key:         k.Name,
+			Value:       value,
+			Source:      source,
+			Scope:       k.Scope.String(),
+			Description: k.Help,
+		})
+	}
+
+	return output.ConfigEntries(ctx, entries)
 }
 
 // ConfigGetCmd gets a configuration value.
 type ConfigGetCmd struct {
-	Key string `arg:"" help:"Config key (account-id)"`
+	Key    string `arg:"" help:"Config key (see 'ponto config list')"`
+	Global bool   `help:"Read the top-level value instead of the effective value for the profile"`
 }
 
 func (c *ConfigGetCmd) Run(ctx context.Context) error {
 	profile := pontoCtx.ProfileFrom(ctx)
 
+	key, err := config.LookupKey(c.Key)
+	if err != nil {
+		return err
+	}
+
 	cfg, err := config.ReadConfig()
 	if err != nil {
 		return fmt.Errorf("read config: %w", err)
 	}
 
-	p := cfg.Profiles[profile]
+	var value string
+
+	if c.Global {
+		value, err = key.Get(cfg, profile, true)
+		if err != nil {
+			return err
+		}
+	} else if v, source := key.Effective(cfg, profile); source != "default" {
+		value = v
+	}
 
-	switch c.Key {
-	case "account-id":
-		if p.AccountID == "" {
-			return fmt.Errorf("account-id not set for profile %q", profile)
+	if value == "" {
+		if key.Scope == config.ScopeProfile {
+			return fmt.Errorf("%s not set for profile %q", key.Name, profile)
 		}
 
-		fmt.Println(p.AccountID)
-	default:
-		return fmt.Errorf("unknown config key: %s", c.Key)
+		return fmt.Errorf("%s not set (default: %s)", key.Name, key.Default)
 	}
 
+	fmt.Fprintln(output.WriterFrom(ctx), value)
+
 	return nil
 }
 
 // ConfigSetCmd sets a configuration value.
 type ConfigSetCmd struct {
-	Key   string `arg:"" help:"Config key (account-id)"`
-	Value string `arg:"" help:"Value to set"`
+	Key    string `arg:"" help:"Config key (see 'ponto config list')"`
+	Value  string `arg:"" help:"Value to set"`
+	Global bool   `help:"Set the value for all profiles instead of the current profile"`
 }
 
 func (c *ConfigSetCmd) Run(ctx context.Context) error {
 	profile := pontoCtx.ProfileFrom(ctx)
 
+	key, err := config.LookupKey(c.Key)
+	if err != nil {
+		return err
+	}
+
 	cfg, err := config.ReadConfig()
 	if err != nil {
 		return fmt.Errorf("read config: %w", err)
 	}
 
-	if cfg.Profiles == nil {
-		cfg.Profiles = make(map[string]config.Profile)
+	if err := key.Set(&cfg, profile, c.Global, c.Value); err != nil {
+		return err
 	}
 
-	p := cfg.Profiles[profile]
+	if err := config.WriteConfig(cfg); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
 
-	switch c.Key {
-	case "account-id":
-		p.AccountID = c.Value
-	default:
-		return fmt.Errorf("unknown config key: %s", c.Key)
+	mode := output.ModeFrom(ctx)
+	if mode == output.ModeTable {
+		fmt.Printf("Set %s=%s %s\n", key.Name, c.Value, scopeLabel(key, profile, c.Global))
 	}
 
-	cfg.Profiles[profile] = p
+	return nil
+}
+
+// ConfigUnsetCmd removes a configuration value.
+type ConfigUnsetCmd struct {
+	Key    string `arg:"" help:"Config key (see 'ponto config list')"`
+	Global bool   `help:"Remove the top-level value instead of the current profile's"`
+}
+
+func (c *ConfigUnsetCmd) Run(ctx context.Context) error {
+	profile := pontoCtx.ProfileFrom(ctx)
+
+	key, err := config.LookupKey(c.Key)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	if err := key.Unset(&cfg, profile, c.Global); err != nil {
+		return err
+	}
 
 	if err := config.WriteConfig(cfg); err != nil {
 		return fmt.Errorf("write config: %w", err)
 	}
 
-	mode := output.ModeFrom(ctx)
-	if mode == output.ModeTable {
-		fmt.Printf("Set %s=%s for profile %q\n", c.Key, c.Value, profile)
+	if output.ModeFrom(ctx) == output.ModeTable {
+		fmt.Printf("Unset %s %s\n", key.Name, scopeLabel(key, profile, c.Global))
+	}
+
+	return nil
+}
+
+func scopeLabel(key config.Key, profile string, global bool) string {
+	if global || key.Scope == config.ScopeGlobal {
+		return "globally"
+	}
+
+	return fmt.Sprintf("for profile %q", profile)
+}
+
+// ConfigEditCmd opens the config file in an editor and validates it before
+// saving.
+type ConfigEditCmd struct{}
+
+func (c *ConfigEditCmd) Run(_ context.Context) error {
+	path, err := config.ConfigPath()
+	if err != nil {
+		return err
+	}
+
+	original, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	dir, err := config.EnsureDir()
+	if err != nil {
+		return fmt.Errorf("ensure config dir: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".config.*.yaml")
+	if err != nil {
+		return fmt.Errorf("create temp config: %w", err)
+	}
+
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(original)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("write temp config: %w", err)
+	}
+
+	for {
+		if err := runEditor(tmp.Name()); err != nil {
+			return err
+		}
+
+		edited, err := os.ReadFile(tmp.Name())
+		if err != nil {
+			return fmt.Errorf("read edited config: %w", err)
+		}
+
+		if string(edited) == string(original) {
+			fmt.Fprintln(os.Stderr, "No changes")
+
+			return nil
+		}
+
+		err = config.WriteConfigBytes(edited)
+		if err == nil {
+			fmt.Fprintf(os.Stderr, "Saved %s\n", path)
+
+			return nil
+		}
+
+		fmt.Fprintf(os.Stderr, "Invalid config: %v\n", err)
+
+		if !confirm("Edit again?", true) {
+			return errors.New("config not saved")
+		}
+	}
+}
+
+// runEditor opens path in $VISUAL or $EDITOR, which may include arguments
+// (e.g. "code --wait").
+func runEditor(path string) error {
+	editor := envOr("VISUAL", envOr("EDITOR", "vi"))
+
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		return errors.New("no editor configured; set $EDITOR")
+	}
+
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("run editor %s: %w", args[0], err)
 	}
 
 	return nil
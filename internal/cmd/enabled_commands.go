@@ -13,34 +13,37 @@ func enforceEnabledCommands(kctx *kong.Context, enabled string) error {
 		return nil
 	}
 
-	allow := parseEnabledCommands(enabled)
-	if len(allow) == 0 {
+	// Get full command path (e.g., "accounts list" -> "accounts.list")
+	cmdPath := strings.ReplaceAll(kctx.Command(), " ", ".")
+	cmdPath = strings.ToLower(cmdPath)
+
+	if commandEnabled(parseEnabledCommands(enabled), cmdPath) {
 		return nil
 	}
 
-	if allow["*"] || allow["all"] {
-		return nil
+	return &ExitError{
+		Code: 2,
+		Err:  fmt.Errorf("command %q is not enabled (allowed: %s)", cmdPath, enabled),
 	}
+}
 
-	// Get full command path (e.g., "accounts list" -> "accounts.list")
-	cmdPath := strings.ReplaceAll(kctx.Command(), " ", ".")
-	cmdPath = strings.ToLower(cmdPath)
+// commandEnabled reports whether the dotted command path is allowed: listed
+// itself or by its top-level command, or allowed by "*" or "all". An empty
+// allow-list allows everything.
+func commandEnabled(allow map[string]bool, cmdPath string) bool {
+	if len(allow) == 0 || allow["*"] || allow["all"] {
+		return true
+	}
 
 	// Check exact match first
 	if allow[cmdPath] {
-		return nil
+		return true
 	}
 
 	// Check top-level command
 	parts := strings.Split(cmdPath, ".")
-	if len(parts) > 0 && allow[parts[0]] {
-		return nil
-	}
 
-	return &ExitError{
-		Code: 2,
-		Err:  fmt.Errorf("command %q is not enabled (allowed: %s)", cmdPath, enabled),
-	}
+	return len(parts) > 0 && allow[parts[0]]
 }
 
 func parseEnabledCommands(value string) map[string]bool {
@@ -66,3 +66,27 @@ func TestParseEnabledCommands(t *testing.T) {
 		})
 	}
 }
+
+func TestCommandEnabled(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		allow   string
+		command string
+		want    bool
+	}{
+		{allow: "", command: "accounts.sync", want: true},
+		{allow: "*", command: "accounts.sync", want: true},
+		{allow: "accounts", command: "accounts.sync", want: true},
+		{allow: "accounts.list,transactions", command: "accounts.list", want: true},
+		{allow: "accounts.list,transactions", command: "transactions.list", want: true},
+		{allow: "accounts.list,transactions", command: "accounts.sync", want: false},
+		{allow: "serve,accounts.list", command: "sync.get", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := commandEnabled(parseEnabledCommands(tt.allow), tt.command); got != tt.want {
+			t.Errorf("commandEnabled(%q, %q) = %v, want %v", tt.allow, tt.command, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/dedene/ponto-cli/internal/api"
+	"github.com/dedene/ponto-cli/internal/output"
+)
+
+// exportRange is the resolved --since/--until range of an export; empty
+// bounds are open.
+type exportRange struct {
+	Since string
+	Until string
+}
+
+// exportFile is a single file produced by an --output-dir export.
+type exportFile struct {
+	Name   string
+	Sheets []output.TransactionSheet
+}
+
+func (f exportFile) transactions() []api.Transaction {
+	var txns []api.Transaction
+	for _, s := range f.Sheets {
+		txns = append(txns, s.Transactions...)
+	}
+
+	return txns
+}
+
+// mergeSheets tags the transactions of each sheet with their account and
+// merges them newest first by execution date.
+func mergeSheets(sheets []output.TransactionSheet) []output.AccountTransaction {
+	var txns []output.AccountTransaction
+
+	for _, s := range sheets {
+		for _, tx := range s.Transactions {
+			txns = append(txns, output.AccountTransaction{
+				AccountID:   s.Account.ID,
+				Account:     s.Account.Description,
+				IBAN:        s.Account.Reference,
+				Transaction: tx,
+			})
+		}
+	}
+
+	sort.SliceStable(txns, func(i, j int) bool {
+		return txns[i].ExecutionDate > txns[j].ExecutionDate
+	})
+
+	return txns
+}
+
+func resolveExportRange(since, until string) (exportRange, error) {
+	var rng exportRange
+
+	if since != "" {
+		d, err := api.ParseDate(since)
+		if err != nil {
+			return rng, fmt.Errorf("invalid since date: %w", err)
+		}
+
+		rng.Since = d
+	}
+
+	if until != "" {
+		d, err := api.ParseDate(until)
+		if err != nil {
+			return rng, fmt.Errorf("invalid until date: %w", err)
+		}
+
+		rng.Until = d
+	}
+
+	return rng, nil
+}
+
+// planExport groups sheets into files according to splitBy ("", "account"
+// or "month") and names each file from the naming template.
+func planExport(sheets []output.TransactionSheet, splitBy, nameTmpl, format string, rng exportRange) ([]exportFile, error) {
+	var groups [][]output.TransactionSheet
+
+	var months []string
+
+	switch splitBy {
+	case "account":
+		for _, s := range sheets {
+			groups = append(groups, []output.TransactionSheet{s})
+		}
+	case "month":
+		byMonth := map[string][]output.TransactionSheet{}
+
+		for _, s := range sheets {
+			for month, txns := range transactionsByMonth(s.Transactions) {
+				byMonth[month] = append(byMonth[month], output.TransactionSheet{Account: s.Account, Transactions: txns})
+			}
+		}
+
+		for month := range byMonth {
+			months = append(months, month)
+		}
+
+		sort.Strings(months)
+
+		for _, month := range months {
+			groups = append(groups, byMonth[month])
+		}
+	default:
+		groups = [][]output.TransactionSheet{sheets}
+	}
+
+	files := make([]exportFile, 0, len(groups))
+	seen := map[string]bool{}
+
+	for i, group := range groups {
+		month := ""
+		if months != nil {
+			month = months[i]
+		}
+
+		name := expandExportName(nameTmpl, exportVars(group, month, format, rng))
+		if seen[name] {
+			return nil, fmt.Errorf("naming template %q produces duplicate file name %q; include {account} or {month}", nameTmpl, name)
+		}
+
+		seen[name] = true
+
+		files = append(files, exportFile{Name: name, Sheets: group})
+	}
+
+	return files, nil
+}
+
+// transactionsByMonth buckets transactions by the YYYY-MM of their value date.
+func transactionsByMonth(txns []api.Transaction) map[string][]api.Transaction {
+	out := map[string][]api.Transaction{}
+
+	for _, tx := range txns {
+		date := tx.ValueDate
+		if date == "" {
+			date = tx.ExecutionDate
+		}
+
+		month := "unknown"
+		if len(date) >= 7 {
+			month = date[:7]
+		}
+
+		out[month] = append(out[month], tx)
+	}
+
+	return out
+}
+
+func exportVars(group []output.TransactionSheet, month, format string, rng exportRange) map[string]string {
+	vars := map[string]string{
+		"ext":   format,
+		"month": month,
+	}
+
+	switch len(group) {
+	case 1:
+		a := group[0].Account
+		vars["account"] = fileSlug(firstNonEmpty(a.Description, a.Reference, a.ID))
+		vars["account_id"] = a.ID
+		vars["iban"] = a.Reference
+	default:
+		vars["account"] = "all"
+		vars["account_id"] = "all"
+		vars["iban"] = "all"
+	}
+
+	since, until := rng.Since, rng.Until
+
+	if month != "" {
+		if t, err := time.Parse("2006-01", month); err == nil {
+			monthStart := t.Format("2006-01-02")
+			monthEnd := t.AddDate(0, 1, -1).Format("2006-01-02")
+
+			if since == "" || monthStart > since {
+				since = monthStart
+			}
+
+			if until == "" || monthEnd < until {
+				until = monthEnd
+			}
+		}
+	}
+
+	first, last := dateBounds(group)
+
+	vars["since"] = firstNonEmpty(since, first, "all")
+	vars["until"] = firstNonEmpty(until, last, "all")
+
+	return vars
+}
+
+// dateBounds returns the earliest and latest value dates in group.
+func dateBounds(group []output.TransactionSheet) (string, string) {
+	var first, last string
+
+	for _, s := range group {
+		for _, tx := range s.Transactions {
+			d := tx.ValueDate
+			if len(d) >= 10 {
+				d = d[:10]
+			}
+
+			if d == "" {
+				continue
+			}
+
+			if first == "" || d < first {
+				first = d
+			}
+
+			if d > last {
+				last = d
+			}
+		}
+	}
+
+	return first, last
+}
+
+func expandExportName(tmpl string, vars map[string]string) string {
+	pairs := make([]string, 0, len(vars)*2)
+	for k, v := range vars {
+		pairs = append(pairs, "{"+k+"}", v)
+	}
+
+	return strings.NewReplacer(pairs...).Replace(tmpl)
+}
+
+// fileSlug makes s safe to use as part of a file name.
+func fileSlug(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		default:
+			return '-'
+		}
+	}, strings.TrimSpace(s))
+
+	for strings.Contains(s, "--") {
+		s = strings.ReplaceAll(s, "--", "-")
+	}
+
+	return strings.Trim(s, "-.")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
+
+// binaryWriter returns the output destination for binary formats, refusing
+// to write to an interactive terminal.
+func binaryWriter(ctx context.Context) (io.Writer, error) {
+	w := output.WriterFrom(ctx)
+
+	if f, ok := w.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		return nil, errors.New("refusing to write binary output to a terminal; use --output or redirect stdout")
+	}
+
+	return w, nil
+}
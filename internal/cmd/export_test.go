@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dedene/ponto-cli/internal/api"
+	"github.com/dedene/ponto-cli/internal/output"
+)
+
+func TestPlanExport(t *testing.T) {
+	t.Parallel()
+
+	sheets := []output.TransactionSheet{
+		{
+			Account: api.Account{ID: "a1", Description: "Main / Ops", Reference: "BE68539007547034"},
+			Transactions: []api.Transaction{
+				{ID: "t1", ValueDate: "2024-01-15"},
+				{ID: "t2", ValueDate: "2024-02-03"},
+				{ID: "t3", ValueDate: "2024-02-20"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		splitBy string
+		tmpl    string
+		rng     exportRange
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "single file uses transaction bounds",
+			tmpl: "{account}_{since}_{until}.{ext}",
+			want: []string{"Main-Ops_2024-01-15_2024-02-20.csv"},
+		},
+		{
+			name: "single file uses requested range",
+			tmpl: "{iban}_{since}_{until}.{ext}",
+			rng:  exportRange{Since: "2024-01-01", Until: "2024-03-31"},
+			want: []string{"BE68539007547034_2024-01-01_2024-03-31.csv"},
+		},
+		{
+			name:    "split by month clips to month bounds",
+			splitBy: "month",
+			tmpl:    "{account}_{since}_{until}.{ext}",
+			rng:     exportRange{Since: "2024-01-10"},
+			want:    []string{"Main-Ops_2024-01-10_2024-01-31.csv", "Main-Ops_2024-02-01_2024-02-29.csv"},
+		},
+		{
+			name:    "duplicate names rejected",
+			splitBy: "month",
+			tmpl:    "{account}.{ext}",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			files, err := planExport(sheets, tt.splitBy, tt.tmpl, "csv", tt.rng)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("planExport() expected error, got nil")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("planExport() unexpected error: %v", err)
+			}
+
+			got := make([]string, len(files))
+			for i, f := range files {
+				got[i] = f.Name
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("planExport() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeSheets(t *testing.T) {
+	t.Parallel()
+
+	sheets := []output.TransactionSheet{
+		{
+			Account: api.Account{ID: "a1", Description: "Main", Reference: "BE68539007547034"},
+			Transactions: []api.Transaction{
+				{ID: "t1", ExecutionDate: "2024-02-20T10:00:00.000Z"},
+				{ID: "t2", ExecutionDate: "2024-01-15T10:00:00.000Z"},
+			},
+		},
+		{
+			Account: api.Account{ID: "a2", Description: "Savings", Reference: "BE71096123456769"},
+			Transactions: []api.Transaction{
+				{ID: "t3", ExecutionDate: "2024-02-03T10:00:00.000Z"},
+			},
+		},
+	}
+
+	txns := mergeSheets(sheets)
+
+	got := make([]string, len(txns))
+	for i, tx := range txns {
+		got[i] = tx.ID + "@" + tx.IBAN
+	}
+
+	want := []string{"t1@BE68539007547034", "t3@BE71096123456769", "t2@BE68539007547034"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeSheets() = %v, want %v", got, want)
+	}
+
+	if txns[1].Account != "Savings" || txns[1].AccountID != "a2" {
+		t.Errorf("mergeSheets()[1] account = %q (%s), want Savings (a2)", txns[1].Account, txns[1].AccountID)
+	}
+}
+
+func TestWriteExportFileMultiAccountLayout(t *testing.T) {
+	t.Parallel()
+
+	sheets := []output.TransactionSheet{
+		{
+			Account: api.Account{ID: "a1", Description: "Main", Reference: "BE68539007547034"},
+			Transactions: []api.Transaction{
+				{ID: "t1", ValueDate: "2024-01-15", ExecutionDate: "2024-01-15T10:00:00.000Z"},
+				{ID: "t2", ValueDate: "2024-02-03", ExecutionDate: "2024-02-03T10:00:00.000Z"},
+			},
+		},
+		{
+			Account: api.Account{ID: "a2", Description: "Savings", Reference: "BE71096123456769"},
+			Transactions: []api.Transaction{
+				{ID: "t3", ValueDate: "2024-02-20", ExecutionDate: "2024-02-20T10:00:00.000Z"},
+			},
+		},
+	}
+
+	c := &TransactionsExportCmd{OutputDir: t.TempDir(), Format: "csv", SplitBy: "month"}
+
+	files, err := planExport(sheets, c.SplitBy, "{month}.{ext}", c.Format, exportRange{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) != 2 {
+		t.Fatalf("planExport() planned %d files, want 2", len(files))
+	}
+
+	var headers []string
+
+	for _, file := range files {
+		if err := c.writeExportFile(context.Background(), file, true); err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := os.ReadFile(filepath.Join(c.OutputDir, file.Name))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		header, _, _ := strings.Cut(string(data), "\n")
+		headers = append(headers, header)
+	}
+
+	// January only holds a1's transaction, but keeps the account columns
+	if headers[0] != headers[1] {
+		t.Errorf("%s header = %q, %s header = %q, want the same", files[0].Name, headers[0], files[1].Name, headers[1])
+	}
+
+	if !strings.Contains(headers[0], ",account,iban,") {
+		t.Errorf("%s header = %q, want account columns", files[0].Name, headers[0])
+	}
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/alecthomas/kong"
+
+	"github.com/dedene/ponto-cli/internal/config"
+	"github.com/dedene/ponto-cli/internal/output"
+)
+
+// fanoutParallelism bounds the number of profiles queried at once.
+const fanoutParallelism = 4
+
+// fanoutCommands are the list commands that can run for several profiles.
+var fanoutCommands = map[string]bool{
+	"accounts list":             true,
+	"transactions list":         true,
+	"transactions export":       true,
+	"pending-transactions list": true,
+	"sync list":                 true,
+}
+
+// fanoutChecker is implemented by commands whose flags only partially
+// support multi-profile runs.
+type fanoutChecker interface {
+	checkFanout() error
+}
+
+type runner interface {
+	Run(ctx context.Context) error
+}
+
+// fanoutProfiles resolves --profiles and --all-profiles; it returns nil when
+// the command runs for a single profile.
+func fanoutProfiles(flags *RootFlags) ([]string, error) {
+	if flags.AllProfiles {
+		if flags.Profiles != "" {
+			return nil, errors.New("--profiles and --all-profiles are mutually exclusive")
+		}
+
+		cfg, err := config.ReadConfig()
+		if err != nil {
+			return nil, fmt.Errorf("read config: %w", err)
+		}
+
+		names := profileNames(cfg)
+		if len(names) == 0 {
+			return nil, errors.New("no profiles configured; run 'ponto profiles add <name>'")
+		}
+
+		return names, nil
+	}
+
+	if flags.Profiles == "" {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool)
+
+	var names []string
+
+	for _, name := range strings.Split(flags.Profiles, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+
+		seen[name] = true
+		names = append(names, name)
+	}
+
+	if len(names) == 0 {
+		return nil, errors.New("--profiles needs at least one profile name")
+	}
+
+	return names, nil
+}
+
+// runFanout runs the selected command once per profile, concurrently, and
+// writes the merged output. Failing profiles are reported on stderr and do
+// not stop the others.
+func runFanout(ctx context.Context, kctx *kong.Context, profiles []string) error {
+	if !fanoutCommands[kctx.Command()] {
+		supported := make([]string, 0, len(fanoutCommands))
+		for name := range fanoutCommands {
+			supported = append(supported, name)
+		}
+
+		sort.Strings(supported)
+
+		return &ExitError{Code: 2, Err: fmt.Errorf("%q does not support --profiles (supported: %s)",
+			kctx.Command(), strings.Join(supported, ", "))}
+	}
+
+	cmd, ok := kctx.Selected().Target.Addr().Interface().(runner)
+	if !ok {
+		return fmt.Errorf("%q cannot be run for several profiles", kctx.Command())
+	}
+
+	if c, ok := cmd.(fanoutChecker); ok {
+		if err := c.checkFanout(); err != nil {
+			return &ExitError{Code: 2, Err: err}
+		}
+	}
+
+	fan := output.NewFanout(profiles)
+	errs := make([]error, len(profiles))
+	sem := make(chan struct{}, fanoutParallelism)
+
+	var wg sync.WaitGroup
+
+	for i, profile := range profiles {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			errs[i] = cmd.Run(fan.WithProfile(withProfile(ctx, profile), profile))
+		}()
+	}
+
+	wg.Wait()
+
+	if err := fan.Render(ctx); err != nil {
+		return err
+	}
+
+	failed := 0
+
+	for i, err := range errs {
+		if err != nil {
+			failed++
+
+			fmt.Fprintf(os.Stderr, "profile %s: %v\n", profiles[i], err)
+		}
+	}
+
+	if failed > 0 {
+		return &ExitError{Code: 1, Err: fmt.Errorf("%d of %d profiles failed", failed, len(profiles))}
+	}
+
+	return nil
+}
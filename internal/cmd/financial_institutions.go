@@ -28,9 +28,7 @@ func (c *FinancialInstitutionsListCmd) Run(ctx context.Context) error {
 		return fmt.Errorf("list financial institutions: %w", err)
 	}
 
-	mode := output.ModeFrom(ctx)
-
-	return output.FinancialInstitutions(mode, institutions)
+	return output.FinancialInstitutions(ctx, institutions)
 }
 
 // FinancialInstitutionsGetCmd gets financial institution details.
@@ -49,7 +47,5 @@ func (c *FinancialInstitutionsGetCmd) Run(ctx context.Context) error {
 		return fmt.Errorf("get financial institution: %w", err)
 	}
 
-	mode := output.ModeFrom(ctx)
-
-	return output.FinancialInstitution(mode, institution)
+	return output.FinancialInstitution(ctx, institution)
 }
@@ -97,7 +97,7 @@ func helpColorMode(args []string) string {
 
 	for i := range len(args) {
 		a := args[i]
-		if a == "--plain" || a == "--json" || a == "--csv" {
+		if a == "--plain" || a == "--json" || a == "--ndjson" || a == "--csv" {
 			return colorNever
 		}
 
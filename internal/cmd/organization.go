@@ -27,7 +27,5 @@ func (c *OrganizationShowCmd) Run(ctx context.Context) error {
 		return fmt.Errorf("get organization: %w", err)
 	}
 
-	mode := output.ModeFrom(ctx)
-
-	return output.Organization(mode, org)
+	return output.Organization(ctx, org)
 }
@@ -15,7 +15,7 @@ type PendingTransactionsCmd struct {
 
 // PendingTransactionsListCmd lists pending transactions.
 type PendingTransactionsListCmd struct {
-	AccountID string `help:"Account ID (default: from config or auto-detect)" name:"account-id"`
+	AccountID string `help:"Account ID, alias, IBAN (suffix) or name (default: from config or auto-detect)" name:"account-id" aliases:"account"`
 }
 
 func (c *PendingTransactionsListCmd) Run(ctx context.Context) error {
@@ -34,7 +34,5 @@ func (c *PendingTransactionsListCmd) Run(ctx context.Context) error {
 		return fmt.Errorf("list pending transactions: %w", err)
 	}
 
-	mode := output.ModeFrom(ctx)
-
-	return output.PendingTransactions(mode, transactions)
+	return output.PendingTransactions(ctx, transactions)
 }
@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/dedene/ponto-cli/internal/auth"
+	"github.com/dedene/ponto-cli/internal/config"
+	"github.com/dedene/ponto-cli/internal/output"
+)
+
+// ProfilesCmd is the parent command for profile management.
+type ProfilesCmd struct {
+	List   ProfilesListCmd   `cmd:"" help:"List profiles"`
+	Add    ProfilesAddCmd    `cmd:"" help:"Add a profile"`
+	Remove ProfilesRemoveCmd `cmd:"" help:"Remove a profile and its credentials"`
+	Use    ProfilesUseCmd    `cmd:"" help:"Set the default profile"`
+	Copy   ProfilesCopyCmd   `cmd:"" help:"Copy a profile and its credentials"`
+	Rename ProfilesRenameCmd `cmd:"" help:"Rename a profile and move its credentials"`
+}
+
+// ProfilesListCmd lists profiles.
+type ProfilesListCmd struct{}
+
+func (c *ProfilesListCmd) Run(ctx context.Context) error {
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	store, err := auth.OpenStore()
+	if err != nil {
+		slog.Warn("credential store unavailable, authentication status unknown", "error", err)
+	}
+
+	defaultName := defaultProfile(cfg)
+
+	names := profileNames(cfg)
+	if _, ok := cfg.Profiles[defaultName]; !ok {
+		names = append(names, defaultName)
+		sort.Strings(names)
+	}
+
+	profiles := make([]output.ProfileInfo, 0, len(names))
+
+	for _, name := range names {
+		p := cfg.Profiles[name]
+		info := output.ProfileInfo{
+			Name:      name,
+			Default:   name == defaultName,
+			Connect:   p.Connect != nil,
+			AccountID: p.AccountID,
+		}
+
+		if store != nil {
+			_, _, err := store.GetCredentials(name)
+			info.Authenticated = err == nil
+		}
+
+		profiles = append(profiles, info)
+	}
+
+	return output.Profiles(ctx, profiles)
+}
+
+// ProfilesAddCmd adds a profile.
+type ProfilesAddCmd struct {
+	Name      string `arg:"" help:"Profile name"`
+	AccountID string `help:"Default account for the profile"`
+}
+
+func (c *ProfilesAddCmd) Run(ctx context.Context) error {
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	if _, ok := cfg.Profiles[c.Name]; ok {
+		return fmt.Errorf("profile %q already exists", c.Name)
+	}
+
+	if cfg.Profiles == nil {
+		cfg.Profiles = make(map[string]config.Profile)
+	}
+
+	cfg.Profiles[c.Name] = config.Profile{AccountID: c.AccountID}
+
+	if err := config.WriteConfig(cfg); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+
+	fmt.Printf("Profile %q added\n", c.Name)
+	fmt.Printf("Run 'ponto --profile=%s auth login' to store its credentials.\n", c.Name)
+
+	return nil
+}
+
+// ProfilesRemoveCmd removes a profile, its credentials and cached tokens.
+type ProfilesRemoveCmd struct {
+	Name string `arg:"" help:"Profile name"`
+}
+
+func (c *ProfilesRemoveCmd) Run(ctx context.Context) error {
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	// The default profile is listed even without a config entry, so it can
+	// be removed too.
+	if _, ok := cfg.Profiles[c.Name]; !ok && c.Name != defaultProfile(cfg) {
+		return fmt.Errorf("unknown profile %q", c.Name)
+	}
+
+	// Secrets go first: if that fails the profile stays in the config, so
+	// its credentials remain reachable and the removal can be retried.
+	if err := deleteProfileSecrets(c.Name); err != nil {
+		return err
+	}
+
+	delete(cfg.Profiles, c.Name)
+
+	if cfg.DefaultProfile == c.Name {
+		cfg.DefaultProfile = ""
+	}
+
+	if err := config.WriteConfig(cfg); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+
+	fmt.Printf("Profile %q removed\n", c.Name)
+
+	return nil
+}
+
+// ProfilesUseCmd sets the default profile.
+type ProfilesUseCmd struct {
+	Name string `arg:"" help:"Profile name"`
+}
+
+func (c *ProfilesUseCmd) Run(ctx context.Context) error {
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	if _, ok := cfg.Profiles[c.Name]; !ok {
+		return fmt.Errorf("unknown profile %q (run 'ponto profiles add %s' first)", c.Name, c.Name)
+	}
+
+	cfg.DefaultProfile = c.Name
+
+	if err := config.WriteConfig(cfg); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+
+	fmt.Printf("Default profile set to %q\n", c.Name)
+
+	return nil
+}
+
+// ProfilesCopyCmd copies a profile.
+type ProfilesCopyCmd struct {
+	Source string `arg:"" help:"Profile to copy"`
+	Target string `arg:"" help:"New profile name"`
+}
+
+func (c *ProfilesCopyCmd) Run(ctx context.Context) error {
+	if err := copyProfile(c.Source, c.Target, false); err != nil {
+		return err
+	}
+
+	fmt.Printf("Profile %q copied to %q\n", c.Source, c.Target)
+
+	return nil
+}
+
+// ProfilesRenameCmd renames a profile.
+type ProfilesRenameCmd struct {
+	Source string `arg:"" help:"Profile to rename"`
+	Target string `arg:"" help:"New profile name"`
+}
+
+func (c *ProfilesRenameCmd) Run(ctx context.Context) error {
+	if err := copyProfile(c.Source, c.Target, true); err != nil {
+		return err
+	}
+
+	fmt.Printf("Profile %q renamed to %q\n", c.Source, c.Target)
+
+	return nil
+}
+
+// copyProfile copies the config entry and credentials of src to dst,
+// removing src afterwards when move is set.
+func copyProfile(src, dst string, move bool) error {
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	if _, ok := cfg.Profiles[dst]; ok {
+		return fmt.Errorf("profile %q already exists", dst)
+	}
+
+	store, err := auth.OpenStore()
+	if err != nil {
+		return fmt.Errorf("open credential store: %w", err)
+	}
+
+	p, inConfig := cfg.Profiles[src]
+
+	// A profile may have credentials without a config entry (e.g. "default").
+	// Anything but missing credentials stops here, before the config
+	// changes and before a rename deletes the source's credentials.
+	if err := auth.CopyProfile(store, src, dst); err != nil {
+		if !auth.IsNotFound(err) {
+			return fmt.Errorf("copy credentials of %q: %w", src, err)
+		}
+
+		if !inConfig {
+			return fmt.Errorf("unknown profile %q", src)
+		}
+
+		slog.Debug("profile has no stored credentials", "profile", src, "error", err)
+	}
+
+	if cfg.Profiles == nil {
+		cfg.Profiles = make(map[string]config.Profile)
+	}
+
+	cfg.Profiles[dst] = p
+
+	if move {
+		delete(cfg.Profiles, src)
+
+		if cfg.DefaultProfile == src {
+			cfg.DefaultProfile = dst
+		}
+	}
+
+	if err := config.WriteConfig(cfg); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+
+	if move {
+		return deleteProfileSecrets(src)
+	}
+
+	return nil
+}
+
+// deleteProfileSecrets removes a profile's credentials and cached tokens.
+func deleteProfileSecrets(profile string) error {
+	store, err := auth.OpenStore()
+	if err != nil {
+		return fmt.Errorf("open credential store: %w", err)
+	}
+
+	if err := store.DeleteCredentials(profile); err != nil {
+		return fmt.Errorf("delete credentials: %w", err)
+	}
+
+	cache, err := auth.OpenTokenCache()
+	if err != nil {
+		return fmt.Errorf("open token cache: %w", err)
+	}
+
+	if err := cache.DeleteProfile(profile); err != nil {
+		return fmt.Errorf("clear token cache: %w", err)
+	}
+
+	return nil
+}
+
+func profileNames(cfg config.File) []string {
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
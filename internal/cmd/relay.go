@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/dedene/ponto-cli/internal/api"
+	pontoCtx "github.com/dedene/ponto-cli/internal/ctx"
+	"github.com/dedene/ponto-cli/internal/relay"
+	"github.com/dedene/ponto-cli/internal/watch"
+)
+
+// RelayCmd delivers new transactions and sync outcomes to a webhook.
+type RelayCmd struct {
+	WebhookURL   string        `name:"webhook-url" required:"" help:"URL to POST events to"`
+	Secret       string        `required:"" env:"PONTO_RELAY_SECRET" help:"Shared secret for the HMAC-SHA256 signature header"`
+	AccountIDs   []string      `help:"Account to relay: ID, alias, IBAN (suffix) or name (repeatable; default: all accounts)" name:"account-id" aliases:"account" sep:"none"`
+	Interval     time.Duration `help:"Time between polls" default:"5m"`
+	NoSync       bool          `name:"no-sync" help:"Don't synchronize accounts, only relay what Ponto already has"`
+	SyncCooldown time.Duration `name:"sync-cooldown" help:"Minimum time between synchronizations of an account" default:"30m"`
+	MaxAttempts  int           `name:"max-attempts" help:"Deliveries failing this often move to the failed queue" default:"10"`
+	Once         bool          `help:"Poll and deliver once, then exit; failed deliveries stay queued"`
+}
+
+func (c *RelayCmd) Run(ctx context.Context) error {
+	if c.Interval < minWatchInterval && !c.Once {
+		return fmt.Errorf("--interval must be at least %s", minWatchInterval)
+	}
+
+	if u, err := url.Parse(c.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("--webhook-url %q is not an http(s) URL", c.WebhookURL)
+	}
+
+	var ids []string
+
+	if len(c.AccountIDs) > 0 {
+		var err error
+
+		if ids, err = resolveAccountIDs(ctx, c.AccountIDs, false); err != nil {
+			return err
+		}
+	}
+
+	client, err := api.NewClientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	profile := pontoCtx.ProfileFrom(ctx)
+
+	path, err := watch.StatePath("relay", profile)
+	if err != nil {
+		return err
+	}
+
+	state, err := watch.LoadState(path)
+	if err != nil {
+		return err
+	}
+
+	queue, err := relay.OpenQueue(strings.TrimSuffix(path, ".json")+"-queue", c.MaxAttempts)
+	if err != nil {
+		return err
+	}
+
+	sender := &relay.Sender{
+		URL:    c.WebhookURL,
+		Secret: c.Secret,
+		Client: &http.Client{Timeout: pontoCtx.TimeoutFrom(ctx)},
+	}
+
+	w := &watch.Watcher{
+		Source:       client,
+		State:        state,
+		Profile:      profile,
+		AccountIDs:   ids,
+		Sync:         !c.NoSync,
+		SyncCooldown: c.SyncCooldown,
+		OnSync: func(r watch.SyncResult) {
+			if err := queue.Enqueue("synchronization", r); err != nil {
+				slog.Warn("queue sync outcome", "account", r.AccountID, "error", err)
+			}
+		},
+	}
+
+	enqueue := func(_ context.Context, e watch.Event) error {
+		return queue.Enqueue(e.Type, e)
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	slog.Info("relaying new transactions", "profile", profile, "url", c.WebhookURL, "interval", c.Interval)
+
+	nextPoll := time.Now()
+
+	for {
+		var pollErr error
+
+		if !time.Now().Before(nextPoll) {
+			pollErr = pollOnce(ctx, w, enqueue)
+			nextPoll = time.Now().Add(c.Interval)
+		}
+
+		retry, err := queue.Flush(ctx, sender.Send)
+
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil:
+			return err
+		case c.Once:
+			return pollErr
+		case pollErr != nil:
+			slog.Warn("poll failed", "error", pollErr)
+		}
+
+		wake := nextPoll
+		if !retry.IsZero() && retry.Before(wake) {
+			wake = retry
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(time.Until(wake)):
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/kong"
+
+	"github.com/dedene/ponto-cli/internal/config"
+)
+
+// outputModeFlags are the global flags that select the output mode.
+var outputModeFlags = []string{"json", "ndjson", "csv", "plain", "template", "jq"}
+
+// configResolver supplies defaults for global flags from the config file.
+// Precedence is flag > environment > profile settings > global settings.
+func configResolver(cfg config.File) kong.Resolver {
+	return kong.ResolverFunc(func(kctx *kong.Context, _ *kong.Path, flag *kong.Flag) (any, error) {
+		// Kong consults resolvers for every flag not given on the command
+		// line, including flags set from the environment.
+		for _, env := range flag.Envs {
+			if _, ok := os.LookupEnv(env); ok {
+				return nil, nil
+			}
+		}
+
+		settings := cfg.SettingsFor(resolvedProfile(kctx))
+
+		switch flag.Name {
+		case "timeout":
+			if settings.Timeout > 0 {
+				return settings.Timeout.String(), nil
+			}
+		case "no-retry":
+			if settings.NoRetry != nil {
+				return strconv.FormatBool(*settings.NoRetry), nil
+			}
+		case "enable-commands":
+			if settings.EnableCommands != "" {
+				return settings.EnableCommands, nil
+			}
+		case "columns":
+			group, _, _ := strings.Cut(kctx.Command(), " ")
+			if v := settings.Columns.For(group); v != "" {
+				return v, nil
+			}
+		case "json", "ndjson", "csv", "plain":
+			if settings.Output == flag.Name && !outputModeChosen(kctx) {
+				return "true", nil
+			}
+		}
+
+		return nil, nil
+	})
+}
+
+// resolvedProfile returns the profile selected by --profile/--sandbox, or
+// their defaults.
+func resolvedProfile(kctx *kong.Context) string {
+	profile := "default"
+
+	for _, f := range kctx.Flags() {
+		switch f.Name {
+		case "sandbox":
+			if v, _ := kctx.FlagValue(f).(bool); v {
+				return "sandbox"
+			}
+		case "profile":
+			if v, _ := kctx.FlagValue(f).(string); v != "" {
+				profile = v
+			}
+		}
+	}
+
+	return profile
+}
+
+// outputModeChosen reports whether an output mode flag was given, so the
+// configured mode does not override it.
+func outputModeChosen(kctx *kong.Context) bool {
+	for _, f := range kctx.Flags() {
+		for _, name := range outputModeFlags {
+			if f.Name != name {
+				continue
+			}
+
+			switch v := kctx.FlagValue(f).(type) {
+			case bool:
+				if v {
+					return true
+				}
+			case string:
+				if v != "" {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dedene/ponto-cli/internal/config"
+)
+
+func TestConfigResolver(t *testing.T) {
+	noRetry := true
+
+	cfg := config.File{
+		Settings: config.Settings{
+			Timeout: time.Minute,
+			Output:  "csv",
+			NoRetry: &noRetry,
+
+			EnableCommands: "auth.status",
+		},
+		Profiles: map[string]config.Profile{
+			"acme": {Settings: config.Settings{
+				Timeout: 2 * time.Minute,
+				Output:  "json",
+				Columns: config.ColumnDefaults{Transactions: "id,amount"},
+			}},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		env     string
+		timeout time.Duration
+		json    bool
+		csv     bool
+		columns string
+		enabled string
+	}{
+		{
+			name:    "global settings",
+			args:    []string{"transactions", "list"},
+			timeout: time.Minute,
+			csv:     true,
+			enabled: "auth.status",
+		},
+		{
+			name:    "profile overrides global",
+			args:    []string{"--profile=acme", "transactions", "list"},
+			timeout: 2 * time.Minute,
+			json:    true,
+			columns: "id,amount",
+			enabled: "auth.status",
+		},
+		{
+			name:    "columns only for their command group",
+			args:    []string{"--profile=acme", "accounts", "list"},
+			timeout: 2 * time.Minute,
+			json:    true,
+			enabled: "auth.status",
+		},
+		{
+			name:    "flags override config",
+			args:    []string{"--profile=acme", "--timeout=5s", "--plain", "--columns=id", "transactions", "list"},
+			timeout: 5 * time.Second,
+			columns: "id",
+			enabled: "auth.status",
+		},
+		{
+			name:    "env overrides config",
+			args:    []string{"accounts", "list"},
+			env:     "accounts.list",
+			timeout: time.Minute,
+			csv:     true,
+			enabled: "accounts.list",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PONTO_ENABLE_COMMANDS", tt.env)
+
+			if tt.env == "" {
+				os.Unsetenv("PONTO_ENABLE_COMMANDS")
+			}
+
+			parser, cli, err := buildParser(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := parser.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			if cli.Timeout != tt.timeout {
+				t.Errorf("Timeout = %s, want %s", cli.Timeout, tt.timeout)
+			}
+
+			if cli.JSON != tt.json || cli.CSV != tt.csv {
+				t.Errorf("JSON, CSV = %v, %v; want %v, %v", cli.JSON, cli.CSV, tt.json, tt.csv)
+			}
+
+			if cli.Columns != tt.columns {
+				t.Errorf("Columns = %q, want %q", cli.Columns, tt.columns)
+			}
+
+			if !cli.NoRetry {
+				t.Error("NoRetry = false, want true from global settings")
+			}
+
+			if cli.EnableCommands != tt.enabled {
+				t.Errorf("EnableCommands = %q, want %q", cli.EnableCommands, tt.enabled)
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/alecthomas/kong"
 
+	"github.com/dedene/ponto-cli/internal/auth"
 	"github.com/dedene/ponto-cli/internal/config"
 	pontoCtx "github.com/dedene/ponto-cli/internal/ctx"
 	"github.com/dedene/ponto-cli/internal/output"
@@ -19,10 +20,18 @@ import (
 type RootFlags struct {
 	Profile        string        `help:"Profile name" default:"${profile}" env:"PONTO_PROFILE"`
 	Sandbox        bool          `help:"Use sandbox profile" default:"false"`
+	Profiles       string        `help:"Run a list command for several profiles (comma-separated) and merge the results"`
+	AllProfiles    bool          `name:"all-profiles" help:"Run a list command for every configured profile"`
 	EnableCommands string        `help:"Comma-separated list of enabled commands" default:"${enabled_commands}" env:"PONTO_ENABLE_COMMANDS"`
 	JSON           bool          `help:"Output JSON"`
+	NDJSON         bool          `name:"ndjson" help:"Output newline-delimited JSON (one object per line)"`
 	CSV            bool          `help:"Output CSV"`
 	Plain          bool          `help:"Output TSV (stable for scripting)"`
+	Template       string        `help:"Render output with a Go template (helpers: money, date, pad, truncate)"`
+	JQ             string        `name:"jq" help:"Filter JSON output with a jq expression"`
+	Output         string        `short:"o" help:"Write output to a file instead of stdout (written atomically)" type:"path"`
+	Columns        string        `help:"Comma-separated columns for list output (e.g. id,valueDate,amount)"`
+	Sort           string        `help:"Sort list output by column; prefix with - for descending, as in --sort=-amount"`
 	Verbose        int           `short:"v" type:"counter" help:"Verbosity (-v, -vv)"`
 	Timeout        time.Duration `help:"Request timeout" default:"30s"`
 	NoRetry        bool          `help:"Disable retry on errors"`
@@ -44,8 +53,15 @@ type CLI struct {
 	PendingTransactions   PendingTransactionsCmd   `cmd:"" name:"pending-transactions" help:"Pending transactions"`
 	FinancialInstitutions FinancialInstitutionsCmd `cmd:"" name:"financial-institutions" help:"Financial institutions"`
 
+	Tui      TuiCmd      `cmd:"" name:"tui" help:"Interactive dashboard of accounts, transactions and syncs"`
+	Watch    WatchCmd    `cmd:"" help:"Report new transactions as they arrive"`
+	Relay    RelayCmd    `cmd:"" help:"Deliver new transactions and sync outcomes to a webhook"`
+	Webhooks WebhooksCmd `cmd:"" help:"Receive Ponto webhooks"`
+	Serve    ServeCmd    `cmd:"" help:"Serve a local HTTP API for accounts and transactions"`
+
 	Completion CompletionCmd `cmd:"" help:"Generate shell completions"`
 	Config     ConfigCmd     `cmd:"" help:"Configuration"`
+	Profiles   ProfilesCmd   `cmd:"" help:"Manage profiles"`
 }
 
 type exitPanic struct{ code int }
@@ -112,6 +128,12 @@ func Execute(args []string) (err error) {
 	mode := output.ModeTable
 
 	switch {
+	case cli.Template != "":
+		mode = output.ModeTemplate
+	case cli.JQ != "":
+		mode = output.ModeJQ
+	case cli.NDJSON:
+		mode = output.ModeNDJSON
 	case cli.JSON:
 		mode = output.ModeJSON
 	case cli.CSV:
@@ -120,6 +142,19 @@ func Execute(args []string) (err error) {
 		mode = output.ModePlain
 	}
 
+	if err = validateOutputFlags(&cli.RootFlags); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+
+		return &ExitError{Code: 2, Err: err}
+	}
+
+	fanout, err := fanoutProfiles(&cli.RootFlags)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+
+		return &ExitError{Code: 2, Err: err}
+	}
+
 	// Resolve profile
 	profile := cli.Profile
 	if cli.Sandbox {
@@ -133,19 +168,74 @@ func Execute(args []string) (err error) {
 	// Build context
 	ctx := context.Background()
 	ctx = output.WithMode(ctx, mode)
-	ctx = pontoCtx.WithProfile(ctx, profile)
+	ctx = output.WithColumns(ctx, output.ParseColumns(cli.Columns))
+	ctx = output.WithSort(ctx, cli.Sort)
+	ctx = output.WithTemplate(ctx, cli.Template)
+	ctx = output.WithJQ(ctx, cli.JQ)
+	ctx = withProfile(ctx, profile)
 	ctx = pontoCtx.WithTimeout(ctx, cli.Timeout)
 	ctx = pontoCtx.WithNoRetry(ctx, cli.NoRetry)
 
+	var out *output.AtomicFile
+
+	if cli.Output != "" {
+		out, err = output.CreateAtomic(cli.Output)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+
+			return err
+		}
+
+		defer out.Abort()
+
+		ctx = output.WithWriter(ctx, out)
+	}
+
 	kctx.BindTo(ctx, (*context.Context)(nil))
 	kctx.Bind(&cli.RootFlags)
 
-	if err = kctx.Run(); err != nil {
+	if fanout != nil {
+		err = runFanout(ctx, kctx, fanout)
+	} else {
+		err = kctx.Run()
+	}
+
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 
 		return err
 	}
 
+	if out != nil {
+		if err = out.Commit(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateOutputFlags rejects conflicting output flags and checks templates
+// and jq expressions before any API call is made.
+func validateOutputFlags(flags *RootFlags) error {
+	if flags.Template != "" && flags.JQ != "" {
+		return errors.New("--template and --jq are mutually exclusive")
+	}
+
+	if flags.Template != "" {
+		if _, err := output.ParseTemplate(flags.Template); err != nil {
+			return err
+		}
+	}
+
+	if flags.JQ != "" {
+		if _, err := output.ParseJQ(flags.JQ); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -162,6 +252,26 @@ func wrapParseError(err error) error {
 	return err
 }
 
+// withProfile selects profile in ctx, along with its configured API base URL.
+func withProfile(ctx context.Context, profile string) context.Context {
+	var baseURL string
+
+	if cfg, err := config.ReadConfig(); err == nil {
+		baseURL = cfg.SettingsFor(profile).BaseURL
+	}
+
+	return pontoCtx.WithBaseURL(pontoCtx.WithProfile(ctx, profile), baseURL)
+}
+
+// defaultProfile returns the configured default profile, or "default".
+func defaultProfile(cfg config.File) string {
+	if cfg.DefaultProfile == "" {
+		return "default"
+	}
+
+	return cfg.DefaultProfile
+}
+
 func envOr(key, fallback string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
@@ -171,10 +281,20 @@ func envOr(key, fallback string) string {
 }
 
 func newParser() (*kong.Kong, *CLI, error) {
+	// An unreadable config is reported by the commands that need it.
+	cfg, _ := config.ReadConfig()
+
+	return buildParser(cfg)
+}
+
+// buildParser creates the parser, taking flag defaults from cfg.
+func buildParser(cfg config.File) (*kong.Kong, *CLI, error) {
 	vars := kong.Vars{
-		"profile":          envOr("PONTO_PROFILE", "default"),
-		"enabled_commands": envOr("PONTO_ENABLE_COMMANDS", ""),
-		"version":          VersionString(),
+		"profile":              envOr("PONTO_PROFILE", defaultProfile(cfg)),
+		"enabled_commands":     envOr("PONTO_ENABLE_COMMANDS", ""),
+		"version":              VersionString(),
+		"connect_redirect_uri": auth.DefaultConnectRedirectURI,
+		"connect_scope":        auth.DefaultConnectScope,
 	}
 
 	cli := &CLI{}
@@ -186,6 +306,7 @@ func newParser() (*kong.Kong, *CLI, error) {
 		kong.ConfigureHelp(helpOptions()),
 		kong.Help(helpPrinter),
 		kong.Vars(vars),
+		kong.Resolvers(configResolver(cfg)),
 		kong.Writers(os.Stdout, os.Stderr),
 		kong.Exit(func(code int) { panic(exitPanic{code: code}) }),
 	)
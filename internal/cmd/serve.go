@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/dedene/ponto-cli/internal/api"
+	"github.com/dedene/ponto-cli/internal/server"
+)
+
+// ServeCmd serves a local HTTP API over the profile's Ponto client.
+type ServeCmd struct {
+	Listen   string `help:"Address to listen on" default:"127.0.0.1:9000"`
+	Token    string `env:"PONTO_SERVE_TOKEN" help:"Require this bearer token on every request"`
+	ReadOnly bool   `name:"read-only" help:"Only serve GET endpoints, refusing POST /accounts/{id}/synchronizations; --enable-commands=serve,accounts.list,accounts.get,transactions.list,pending-transactions.list,sync.get does the same"`
+}
+
+func (c *ServeCmd) Run(ctx context.Context, flags *RootFlags) error {
+	client, err := api.NewClientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	allow := parseEnabledCommands(flags.EnableCommands)
+	p := profileConfig(ctx)
+
+	s := &server.Server{
+		Source:   client,
+		Token:    c.Token,
+		ReadOnly: c.ReadOnly,
+		Allowed:  func(command string) bool { return commandEnabled(allow, command) },
+		ResolveAccount: func(ctx context.Context, ref string) (string, error) {
+			return resolveAccountRefWith(ctx, p, ref, client.ListAccounts)
+		},
+	}
+
+	if c.Token == "" && !loopback(c.Listen) {
+		slog.Warn("serving without --token on a non-loopback address", "listen", c.Listen)
+	}
+
+	srv := &http.Server{
+		Addr:              c.Listen,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		_ = srv.Shutdown(shutdown)
+	}()
+
+	fmt.Fprintf(os.Stderr, "Serving the Ponto API on http://%s\n", c.Listen)
+
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serve: %w", err)
+	}
+
+	return nil
+}
+
+// loopback reports whether addr only listens on the local machine.
+func loopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
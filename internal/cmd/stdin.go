@@ -2,8 +2,11 @@ package cmd
 
 import (
 	"bufio"
+	"fmt"
 	"os"
 	"strings"
+
+	"golang.org/x/term"
 )
 
 // ReadStdinIDs reads IDs from stdin when "-" is provided.
@@ -34,3 +37,29 @@ func ReadStdinIDs(arg string) ([]string, error) {
 func IsStdin(arg string) bool {
 	return arg == "-"
 }
+
+// confirm asks a yes/no question on stderr and returns def for an empty
+// answer; without a terminal it declines.
+func confirm(question string, def bool) bool {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false
+	}
+
+	hint := "[y/N]"
+	if def {
+		hint = "[Y/n]"
+	}
+
+	fmt.Fprintf(os.Stderr, "%s %s ", question, hint)
+
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "":
+		return def
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
@@ -17,7 +17,7 @@ type SyncCmd struct {
 
 // SyncCreateCmd creates a sync.
 type SyncCreateCmd struct {
-	AccountID string `help:"Account ID (default: from config or auto-detect)" name:"account-id"`
+	AccountID string `help:"Account ID, alias, IBAN (suffix) or name (default: from config or auto-detect)" name:"account-id" aliases:"account"`
 	Subtype   string `required:"" help:"Sync subtype (accountDetails, accountTransactions)" enum:"accountDetails,accountTransactions"`
 	Wait      bool   `help:"Wait for sync to complete"`
 }
@@ -45,9 +45,7 @@ func (c *SyncCreateCmd) Run(ctx context.Context) error {
 		}
 	}
 
-	mode := output.ModeFrom(ctx)
-
-	return output.Sync(mode, sync)
+	return output.Sync(ctx, sync)
 }
 
 // SyncGetCmd gets sync status.
@@ -66,14 +64,12 @@ func (c *SyncGetCmd) Run(ctx context.Context) error {
 		return fmt.Errorf("get sync: %w", err)
 	}
 
-	mode := output.ModeFrom(ctx)
-
-	return output.Sync(mode, sync)
+	return output.Sync(ctx, sync)
 }
 
 // SyncListCmd lists syncs.
 type SyncListCmd struct {
-	AccountID string `help:"Account ID (default: from config or auto-detect)" name:"account-id"`
+	AccountID string `help:"Account ID, alias, IBAN (suffix) or name (default: from config or auto-detect)" name:"account-id" aliases:"account"`
 	Limit     int    `help:"Maximum number of syncs" default:"10"`
 }
 
@@ -93,7 +89,5 @@ func (c *SyncListCmd) Run(ctx context.Context) error {
 		return fmt.Errorf("list syncs: %w", err)
 	}
 
-	mode := output.ModeFrom(ctx)
-
-	return output.Syncs(mode, syncs)
+	return output.Syncs(ctx, syncs)
 }
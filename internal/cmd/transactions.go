@@ -2,7 +2,11 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
 
 	"github.com/dedene/ponto-cli/internal/api"
 	"github.com/dedene/ponto-cli/internal/output"
@@ -15,17 +19,21 @@ type TransactionsCmd struct {
 	Export TransactionsExportCmd `cmd:"" help:"Export transactions"`
 }
 
+// accountParallelism bounds the number of accounts queried at once.
+const accountParallelism = 4
+
 // TransactionsListCmd lists transactions.
 type TransactionsListCmd struct {
-	AccountID string `help:"Account ID (default: from config or auto-detect)" name:"account-id"`
-	Since     string `help:"Start date (ISO 8601 or relative like -30d)"`
-	Until     string `help:"End date (ISO 8601 or relative like -1d)"`
-	Limit     int    `help:"Maximum number of transactions" default:"100"`
-	Type      string `help:"Filter by type: income, expense, or all" enum:"income,expense,all" default:"all"`
+	AccountIDs  []string `help:"Account ID, alias, IBAN (suffix) or name; repeatable (default: from config or auto-detect)" name:"account-id" aliases:"account" sep:"none"`
+	AllAccounts bool     `help:"List transactions of all accounts" name:"all-accounts"`
+	Since       string   `help:"Start date (ISO 8601 or relative like -30d)"`
+	Until       string   `help:"End date (ISO 8601 or relative like -1d)"`
+	Limit       int      `help:"Maximum number of transactions" default:"100"`
+	Type        string   `help:"Filter by type: income, expense, or all" enum:"income,expense,all" default:"all"`
 }
 
 func (c *TransactionsListCmd) Run(ctx context.Context) error {
-	accountID, err := ResolveAccountID(ctx, c.AccountID)
+	accountIDs, err := resolveAccountIDs(ctx, c.AccountIDs, c.AllAccounts)
 	if err != nil {
 		return err
 	}
@@ -41,20 +49,28 @@ func (c *TransactionsListCmd) Run(ctx context.Context) error {
 		Limit: c.Limit,
 	}
 
-	transactions, err := client.ListTransactions(ctx, accountID, opts)
+	if !c.AllAccounts && len(accountIDs) == 1 {
+		return writeTransactions(ctx, client, accountIDs[0], opts, c.Type)
+	}
+
+	sheets, err := fetchSheets(ctx, client, accountIDs, opts, c.Type)
 	if err != nil {
-		return fmt.Errorf("list transactions: %w", err)
+		return err
 	}
 
-	transactions = filterTransactionsByType(transactions, c.Type)
-	mode := output.ModeFrom(ctx)
+	// Each account returns its newest transactions, so the newest of the
+	// merged list are the newest overall.
+	txns := mergeSheets(sheets)
+	if c.Limit > 0 && len(txns) > c.Limit {
+		txns = txns[:c.Limit]
+	}
 
-	return output.Transactions(mode, transactions)
+	return output.AccountTransactions(ctx, txns)
 }
 
 // TransactionsGetCmd gets transaction details.
 type TransactionsGetCmd struct {
-	AccountID string `help:"Account ID (default: from config or auto-detect)" name:"account-id"`
+	AccountID string `help:"Account ID, alias, IBAN (suffix) or name (default: from config or auto-detect)" name:"account-id" aliases:"account"`
 	ID        string `arg:"" help:"Transaction ID (use - for stdin)"`
 }
 
@@ -69,8 +85,6 @@ func (c *TransactionsGetCmd) Run(ctx context.Context) error {
 		return err
 	}
 
-	mode := output.ModeFrom(ctx)
-
 	// Handle stdin batching
 	ids, err := ReadStdinIDs(c.ID)
 	if err != nil {
@@ -87,7 +101,7 @@ func (c *TransactionsGetCmd) Run(ctx context.Context) error {
 			return fmt.Errorf("get transaction %s: %w", id, err)
 		}
 
-		if err := output.Transaction(mode, transaction); err != nil {
+		if err := output.Transaction(ctx, transaction); err != nil {
 			return err
 		}
 	}
@@ -97,15 +111,23 @@ func (c *TransactionsGetCmd) Run(ctx context.Context) error {
 
 // TransactionsExportCmd exports transactions.
 type TransactionsExportCmd struct {
-	AccountID string `help:"Account ID (default: from config or auto-detect)" name:"account-id"`
-	Since     string `help:"Start date (ISO 8601 or relative like -30d)"`
-	Until     string `help:"End date (ISO 8601 or relative like -1d)"`
-	Format    string `help:"Output format (csv, json)" default:"csv" enum:"csv,json"`
-	Type      string `help:"Filter by type: income, expense, or all" enum:"income,expense,all" default:"all"`
+	AccountIDs  []string `help:"Account ID, alias, IBAN (suffix) or name; repeatable (default: from config or auto-detect)" name:"account-id" aliases:"account" sep:"none"`
+	AllAccounts bool     `help:"Export transactions of all accounts" name:"all-accounts"`
+	Since       string   `help:"Start date (ISO 8601 or relative like -30d)"`
+	Until       string   `help:"End date (ISO 8601 or relative like -1d)"`
+	Format      string   `help:"Output format (csv, json, ndjson, xlsx)" default:"csv" enum:"csv,json,ndjson,xlsx"`
+	Type        string   `help:"Filter by type: income, expense, or all" enum:"income,expense,all" default:"all"`
+	OutputDir   string   `help:"Write export files into this directory" name:"output-dir" type:"path"`
+	Name        string   `help:"File naming template for --output-dir (placeholders: {account}, {account_id}, {iban}, {since}, {until}, {month}, {ext})" default:"{account}_{since}_{until}.{ext}"`
+	SplitBy     string   `help:"Split --output-dir exports into one file per month or account" name:"split-by" enum:",month,account" default:""`
 }
 
 func (c *TransactionsExportCmd) Run(ctx context.Context) error {
-	accountID, err := ResolveAccountID(ctx, c.AccountID)
+	if c.SplitBy != "" && c.OutputDir == "" {
+		return fmt.Errorf("--split-by requires --output-dir")
+	}
+
+	accountIDs, err := resolveAccountIDs(ctx, c.AccountIDs, c.AllAccounts)
 	if err != nil {
 		return err
 	}
@@ -121,19 +143,197 @@ func (c *TransactionsExportCmd) Run(ctx context.Context) error {
 		Limit: 0, // no limit for export
 	}
 
+	// Exports of several accounts carry the account columns in every file,
+	// however the transactions end up split over them.
+	multi := c.AllAccounts || len(accountIDs) > 1
+
+	if c.OutputDir != "" {
+		return c.exportToDir(ctx, client, accountIDs, opts, multi)
+	}
+
+	if c.Format == "xlsx" {
+		return c.exportXLSX(ctx, client, accountIDs, opts)
+	}
+
+	ctx = output.WithMode(ctx, exportMode(c.Format))
+
+	if !multi {
+		return writeTransactions(ctx, client, accountIDs[0], opts, c.Type)
+	}
+
+	sheets, err := fetchSheets(ctx, client, accountIDs, opts, c.Type)
+	if err != nil {
+		return err
+	}
+
+	return output.AccountTransactions(ctx, mergeSheets(sheets))
+}
+
+func (c *TransactionsExportCmd) checkFanout() error {
+	if c.Format == "xlsx" || c.OutputDir != "" {
+		return errors.New("--format=xlsx and --output-dir are not supported with --profiles")
+	}
+
+	return nil
+}
+
+func (c *TransactionsExportCmd) exportXLSX(ctx context.Context, client *api.Client, accountIDs []string, opts api.TransactionListOptions) error {
+	w, err := binaryWriter(ctx)
+	if err != nil {
+		return err
+	}
+
+	sheets, err := fetchSheets(ctx, client, accountIDs, opts, c.Type)
+	if err != nil {
+		return err
+	}
+
+	return output.TransactionsXLSX(ctx, w, sheets)
+}
+
+// exportToDir writes one file per export group into c.OutputDir.
+func (c *TransactionsExportCmd) exportToDir(ctx context.Context, client *api.Client, accountIDs []string, opts api.TransactionListOptions, multi bool) error {
+	sheets, err := fetchSheets(ctx, client, accountIDs, opts, c.Type)
+	if err != nil {
+		return err
+	}
+
+	rng, err := resolveExportRange(c.Since, c.Until)
+	if err != nil {
+		return err
+	}
+
+	files, err := planExport(sheets, c.SplitBy, c.Name, c.Format, rng)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := c.writeExportFile(ctx, file, multi); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fetchSheets fetches the transactions of each account, at most
+// accountParallelism accounts at a time, keeping the order of accountIDs.
+func fetchSheets(ctx context.Context, client *api.Client, accountIDs []string, opts api.TransactionListOptions, typ string) ([]output.TransactionSheet, error) {
+	sheets := make([]output.TransactionSheet, len(accountIDs))
+	errs := make([]error, len(accountIDs))
+	sem := make(chan struct{}, accountParallelism)
+
+	var wg sync.WaitGroup
+
+	for i, id := range accountIDs {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			sheets[i], errs[i] = fetchSheet(ctx, client, id, opts, typ)
+		}()
+	}
+
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return sheets, nil
+}
+
+func fetchSheet(ctx context.Context, client *api.Client, accountID string, opts api.TransactionListOptions, typ string) (output.TransactionSheet, error) {
+	account, err := client.GetAccount(ctx, accountID)
+	if err != nil {
+		return output.TransactionSheet{}, fmt.Errorf("get account %s: %w", accountID, err)
+	}
+
 	transactions, err := client.ListTransactions(ctx, accountID, opts)
 	if err != nil {
-		return fmt.Errorf("list transactions: %w", err)
+		return output.TransactionSheet{}, fmt.Errorf("list transactions of %s: %w", accountID, err)
 	}
 
-	transactions = filterTransactionsByType(transactions, c.Type)
+	return output.TransactionSheet{
+		Account:      *account,
+		Transactions: filterTransactionsByType(transactions, typ),
+	}, nil
+}
+
+// writeExportFile writes one planned file. With multi set it uses the
+// merged layout with account columns, even when the file holds a single
+// account's transactions.
+func (c *TransactionsExportCmd) writeExportFile(ctx context.Context, file exportFile, multi bool) error {
+	path := filepath.Join(c.OutputDir, file.Name)
 
-	mode := output.ModeCSV
-	if c.Format == "json" {
-		mode = output.ModeJSON
+	f, err := output.CreateAtomic(path)
+	if err != nil {
+		return err
 	}
+	defer f.Abort()
+
+	fileCtx := output.WithWriter(output.WithMode(ctx, exportMode(c.Format)), f)
+
+	switch {
+	case c.Format == "xlsx":
+		err = output.TransactionsXLSX(ctx, f, file.Sheets)
+	case multi:
+		err = output.AccountTransactions(fileCtx, mergeSheets(file.Sheets))
+	default:
+		err = output.Transactions(fileCtx, file.transactions())
+	}
+
+	if err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+
+	if err := f.Commit(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Wrote %s (%d transactions)\n", path, len(file.transactions()))
+
+	return nil
+}
+
+func exportMode(format string) output.Mode {
+	switch format {
+	case "json":
+		return output.ModeJSON
+	case "ndjson":
+		return output.ModeNDJSON
+	default:
+		return output.ModeCSV
+	}
+}
+
+// writeTransactions fetches and outputs transactions, streaming page by page
+// when the output mode allows it.
+func writeTransactions(ctx context.Context, client *api.Client, accountID string, opts api.TransactionListOptions, typ string) error {
+	if output.Streams(ctx) {
+		err := client.EachTransactionPage(ctx, accountID, opts, func(page []api.Transaction) error {
+			return output.Transactions(ctx, filterTransactionsByType(page, typ))
+		})
+		if err != nil {
+			return fmt.Errorf("list transactions: %w", err)
+		}
+
+		return nil
+	}
+
+	transactions, err := client.ListTransactions(ctx, accountID, opts)
+	if err != nil {
+		return fmt.Errorf("list transactions: %w", err)
+	}
+
+	transactions = filterTransactionsByType(transactions, typ)
 
-	return output.Transactions(mode, transactions)
+	return output.Transactions(ctx, transactions)
 }
 
 // filterTransactionsByType filters transactions by income/expense type.
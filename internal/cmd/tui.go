@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+
+	"golang.org/x/term"
+
+	"github.com/dedene/ponto-cli/internal/api"
+	pontoCtx "github.com/dedene/ponto-cli/internal/ctx"
+	"github.com/dedene/ponto-cli/internal/tui"
+)
+
+// TuiCmd runs the full-screen dashboard.
+type TuiCmd struct {
+	Since string `help:"Load transactions from this date (ISO 8601 or relative like -90d)"`
+	Limit int    `help:"Maximum number of transactions to load per account" default:"500"`
+}
+
+func (c *TuiCmd) Run(ctx context.Context) error {
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return errors.New("ponto tui needs a terminal; use the list commands in scripts")
+	}
+
+	client, err := api.NewClientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Log lines would tear through the full-screen display.
+	logger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	defer slog.SetDefault(logger)
+
+	src := &dashboardSource{client: client, opts: api.TransactionListOptions{Since: c.Since, Limit: c.Limit}}
+
+	return tui.Run(ctx, os.Stdin, os.Stdout, pontoCtx.ProfileFrom(ctx), src)
+}
+
+// dashboardSource serves the dashboard from the Ponto API.
+type dashboardSource struct {
+	client *api.Client
+	opts   api.TransactionListOptions
+}
+
+func (s *dashboardSource) Accounts(ctx context.Context) ([]api.Account, error) {
+	return s.client.ListAccounts(ctx)
+}
+
+func (s *dashboardSource) Transactions(ctx context.Context, accountID string) ([]api.Transaction, error) {
+	return s.client.ListTransactions(ctx, accountID, s.opts)
+}
+
+func (s *dashboardSource) PendingTransactions(ctx context.Context, accountID string) ([]api.PendingTransaction, error) {
+	return s.client.ListPendingTransactions(ctx, accountID)
+}
+
+func (s *dashboardSource) CreateSync(ctx context.Context, accountID string) (*api.Synchronization, error) {
+	return s.client.CreateSync(ctx, accountID, "accountTransactions")
+}
+
+func (s *dashboardSource) GetSync(ctx context.Context, id string) (*api.Synchronization, error) {
+	return s.client.GetSync(ctx, id)
+}
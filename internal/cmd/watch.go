@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/dedene/ponto-cli/internal/api"
+	pontoCtx "github.com/dedene/ponto-cli/internal/ctx"
+	"github.com/dedene/ponto-cli/internal/output"
+	"github.com/dedene/ponto-cli/internal/watch"
+)
+
+// minWatchInterval keeps watch from hammering the API.
+const minWatchInterval = time.Minute
+
+// WatchCmd reports new transactions as they arrive.
+type WatchCmd struct {
+	AccountIDs   []string      `help:"Account to watch: ID, alias, IBAN (suffix) or name (repeatable; default: all accounts)" name:"account-id" aliases:"account" sep:"none"`
+	Interval     time.Duration `help:"Time between polls" default:"5m"`
+	NoSync       bool          `name:"no-sync" help:"Don't synchronize accounts, only report what Ponto already has"`
+	SyncCooldown time.Duration `name:"sync-cooldown" help:"Minimum time between synchronizations of an account" default:"30m"`
+	Notify       bool          `help:"Show a desktop notification for each new transaction"`
+	Exec         string        `help:"Run a command for each new transaction; each word is a Go template over the event (e.g. 'notify.sh {{.Amount}}'), which is also passed as JSON on stdin"`
+	Once         bool          `help:"Poll once and exit"`
+}
+
+func (c *WatchCmd) Run(ctx context.Context) error {
+	if c.Interval < minWatchInterval && !c.Once {
+		return fmt.Errorf("--interval must be at least %s", minWatchInterval)
+	}
+
+	var hook *watch.Hook
+
+	if c.Exec != "" {
+		var err error
+
+		if hook, err = watch.ParseHook(c.Exec); err != nil {
+			return fmt.Errorf("--exec: %w", err)
+		}
+	}
+
+	if c.Notify {
+		if err := watch.CheckNotify(); err != nil {
+			return err
+		}
+	}
+
+	var ids []string
+
+	if len(c.AccountIDs) > 0 {
+		var err error
+
+		if ids, err = resolveAccountIDs(ctx, c.AccountIDs, false); err != nil {
+			return err
+		}
+	}
+
+	client, err := api.NewClientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	profile := pontoCtx.ProfileFrom(ctx)
+
+	path, err := watch.StatePath("watch", profile)
+	if err != nil {
+		return err
+	}
+
+	state, err := watch.LoadState(path)
+	if err != nil {
+		return err
+	}
+
+	w := &watch.Watcher{
+		Source:       client,
+		State:        state,
+		Profile:      profile,
+		AccountIDs:   ids,
+		Sync:         !c.NoSync,
+		SyncCooldown: c.SyncCooldown,
+	}
+
+	emit := func(ctx context.Context, e watch.Event) error {
+		if err := output.JSONCompact(output.WriterFrom(ctx), e); err != nil {
+			return err
+		}
+
+		if c.Notify {
+			if err := watch.Notify(ctx, e); err != nil {
+				slog.Warn("notification failed", "transaction", e.ID, "error", err)
+			}
+		}
+
+		if hook != nil {
+			if err := hook.Run(ctx, e); err != nil {
+				slog.Warn("hook failed", "transaction", e.ID, "error", err)
+			}
+		}
+
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	slog.Info("watching for new transactions", "profile", profile, "interval", c.Interval, "state", path)
+
+	for {
+		err := pollOnce(ctx, w, emit)
+
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case c.Once:
+			return err
+		case err != nil:
+			slog.Warn("poll failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(c.Interval):
+		}
+	}
+}
+
+// pollOnce emits the events of one poll and saves the watcher's state
+// afterwards, so an interrupted run reports them again rather than never.
+// When emit fails the state goes back to before the poll, so the next poll
+// finds the events that were not emitted instead of saving marks past them.
+func pollOnce(ctx context.Context, w *watch.Watcher, emit func(context.Context, watch.Event) error) error {
+	before := w.State.Clone()
+	events, pollErr := w.Poll(ctx)
+
+	for _, e := range events {
+		if err := emit(ctx, e); err != nil {
+			w.State.Accounts = before.Accounts
+
+			return errors.Join(pollErr, err)
+		}
+	}
+
+	if err := w.State.Save(); err != nil {
+		return errors.Join(pollErr, err)
+	}
+
+	return pollErr
+}
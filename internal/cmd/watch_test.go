@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/dedene/ponto-cli/internal/api"
+	"github.com/dedene/ponto-cli/internal/watch"
+)
+
+type watchSource struct {
+	txns []api.Transaction // newest first
+}
+
+func (s *watchSource) ListAccounts(context.Context) ([]api.Account, error) {
+	return []api.Account{{ID: "a1"}}, nil
+}
+
+func (s *watchSource) EachTransactionPage(_ context.Context, _ string, opts api.TransactionListOptions, fn func([]api.Transaction) error) error {
+	txns := s.txns
+	if opts.Limit > 0 && len(txns) > opts.Limit {
+		txns = txns[:opts.Limit]
+	}
+
+	return fn(txns)
+}
+
+func (s *watchSource) ListPendingTransactions(context.Context, string) ([]api.PendingTransaction, error) {
+	return nil, nil
+}
+
+func (s *watchSource) CreateSync(context.Context, string, string) (*api.Synchronization, error) {
+	return nil, errors.New("not used")
+}
+
+func (s *watchSource) WaitForSync(context.Context, string) (*api.Synchronization, error) {
+	return nil, errors.New("not used")
+}
+
+func TestPollOnceRollsBackOnEmitFailure(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	state, err := watch.LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := &watchSource{txns: []api.Transaction{{ID: "t1"}}}
+	w := &watch.Watcher{Source: src, State: state}
+
+	var (
+		emitted []string
+		full    = true
+	)
+
+	emit := func(_ context.Context, e watch.Event) error {
+		if e.ID == "t3" && full {
+			full = false
+
+			return errors.New("disk full")
+		}
+
+		emitted = append(emitted, e.ID)
+
+		return nil
+	}
+
+	if err := pollOnce(context.Background(), w, emit); err != nil {
+		t.Fatal(err)
+	}
+
+	src.txns = []api.Transaction{{ID: "t3"}, {ID: "t2"}, {ID: "t1"}}
+
+	if err := pollOnce(context.Background(), w, emit); err == nil {
+		t.Fatal("pollOnce() succeeded, want the emit error")
+	}
+
+	if got := state.Accounts["a1"].LastTransactionID; got != "t1" {
+		t.Errorf("mark after failed emit = %q, want t1", got)
+	}
+
+	if err := pollOnce(context.Background(), w, emit); err != nil {
+		t.Fatal(err)
+	}
+
+	// t2 is emitted again: delivery is at least once
+	want := []string{"t2", "t2", "t3"}
+	if !slices.Equal(emitted, want) {
+		t.Errorf("emitted %v, want %v", emitted, want)
+	}
+
+	saved, err := watch.LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := saved.Accounts["a1"].LastTransactionID; got != "t3" {
+		t.Errorf("saved mark = %q, want t3", got)
+	}
+}
@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/dedene/ponto-cli/internal/api"
+	pontoCtx "github.com/dedene/ponto-cli/internal/ctx"
+	"github.com/dedene/ponto-cli/internal/output"
+	"github.com/dedene/ponto-cli/internal/relay"
+	"github.com/dedene/ponto-cli/internal/webhook"
+)
+
+// WebhooksCmd is the parent command for receiving Ponto webhooks.
+type WebhooksCmd struct {
+	Serve  WebhooksServeCmd  `cmd:"" help:"Receive, verify and print or forward webhooks"`
+	Verify WebhooksVerifyCmd `cmd:"" help:"Verify and decode a captured webhook"`
+	Keys   WebhooksKeysCmd   `cmd:"" help:"List the keys Ponto signs webhooks with"`
+}
+
+// WebhooksServeCmd receives webhooks over HTTP.
+type WebhooksServeCmd struct {
+	Listen        string `help:"Address to listen on" default:":8080"`
+	Path          string `help:"URL path webhooks are posted to" default:"/"`
+	Fetch         bool   `help:"Fetch the transactions, account or synchronization each event refers to"`
+	ForwardURL    string `name:"forward-url" help:"POST each event to this URL, signed like ponto relay"`
+	ForwardSecret string `name:"forward-secret" env:"PONTO_RELAY_SECRET" help:"Secret for the signature of forwarded events"`
+	CaptureDir    string `name:"capture-dir" type:"path" help:"Save every request to this directory for webhooks verify"`
+	Keys          string `type:"existingfile" help:"Verify with the keys in this file (from webhooks keys --json) instead of fetching them"`
+}
+
+// receivedEvent is a webhook event as printed or forwarded, with the
+// resources it refers to when they were fetched.
+type receivedEvent struct {
+	*webhook.Event
+	Data any `json:"data,omitempty"`
+}
+
+func (c *WebhooksServeCmd) Run(ctx context.Context) error {
+	if c.ForwardURL != "" && c.ForwardSecret == "" {
+		return errors.New("--forward-url needs --forward-secret or PONTO_RELAY_SECRET")
+	}
+
+	client, verifier, err := webhookVerifier(ctx, c.Keys, c.Fetch)
+	if err != nil {
+		return err
+	}
+
+	if !c.Fetch {
+		client = nil
+	}
+
+	var forward *relay.Sender
+
+	if c.ForwardURL != "" {
+		forward = &relay.Sender{
+			URL:    c.ForwardURL,
+			Secret: c.ForwardSecret,
+			Client: &http.Client{Timeout: pontoCtx.TimeoutFrom(ctx)},
+		}
+	}
+
+	var mu sync.Mutex
+
+	handle := func(ctx context.Context, e *webhook.Event) error {
+		rec, err := receive(ctx, client, e)
+		if err != nil {
+			return err
+		}
+
+		if forward != nil {
+			if err := forwardEvent(ctx, forward, rec); err != nil {
+				return err
+			}
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		return output.JSONCompact(output.WriterFrom(ctx), rec)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(c.Path, &webhook.Handler{Verifier: verifier, CaptureDir: c.CaptureDir, Handle: handle})
+
+	srv := &http.Server{
+		Addr:              c.Listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		_ = srv.Shutdown(shutdown)
+	}()
+
+	fmt.Fprintf(os.Stderr, "Listening for webhooks on %s%s\n", c.Listen, c.Path)
+
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serve webhooks: %w", err)
+	}
+
+	return nil
+}
+
+// WebhooksVerifyCmd verifies a captured webhook.
+type WebhooksVerifyCmd struct {
+	File       string `arg:"" type:"existingfile" help:"Capture from webhooks serve --capture-dir, or a raw body with --signature"`
+	Signature  string `help:"Signature header of a raw body"`
+	Keys       string `type:"existingfile" help:"Verify with the keys in this file (from webhooks keys --json) instead of fetching them"`
+	SkipExpiry bool   `name:"skip-expiry" help:"Accept an expired signature, e.g. of an old capture"`
+	Fetch      bool   `help:"Fetch the resources the event refers to"`
+}
+
+func (c *WebhooksVerifyCmd) Run(ctx context.Context) error {
+	capture := &webhook.Capture{Signature: c.Signature}
+
+	if c.Signature != "" {
+		body, err := os.ReadFile(c.File)
+		if err != nil {
+			return fmt.Errorf("read body: %w", err)
+		}
+
+		capture.Body = string(body)
+	} else {
+		var err error
+
+		if capture, err = webhook.ReadCapture(c.File); err != nil {
+			return err
+		}
+	}
+
+	client, verifier, err := webhookVerifier(ctx, c.Keys, c.Fetch)
+	if err != nil {
+		return err
+	}
+
+	verifier.SkipExpiry = c.SkipExpiry
+
+	if !c.Fetch {
+		client = nil
+	}
+
+	if err := verifier.Verify(ctx, []byte(capture.Body), capture.Signature); err != nil {
+		return err
+	}
+
+	e, err := webhook.Decode([]byte(capture.Body))
+	if err != nil {
+		return err
+	}
+
+	rec, err := receive(ctx, client, e)
+	if err != nil {
+		return err
+	}
+
+	return output.JSON(output.WriterFrom(ctx), rec)
+}
+
+// WebhooksKeysCmd lists the webhook signing keys.
+type WebhooksKeysCmd struct{}
+
+func (c *WebhooksKeysCmd) Run(ctx context.Context) error {
+	client, err := api.NewClientFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys, err := client.ListWebhookKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("list webhook keys: %w", err)
+	}
+
+	return output.WebhookKeys(ctx, keys)
+}
+
+// webhookVerifier builds a verifier with the keys from keysFile, or from
+// the API without one. The client is nil when neither the keys nor fetch
+// need it; otherwise webhooks must be addressed to its client ID.
+func webhookVerifier(ctx context.Context, keysFile string, fetch bool) (*api.Client, *webhook.Verifier, error) {
+	v := &webhook.Verifier{}
+
+	if keysFile != "" {
+		keys, err := readWebhookKeys(keysFile)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		v.Keys = func(context.Context) ([]api.WebhookKey, error) { return keys, nil }
+
+		if !fetch {
+			return nil, v, nil
+		}
+	}
+
+	client, err := api.NewClientFromContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if v.Keys == nil {
+		v.Keys = client.ListWebhookKeys
+	}
+
+	v.Audience = client.ClientID()
+
+	return client, v, nil
+}
+
+func readWebhookKeys(path string) ([]api.WebhookKey, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read webhook keys: %w", err)
+	}
+
+	var keys []api.WebhookKey
+	if err := json.Unmarshal(b, &keys); err != nil {
+		return nil, fmt.Errorf("parse webhook keys %s: %w", path, err)
+	}
+
+	return keys, nil
+}
+
+// receive fetches the resources of e when client is set, i.e. with --fetch.
+func receive(ctx context.Context, client *api.Client, e *webhook.Event) (*receivedEvent, error) {
+	rec := &receivedEvent{Event: e}
+
+	if client == nil {
+		return rec, nil
+	}
+
+	data, err := webhook.Fetch(ctx, client, e)
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s resources: %w", e.Type, err)
+	}
+
+	rec.Data = data
+
+	return rec, nil
+}
+
+func forwardEvent(ctx context.Context, s *relay.Sender, rec *receivedEvent) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+
+	created, err := time.Parse(time.RFC3339, rec.CreatedAt)
+	if err != nil {
+		created = time.Now().UTC()
+	}
+
+	if err := s.Send(ctx, &relay.Delivery{ID: rec.ID, Type: rec.Type, CreatedAt: created, Data: data}); err != nil {
+		return fmt.Errorf("forward: %w", err)
+	}
+
+	slog.Info("forwarded webhook", "id", rec.ID, "type", rec.Type)
+
+	return nil
+}
@@ -1,8 +1,12 @@
 package config
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -12,12 +16,155 @@ type File struct {
 	DefaultProfile string             `yaml:"default_profile,omitempty"`
 	Profiles       map[string]Profile `yaml:"profiles,omitempty"`
 	KeyringBackend string             `yaml:"keyring_backend,omitempty"`
+	Backends       Backends           `yaml:"backends,omitempty"`
+
+	// Settings apply to every profile unless the profile overrides them.
+	Settings `yaml:",inline"`
+}
+
+// Settings are options that can be set for all profiles at the top level of
+// the config file and overridden per profile.
+type Settings struct {
+	Output     string         `yaml:"output,omitempty"`
+	Timeout    time.Duration  `yaml:"timeout,omitempty"`
+	Columns    ColumnDefaults `yaml:"columns,omitempty"`
+	BaseURL    string         `yaml:"base_url,omitempty"`
+	NoRetry    *bool          `yaml:"no_retry,omitempty"`
+	MaxRetries *int           `yaml:"max_retries,omitempty"`
+	RetryDelay time.Duration  `yaml:"retry_delay,omitempty"`
+
+	EnableCommands string `yaml:"enable_commands,omitempty"`
+}
+
+// ColumnDefaults holds the default --columns per list command group.
+type ColumnDefaults struct {
+	Accounts              string `yaml:"accounts,omitempty"`
+	Transactions          string `yaml:"transactions,omitempty"`
+	PendingTransactions   string `yaml:"pending_transactions,omitempty"`
+	Sync                  string `yaml:"sync,omitempty"`
+	FinancialInstitutions string `yaml:"financial_institutions,omitempty"`
+}
+
+// For returns the default columns for a command group such as
+// "transactions", or "" when none are set.
+func (c ColumnDefaults) For(group string) string {
+	for _, k := range columnKeys {
+		if k.group == group {
+			return *k.field(&c)
+		}
+	}
+
+	return ""
+}
+
+// Backends configures the external credential backends selectable with
+// keyring_backend.
+type Backends struct {
+	Exec        ExecBackend        `yaml:"exec,omitempty"`
+	Vault       VaultBackend       `yaml:"vault,omitempty"`
+	Pass        PassBackend        `yaml:"pass,omitempty"`
+	OnePassword OnePasswordBackend `yaml:"1password,omitempty"`
+}
+
+// ExecBackend configures a credential helper that speaks JSON over
+// stdin/stdout.
+type ExecBackend struct {
+	Command string   `yaml:"command,omitempty"`
+	Args    []string `yaml:"args,omitempty"`
+}
+
+// VaultBackend configures a HashiCorp Vault KV v2 secrets engine. The token
+// is read from VAULT_TOKEN or ~/.vault-token.
+type VaultBackend struct {
+	Address string `yaml:"address,omitempty"` // defaults to VAULT_ADDR
+	Mount   string `yaml:"mount,omitempty"`   // defaults to "secret"
+	Path    string `yaml:"path,omitempty"`    // defaults to "ponto"
+}
+
+// PassBackend configures the pass password store.
+type PassBackend struct {
+	Prefix string `yaml:"prefix,omitempty"` // defaults to "ponto"
+}
+
+// OnePasswordBackend configures the 1Password CLI.
+type OnePasswordBackend struct {
+	Vault   string `yaml:"vault,omitempty"`
+	Account string `yaml:"account,omitempty"`
 }
 
 // Profile represents a named profile configuration.
 type Profile struct {
 	// Credentials are stored in keyring, not here
-	AccountID string `yaml:"account_id,omitempty"`
+	AccountID string            `yaml:"account_id,omitempty"`
+	Aliases   map[string]string `yaml:"aliases,omitempty"` // account alias -> account ID
+	Connect   *ConnectProfile   `yaml:"connect,omitempty"`
+	Signing   *SigningProfile   `yaml:"signing,omitempty"`
+
+	SecretRotatedAt time.Time `yaml:"secret_rotated_at,omitempty"`
+
+	Settings `yaml:",inline"`
+}
+
+// SettingsFor returns the settings in effect for profile: the profile's own
+// values, falling back to the top-level ones.
+func (f File) SettingsFor(profile string) Settings {
+	s := f.Settings
+	p := f.Profiles[profile].Settings
+
+	if p.Output != "" {
+		s.Output = p.Output
+	}
+
+	if p.Timeout != 0 {
+		s.Timeout = p.Timeout
+	}
+
+	if p.BaseURL != "" {
+		s.BaseURL = p.BaseURL
+	}
+
+	if p.NoRetry != nil {
+		s.NoRetry = p.NoRetry
+	}
+
+	if p.MaxRetries != nil {
+		s.MaxRetries = p.MaxRetries
+	}
+
+	if p.RetryDelay != 0 {
+		s.RetryDelay = p.RetryDelay
+	}
+
+	if p.EnableCommands != "" {
+		s.EnableCommands = p.EnableCommands
+	}
+
+	for _, k := range columnKeys {
+		if v := *k.field(&p.Columns); v != "" {
+			*k.field(&s.Columns) = v
+		}
+	}
+
+	return s
+}
+
+// ConnectProfile configures a Ponto Connect integration, which uses mutual
+// TLS and the authorization-code flow instead of client credentials.
+type ConnectProfile struct {
+	CertFile     string `yaml:"cert_file"`
+	KeyFile      string `yaml:"key_file,omitempty"` // empty for PKCS#12 bundles
+	AuthorizeURL string `yaml:"authorize_url,omitempty"`
+	RedirectURI  string `yaml:"redirect_uri,omitempty"`
+	Scope        string `yaml:"scope,omitempty"`
+}
+
+// SigningProfile configures HTTP request signing for Ponto Connect payment
+// endpoints.
+type SigningProfile struct {
+	KeyID     string   `yaml:"key_id"`
+	KeyFile   string   `yaml:"key_file,omitempty"` // empty when the key is held in the keyring
+	Algorithm string   `yaml:"algorithm,omitempty"`
+	Paths     []string `yaml:"paths,omitempty"` // path patterns to sign; defaults to the payment endpoints
 }
 
 // ReadConfig reads the config file.
@@ -44,6 +191,25 @@ func ReadConfig() (File, error) {
 	return cfg, nil
 }
 
+// ParseConfig strictly decodes and validates config file contents; unknown
+// keys are errors.
+func ParseConfig(b []byte) (File, error) {
+	var cfg File
+
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return File{}, fmt.Errorf("parse config: %w", err)
+	}
+
+	if err := Validate(cfg); err != nil {
+		return File{}, err
+	}
+
+	return cfg, nil
+}
+
 // WriteConfig writes the config file atomically.
 func WriteConfig(cfg File) error {
 	if _, err := EnsureDir(); err != nil {
@@ -60,6 +226,29 @@ func WriteConfig(cfg File) error {
 		return fmt.Errorf("encode config yaml: %w", err)
 	}
 
+	return writeConfigFile(path, b)
+}
+
+// WriteConfigBytes validates raw config file contents and writes them
+// atomically, keeping comments and layout intact.
+func WriteConfigBytes(b []byte) error {
+	if _, err := ParseConfig(b); err != nil {
+		return err
+	}
+
+	if _, err := EnsureDir(); err != nil {
+		return fmt.Errorf("ensure config dir: %w", err)
+	}
+
+	path, err := ConfigPath()
+	if err != nil {
+		return err
+	}
+
+	return writeConfigFile(path, b)
+}
+
+func writeConfigFile(path string, b []byte) error {
 	tmp := path + ".tmp"
 
 	if err := os.WriteFile(tmp, b, 0o600); err != nil {
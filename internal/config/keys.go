@@ -0,0 +1,423 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Scope says where a config key can be set.
+type Scope int
+
+const (
+	ScopeGlobal Scope = 1 << iota
+	ScopeProfile
+	ScopeBoth = ScopeGlobal | ScopeProfile
+)
+
+// String implements fmt.Stringer.
+func (s Scope) String() string {
+	switch s {
+	case ScopeGlobal:
+		return "global"
+	case ScopeProfile:
+		return "profile"
+	default:
+		return "global, profile"
+	}
+}
+
+// Key describes a config key that can be read and written with
+// `ponto config`.
+type Key struct {
+	Name    string
+	Help    string
+	Scope   Scope
+	Default string   // value used when the key is unset
+	Values  []string // allowed values; nil when free-form
+
+	check    func(string) error
+	file     func(*File) any     // top-level field
+	profile  func(*Profile) any  // per-profile field
+	settings func(*Settings) any // top-level or per-profile setting
+}
+
+var errUnknownKey = errors.New("unknown config key")
+
+// columnKeys lists the list command groups that accept default columns.
+var columnKeys = []struct {
+	group string
+	field func(*ColumnDefaults) *string
+}{
+	{"accounts", func(c *ColumnDefaults) *string { return &c.Accounts }},
+	{"transactions", func(c *ColumnDefaults) *string { return &c.Transactions }},
+	{"pending-transactions", func(c *ColumnDefaults) *string { return &c.PendingTransactions }},
+	{"sync", func(c *ColumnDefaults) *string { return &c.Sync }},
+	{"financial-institutions", func(c *ColumnDefaults) *string { return &c.FinancialInstitutions }},
+}
+
+var keys = buildKeys()
+
+func buildKeys() []Key {
+	ks := []Key{
+		{
+			Name:    "default-profile",
+			Help:    "Profile used when --profile is not given",
+			Scope:   ScopeGlobal,
+			Default: "default",
+			file:    func(f *File) any { return &f.DefaultProfile },
+		},
+		{
+			Name:    "keyring-backend",
+			Help:    "Credential backend",
+			Scope:   ScopeGlobal,
+			Default: "auto",
+			Values:  []string{"auto", "keychain", "file", "exec", "vault", "pass", "1password"},
+			file:    func(f *File) any { return &f.KeyringBackend },
+		},
+		{
+			Name:    "account-id",
+			Help:    "Default account for account-scoped commands",
+			Scope:   ScopeProfile,
+			profile: func(p *Profile) any { return &p.AccountID },
+		},
+		{
+			Name:     "output",
+			Help:     "Default output mode",
+			Scope:    ScopeBoth,
+			Default:  "table",
+			Values:   []string{"table", "json", "csv", "plain", "ndjson"},
+			settings: func(s *Settings) any { return &s.Output },
+		},
+		{
+			Name:     "timeout",
+			Help:     "Request timeout (e.g. 60s, 2m)",
+			Scope:    ScopeBoth,
+			Default:  "30s",
+			settings: func(s *Settings) any { return &s.Timeout },
+		},
+		{
+			Name:     "base-url",
+			Help:     "Ponto API base URL",
+			Scope:    ScopeBoth,
+			Default:  "https://api.myponto.com",
+			check:    checkURL,
+			settings: func(s *Settings) any { return &s.BaseURL },
+		},
+		{
+			Name:     "no-retry",
+			Help:     "Disable retries on rate limits and server errors",
+			Scope:    ScopeBoth,
+			Default:  "false",
+			settings: func(s *Settings) any { return &s.NoRetry },
+		},
+		{
+			Name:     "max-retries",
+			Help:     "Retries on rate limits and server errors (0 disables them)",
+			Scope:    ScopeBoth,
+			Default:  "3",
+			settings: func(s *Settings) any { return &s.MaxRetries },
+		},
+		{
+			Name:     "retry-delay",
+			Help:     "Base delay of the rate-limit backoff",
+			Scope:    ScopeBoth,
+			Default:  "1s",
+			settings: func(s *Settings) any { return &s.RetryDelay },
+		},
+		{
+			Name:     "enable-commands",
+			Help:     "Comma-separated list of enabled commands",
+			Scope:    ScopeBoth,
+			check:    checkList,
+			settings: func(s *Settings) any { return &s.EnableCommands },
+		},
+	}
+
+	for _, c := range columnKeys {
+		field := c.field
+
+		ks = append(ks, Key{
+			Name:     "columns." + c.group,
+			Help:     "Default --columns for " + c.group + " commands",
+			Scope:    ScopeBoth,
+			check:    checkList,
+			settings: func(s *Settings) any { return field(&s.Columns) },
+		})
+	}
+
+	return ks
+}
+
+// Keys returns every known config key.
+func Keys() []Key {
+	return keys
+}
+
+// LookupKey finds a key by name; underscores are accepted for dashes.
+func LookupKey(name string) (Key, error) {
+	name = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "_", "-"))
+
+	names := make([]string, len(keys))
+
+	for i, k := range keys {
+		if k.Name == name {
+			return k, nil
+		}
+
+		names[i] = k.Name
+	}
+
+	return Key{}, fmt.Errorf("%w %q (valid: %s)", errUnknownKey, name, strings.Join(names, ", "))
+}
+
+// Get returns the value of k in one scope: the top level when global is set,
+// otherwise the profile. It returns "" when the key is unset there.
+func (k Key) Get(f File, profile string, global bool) (string, error) {
+	ptr, err := k.ref(&f, profile, global)
+	if err != nil {
+		return "", err
+	}
+
+	return formatValue(ptr), nil
+}
+
+// Effective returns the value of k in effect for profile and where it came
+// from: "profile", "global", or "default" when the key is unset.
+func (k Key) Effective(f File, profile string) (value, source string) {
+	if k.Scope&ScopeProfile != 0 {
+		if v, _ := k.Get(f, profile, false); v != "" {
+			return v, "profile"
+		}
+	}
+
+	if k.Scope&ScopeGlobal != 0 {
+		if v, _ := k.Get(f, profile, true); v != "" {
+			return v, "global"
+		}
+	}
+
+	return k.Default, "default"
+}
+
+// Set validates value and stores it in one scope of f.
+func (k Key) Set(f *File, profile string, global bool, value string) error {
+	if err := k.Validate(value); err != nil {
+		return err
+	}
+
+	return k.update(f, profile, global, func(ptr any) error {
+		return assign(ptr, value)
+	})
+}
+
+// Unset clears k in one scope of f.
+func (k Key) Unset(f *File, profile string, global bool) error {
+	return k.update(f, profile, global, func(ptr any) error {
+		reflect.ValueOf(ptr).Elem().SetZero()
+
+		return nil
+	})
+}
+
+// Validate reports whether value is acceptable for k.
+func (k Key) Validate(value string) error {
+	if len(k.Values) > 0 && !contains(k.Values, value) {
+		return fmt.Errorf("%s: invalid value %q (valid: %s)", k.Name, value, strings.Join(k.Values, ", "))
+	}
+
+	if k.check != nil {
+		if err := k.check(value); err != nil {
+			return fmt.Errorf("%s: %w", k.Name, err)
+		}
+	}
+
+	// Parse into a scratch value of the field's type.
+	var scratch File
+
+	ptr, err := k.ref(&scratch, "", k.Scope&ScopeGlobal != 0)
+	if err != nil {
+		return err
+	}
+
+	if err := assign(ptr, value); err != nil {
+		return fmt.Errorf("%s: %w", k.Name, err)
+	}
+
+	return nil
+}
+
+// Validate checks every key set in f.
+func Validate(f File) error {
+	var errs []error
+
+	for _, k := range keys {
+		if k.Scope&ScopeGlobal != 0 {
+			if v, _ := k.Get(f, "", true); v != "" {
+				errs = append(errs, k.Validate(v))
+			}
+		}
+
+		if k.Scope&ScopeProfile == 0 {
+			continue
+		}
+
+		for name := range f.Profiles {
+			if v, _ := k.Get(f, name, false); v != "" {
+				if err := k.Validate(v); err != nil {
+					errs = append(errs, fmt.Errorf("profile %s: %w", name, err))
+				}
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ref returns a pointer to the field holding k in one scope of f. Profile
+// fields point into a copy; see update.
+func (k Key) ref(f *File, profile string, global bool) (any, error) {
+	switch {
+	case k.file != nil:
+		return k.file(f), nil
+	case global && k.Scope&ScopeGlobal == 0:
+		return nil, fmt.Errorf("%s can only be set per profile", k.Name)
+	case global:
+		return k.settings(&f.Settings), nil
+	}
+
+	p := f.Profiles[profile]
+
+	if k.profile != nil {
+		return k.profile(&p), nil
+	}
+
+	return k.settings(&p.Settings), nil
+}
+
+// update applies fn to the field holding k and writes profile changes back
+// into f.
+func (k Key) update(f *File, profile string, global bool, fn func(ptr any) error) error {
+	if k.file != nil || global {
+		ptr, err := k.ref(f, profile, global)
+		if err != nil {
+			return err
+		}
+
+		return fn(ptr)
+	}
+
+	if f.Profiles == nil {
+		f.Profiles = make(map[string]Profile)
+	}
+
+	p := f.Profiles[profile]
+
+	var ptr any
+	if k.profile != nil {
+		ptr = k.profile(&p)
+	} else {
+		ptr = k.settings(&p.Settings)
+	}
+
+	if err := fn(ptr); err != nil {
+		return err
+	}
+
+	f.Profiles[profile] = p
+
+	return nil
+}
+
+func assign(ptr any, value string) error {
+	switch p := ptr.(type) {
+	case *string:
+		*p = value
+	case *time.Duration:
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid duration %q (e.g. 30s, 2m)", value)
+		}
+
+		*p = d
+	case **int:
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid number %q (must be 0 or more)", value)
+		}
+
+		*p = &n
+	case **bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value %q (must be true or false)", value)
+		}
+
+		*p = &b
+	default:
+		return fmt.Errorf("unsupported config field %T", ptr)
+	}
+
+	return nil
+}
+
+func formatValue(ptr any) string {
+	switch p := ptr.(type) {
+	case *string:
+		return *p
+	case *time.Duration:
+		if *p == 0 {
+			return ""
+		}
+
+		return p.String()
+	case **int:
+		if *p == nil {
+			return ""
+		}
+
+		return strconv.Itoa(**p)
+	case **bool:
+		if *p == nil {
+			return ""
+		}
+
+		return strconv.FormatBool(**p)
+	default:
+		return ""
+	}
+}
+
+func checkURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("invalid URL %q (e.g. https://api.myponto.com)", value)
+	}
+
+	return nil
+}
+
+// checkList rejects comma-separated lists with empty entries.
+func checkList(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		if strings.TrimSpace(item) == "" {
+			return fmt.Errorf("invalid list %q (comma-separated, e.g. a,b,c)", value)
+		}
+	}
+
+	return nil
+}
+
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKeySet(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		key     string
+		value   string
+		global  bool
+		wantErr string
+	}{
+		{key: "timeout", value: "1m30s"},
+		{key: "timeout", value: "soon", wantErr: "invalid duration"},
+		{key: "timeout", value: "-5s", wantErr: "invalid duration"},
+		{key: "output", value: "json", global: true},
+		{key: "output", value: "yaml", wantErr: "valid: table, json"},
+		{key: "base-url", value: "https://sandbox.example.com"},
+		{key: "base-url", value: "api.myponto.com", wantErr: "invalid URL"},
+		{key: "max_retries", value: "5"},
+		{key: "max-retries", value: "0"},
+		{key: "max-retries", value: "-1", wantErr: "0 or more"},
+		{key: "no-retry", value: "yes", wantErr: "true or false"},
+		{key: "columns.transactions", value: "id,,amount", wantErr: "invalid list"},
+		{key: "keyring-backend", value: "vault"},
+		{key: "account-id", value: "abc", global: true, wantErr: "only be set per profile"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			t.Parallel()
+
+			k, err := LookupKey(tt.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var f File
+
+			err = k.Set(&f, "acme", tt.global, tt.value)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Set() error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+
+			if got, _ := k.Get(f, "acme", tt.global); got != tt.value {
+				t.Errorf("Get() = %q, want %q", got, tt.value)
+			}
+		})
+	}
+}
+
+func TestKeyEffective(t *testing.T) {
+	t.Parallel()
+
+	k, err := LookupKey("timeout")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var f File
+
+	if v, src := k.Effective(f, "acme"); v != "30s" || src != "default" {
+		t.Errorf("Effective() = %q, %q; want default", v, src)
+	}
+
+	if err := k.Set(&f, "", true, "1m"); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, src := k.Effective(f, "acme"); v != "1m0s" || src != "global" {
+		t.Errorf("Effective() = %q, %q; want global 1m0s", v, src)
+	}
+
+	if err := k.Set(&f, "acme", false, "2m"); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, src := k.Effective(f, "acme"); v != "2m0s" || src != "profile" {
+		t.Errorf("Effective() = %q, %q; want profile 2m0s", v, src)
+	}
+
+	if got := f.SettingsFor("other").Timeout.String(); got != "1m0s" {
+		t.Errorf("SettingsFor(other).Timeout = %s, want 1m0s", got)
+	}
+
+	if err := k.Unset(&f, "acme", false); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, src := k.Effective(f, "acme"); src != "global" {
+		t.Errorf("Effective() source after Unset = %q, want global", src)
+	}
+
+	retries, err := LookupKey("max-retries")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := retries.Set(&f, "", true, "5"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := retries.Set(&f, "acme", false, "0"); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, src := retries.Effective(f, "acme"); v != "0" || src != "profile" {
+		t.Errorf("Effective(max-retries) = %q, %q; want profile 0", v, src)
+	}
+
+	if got := f.SettingsFor("acme").MaxRetries; got == nil || *got != 0 {
+		t.Errorf("SettingsFor(acme).MaxRetries = %v, want 0", got)
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{name: "empty", yaml: ""},
+		{name: "valid", yaml: "timeout: 60s\nprofiles:\n  acme:\n    output: csv\n    account_id: x\n"},
+		{name: "unknown key", yaml: "timout: 60s\n", wantErr: "field timout not found"},
+		{name: "invalid profile value", yaml: "profiles:\n  acme:\n    output: xml\n", wantErr: "profile acme: output"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ParseConfig([]byte(tt.yaml))
+
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("ParseConfig() error = %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("ParseConfig() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -57,6 +57,30 @@ func EnsureKeyringDir() (string, error) {
 	return dir, nil
 }
 
+// TokenCacheDir returns the access-token cache directory path.
+func TokenCacheDir() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "tokens"), nil
+}
+
+// EnsureTokenCacheDir creates the access-token cache directory if it doesn't exist.
+func EnsureTokenCacheDir() (string, error) {
+	dir, err := TokenCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("ensure token cache dir: %w", err)
+	}
+
+	return dir, nil
+}
+
 // ConfigPath returns the config file path.
 func ConfigPath() (string, error) {
 	dir, err := Dir()
@@ -66,3 +90,28 @@ func ConfigPath() (string, error) {
 
 	return filepath.Join(dir, "config.yaml"), nil
 }
+
+// StateDir returns the directory of long-running commands' state, such as
+// the transactions `ponto watch` has already reported.
+func StateDir() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "state"), nil
+}
+
+// EnsureStateDir creates the state directory if it doesn't exist.
+func EnsureStateDir() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("ensure state dir: %w", err)
+	}
+
+	return dir, nil
+}
@@ -12,6 +12,7 @@ const (
 	profileKey contextKey = "profile"
 	timeoutKey contextKey = "timeout"
 	noRetryKey contextKey = "noRetry"
+	baseURLKey contextKey = "baseURL"
 )
 
 // WithProfile adds the profile to the context.
@@ -55,3 +56,18 @@ func NoRetryFrom(ctx context.Context) bool {
 
 	return false
 }
+
+// WithBaseURL adds the API base URL to the context.
+func WithBaseURL(ctx context.Context, baseURL string) context.Context {
+	return context.WithValue(ctx, baseURLKey, baseURL)
+}
+
+// BaseURLFrom retrieves the API base URL from the context, or "" for the
+// default.
+func BaseURLFrom(ctx context.Context) string {
+	if v, ok := ctx.Value(baseURLKey).(string); ok {
+		return v
+	}
+
+	return ""
+}
@@ -0,0 +1,37 @@
+package output
+
+import "context"
+
+// AccountAlias is a named alias for an account.
+type AccountAlias struct {
+	Alias       string `json:"alias"`
+	AccountID   string `json:"accountId"`
+	IBAN        string `json:"iban,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// AccountAliases outputs account aliases.
+func AccountAliases(ctx context.Context, aliases []AccountAlias) error {
+	return renderList(ctx, aliases, accountAliasesLayout)
+}
+
+var accountAliasesLayout = layout{
+	table: []Column{
+		{Key: "alias", Title: "ALIAS"},
+		{Key: "accountId", Title: "ACCOUNT ID"},
+		{Key: "iban", Title: "IBAN"},
+		{Key: "description", Title: "NAME", Width: 30},
+	},
+	csv: []Column{
+		{Key: "alias", Header: "alias"},
+		{Key: "accountId", Header: "account_id"},
+		{Key: "iban", Header: "iban"},
+		{Key: "description", Header: "name"},
+	},
+	plain: []Column{
+		{Key: "alias"},
+		{Key: "accountId"},
+		{Key: "iban"},
+		{Key: "description"},
+	},
+}
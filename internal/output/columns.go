@@ -0,0 +1,545 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Column describes a single column of tabular output.
+type Column struct {
+	Key    string           // selector accepted by --columns and --sort
+	Header string           // CSV header
+	Title  string           // table header
+	Width  int              // table truncation width (0 = no truncation)
+	Date   bool             // value is an ISO 8601 date (typed cell in XLSX)
+	Value  func(any) string // computed value; nil reads the struct field named by Key
+}
+
+// layout holds the default columns of a list type per output mode, plus
+// computed columns that can be selected by key.
+type layout struct {
+	table   []Column
+	csv     []Column
+	plain   []Column
+	derived []Column
+}
+
+const (
+	columnsKey contextKey = "output_columns"
+	sortKey    contextKey = "output_sort"
+)
+
+// WithColumns adds the selected output columns to the context.
+func WithColumns(ctx context.Context, cols []string) context.Context {
+	return context.WithValue(ctx, columnsKey, cols)
+}
+
+// ColumnsFrom retrieves the selected output columns from the context.
+func ColumnsFrom(ctx context.Context) []string {
+	if v, ok := ctx.Value(columnsKey).([]string); ok {
+		return v
+	}
+
+	return nil
+}
+
+// WithSort adds the sort column to the context.
+func WithSort(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, sortKey, key)
+}
+
+// SortFrom retrieves the sort column from the context.
+func SortFrom(ctx context.Context) string {
+	if v, ok := ctx.Value(sortKey).(string); ok {
+		return v
+	}
+
+	return ""
+}
+
+// ParseColumns splits a comma-separated column list.
+func ParseColumns(value string) []string {
+	var cols []string
+
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			cols = append(cols, part)
+		}
+	}
+
+	return cols
+}
+
+// renderList writes items in the output mode from ctx, honouring the
+// column selection and sort order. Under a Fanout the items are collected
+// instead and written by Fanout.Render.
+func renderList[T any](ctx context.Context, items []T, l layout) error {
+	rows := make([]any, len(items))
+	for i := range items {
+		rows[i] = items[i]
+	}
+
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+
+	if t, ok := ctx.Value(fanoutKey).(fanoutTarget); ok {
+		return t.fanout.collect(ctx, t.profile, typ, rows, l)
+	}
+
+	return renderRows(ctx, typ, rows, l)
+}
+
+// renderRows writes rows of type typ in the output mode from ctx.
+func renderRows(ctx context.Context, typ reflect.Type, rows []any, l layout) error {
+	if key := SortFrom(ctx); key != "" {
+		if err := sortRows(typ, rows, key, l.derived); err != nil {
+			return err
+		}
+	}
+
+	cols, err := selectedColumns(ctx, typ, l.derived)
+	if err != nil {
+		return err
+	}
+
+	w := WriterFrom(ctx)
+
+	switch ModeFrom(ctx) {
+	case ModeJSON:
+		if cols == nil {
+			return JSON(w, rows)
+		}
+
+		return JSON(w, projectRows(rows, cols))
+	case ModeNDJSON:
+		if cols == nil {
+			return NDJSON(w, rows)
+		}
+
+		return NDJSON(w, projectRows(rows, cols))
+	case ModeTemplate:
+		return executeTemplate(ctx, rows...)
+	case ModeJQ:
+		if cols == nil {
+			return executeJQ(ctx, rows)
+		}
+
+		return executeJQ(ctx, projectRows(rows, cols))
+	case ModeCSV:
+		if cols == nil {
+			cols = l.csv
+		}
+
+		return writeCSV(w, cols, rows)
+	case ModePlain:
+		if cols == nil {
+			cols = l.plain
+		}
+
+		return writePlain(w, cols, rows)
+	default:
+		if cols == nil {
+			cols = l.table
+		}
+
+		return writeTable(w, cols, rows)
+	}
+}
+
+// selectedColumns resolves the --columns selection from ctx; it returns nil
+// when no columns were selected so callers can fall back to their defaults.
+func selectedColumns(ctx context.Context, typ reflect.Type, derived []Column) ([]Column, error) {
+	selected := ColumnsFrom(ctx)
+	if len(selected) == 0 {
+		return nil, nil
+	}
+
+	return resolveColumns(typ, selected, derived)
+}
+
+// sortedRows converts items to rows, sorted by the --sort column from ctx.
+func sortedRows[T any](ctx context.Context, items []T, derived []Column) ([]any, error) {
+	rows := make([]any, len(items))
+	for i := range items {
+		rows[i] = items[i]
+	}
+
+	if key := SortFrom(ctx); key != "" {
+		if err := sortRows(reflect.TypeOf((*T)(nil)).Elem(), rows, key, derived); err != nil {
+			return nil, err
+		}
+	}
+
+	return rows, nil
+}
+
+// writeStructured writes a single value in the JSON, NDJSON, template and jq modes
+// and reports whether the mode was handled.
+func writeStructured(ctx context.Context, v any) (bool, error) {
+	switch ModeFrom(ctx) {
+	case ModeJSON:
+		return true, JSON(WriterFrom(ctx), v)
+	case ModeNDJSON:
+		return true, JSONCompact(WriterFrom(ctx), v)
+	case ModeTemplate:
+		return true, executeTemplate(ctx, v)
+	case ModeJQ:
+		return true, executeJQ(ctx, v)
+	default:
+		return false, nil
+	}
+}
+
+func writeTable(w io.Writer, cols []Column, rows []any) error {
+	t := NewTable(w)
+
+	titles := make([]string, len(cols))
+	for i, c := range cols {
+		titles[i] = c.Title
+	}
+
+	t.Header(titles...)
+
+	for _, row := range rows {
+		values := make([]string, len(cols))
+		for i, c := range cols {
+			values[i] = c.value(row)
+			if c.Width > 0 {
+				values[i] = Truncate(values[i], c.Width)
+			}
+		}
+
+		t.Row(values...)
+	}
+
+	return t.Flush()
+}
+
+func writeCSV(w io.Writer, cols []Column, rows []any) error {
+	c := NewCSV(w)
+
+	headers := make([]string, len(cols))
+	for i, col := range cols {
+		headers[i] = col.Header
+	}
+
+	if err := c.Header(headers...); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		if err := c.Row(rowValues(cols, row)...); err != nil {
+			return err
+		}
+	}
+
+	return c.Flush()
+}
+
+func writePlain(w io.Writer, cols []Column, rows []any) error {
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(rowValues(cols, row), "\t"))
+	}
+
+	return nil
+}
+
+func rowValues(cols []Column, row any) []string {
+	values := make([]string, len(cols))
+	for i, c := range cols {
+		values[i] = c.value(row)
+	}
+
+	return values
+}
+
+// value returns the formatted column value for row.
+func (c Column) value(row any) string {
+	if c.Value != nil {
+		return c.Value(row)
+	}
+
+	v, ok := fieldValue(row, c.Key)
+	if !ok {
+		return ""
+	}
+
+	return formatValue(v)
+}
+
+// resolveColumns maps user-supplied keys to columns, checking computed
+// columns first and struct fields (by JSON name) second.
+func resolveColumns(typ reflect.Type, keys []string, derived []Column) ([]Column, error) {
+	cols := make([]Column, 0, len(keys))
+
+	for _, key := range keys {
+		col, ok := lookupColumn(typ, key, derived)
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q (available: %s)", key, strings.Join(availableColumns(typ, derived), ", "))
+		}
+
+		cols = append(cols, col)
+	}
+
+	return cols, nil
+}
+
+func lookupColumn(typ reflect.Type, key string, derived []Column) (Column, bool) {
+	for _, d := range derived {
+		if strings.EqualFold(d.Key, key) {
+			return Column{Key: d.Key, Header: d.Key, Title: strings.ToUpper(d.Key), Value: d.Value}, true
+		}
+	}
+
+	name, ok := jsonFieldName(typ, key)
+	if !ok {
+		return Column{}, false
+	}
+
+	return Column{Key: name, Header: name, Title: strings.ToUpper(name)}, true
+}
+
+func availableColumns(typ reflect.Type, derived []Column) []string {
+	var keys []string
+
+	for _, f := range jsonFields(typ) {
+		keys = append(keys, jsonName(f))
+	}
+
+	for _, d := range derived {
+		keys = append(keys, d.Key)
+	}
+
+	return keys
+}
+
+// jsonName returns the JSON name of an exported struct field, or "" when
+// the field is not serialised.
+func jsonName(f reflect.StructField) string {
+	if !f.IsExported() {
+		return ""
+	}
+
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = f.Name
+	}
+
+	return name
+}
+
+// jsonFields returns the serialised fields of typ, with the fields of
+// untagged embedded structs flattened in as encoding/json does.
+func jsonFields(typ reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+
+	for _, f := range reflect.VisibleFields(typ) {
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && f.Tag.Get("json") == "" {
+			continue
+		}
+
+		if jsonName(f) != "" {
+			fields = append(fields, f)
+		}
+	}
+
+	return fields
+}
+
+// jsonFieldName resolves key case-insensitively against the JSON names and
+// Go names of typ's fields.
+func jsonFieldName(typ reflect.Type, key string) (string, bool) {
+	for _, f := range jsonFields(typ) {
+		name := jsonName(f)
+
+		if strings.EqualFold(name, key) || strings.EqualFold(f.Name, key) {
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+func fieldValue(row any, key string) (reflect.Value, bool) {
+	if r, ok := row.(profileRow); ok {
+		row = r.row
+	}
+
+	v := reflect.ValueOf(row)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+
+	for _, f := range jsonFields(v.Type()) {
+		if jsonName(f) == key {
+			return v.FieldByIndex(f.Index), true
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+func formatValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Float32, reflect.Float64:
+		return formatAmount(v.Float())
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Slice, reflect.Map, reflect.Struct, reflect.Pointer:
+		if v.Kind() != reflect.Struct && v.IsNil() {
+			return ""
+		}
+
+		b, err := json.Marshal(v.Interface())
+		if err != nil {
+			return fmt.Sprint(v.Interface())
+		}
+
+		return string(b)
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// sortRows sorts rows in place by key; a leading "-" sorts descending.
+func sortRows(typ reflect.Type, rows []any, key string, derived []Column) error {
+	desc := strings.HasPrefix(key, "-")
+	key = strings.TrimPrefix(key, "-")
+
+	col, ok := lookupColumn(typ, key, derived)
+	if !ok {
+		return fmt.Errorf("unknown sort column %q (available: %s)", key, strings.Join(availableColumns(typ, derived), ", "))
+	}
+
+	less := func(a, b any) bool {
+		if col.Value == nil {
+			va, _ := fieldValue(a, col.Key)
+			vb, _ := fieldValue(b, col.Key)
+
+			if c, ok := compareNumeric(va, vb); ok {
+				return c < 0
+			}
+		}
+
+		return col.value(a) < col.value(b)
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if desc {
+			return less(rows[j], rows[i])
+		}
+
+		return less(rows[i], rows[j])
+	})
+
+	return nil
+}
+
+func compareNumeric(a, b reflect.Value) (int, bool) {
+	if !a.IsValid() || !b.IsValid() {
+		return 0, false
+	}
+
+	var x, y float64
+
+	switch a.Kind() {
+	case reflect.Float32, reflect.Float64:
+		x, y = a.Float(), b.Float()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, y = float64(a.Int()), float64(b.Int())
+	default:
+		return 0, false
+	}
+
+	switch {
+	case x < y:
+		return -1, true
+	case x > y:
+		return 1, true
+	default:
+		return 0, true
+	}
+}
+
+// projectRows reduces rows to the selected columns for JSON output.
+func projectRows(rows []any, cols []Column) []orderedRow {
+	out := make([]orderedRow, len(rows))
+
+	for i, row := range rows {
+		r := orderedRow{keys: make([]string, len(cols)), values: make([]any, len(cols))}
+
+		for j, c := range cols {
+			r.keys[j] = c.Key
+
+			if c.Value != nil {
+				r.values[j] = c.Value(row)
+			} else if v, ok := fieldValue(row, c.Key); ok {
+				r.values[j] = v.Interface()
+			}
+		}
+
+		out[i] = r
+	}
+
+	return out
+}
+
+// orderedRow marshals to a JSON object that preserves column order.
+type orderedRow struct {
+	keys   []string
+	values []any
+}
+
+func (r orderedRow) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+
+	for i, k := range r.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		kb, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+
+		vb, err := json.Marshal(r.values[i])
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(kb)
+		buf.WriteByte(':')
+		buf.Write(vb)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,169 @@
+package output
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/dedene/ponto-cli/internal/api"
+)
+
+func TestParseColumns(t *testing.T) {
+	t.Parallel()
+
+	got := ParseColumns(" id, valueDate,,amount ")
+	want := []string{"id", "valueDate", "amount"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseColumns() = %v, want %v", got, want)
+	}
+
+	if got := ParseColumns(""); got != nil {
+		t.Errorf("ParseColumns(\"\") = %v, want nil", got)
+	}
+}
+
+func TestResolveColumns(t *testing.T) {
+	t.Parallel()
+
+	typ := reflect.TypeOf(api.Transaction{})
+
+	tests := []struct {
+		name    string
+		keys    []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "json names",
+			keys: []string{"id", "valueDate", "endToEndId"},
+			want: []string{"id", "valueDate", "endToEndId"},
+		},
+		{
+			name: "case insensitive and go names",
+			keys: []string{"VALUEDATE", "CounterpartRef"},
+			want: []string{"valueDate", "counterpartReference"},
+		},
+		{
+			name: "derived column",
+			keys: []string{"communication"},
+			want: []string{"communication"},
+		},
+		{
+			name:    "unknown column",
+			keys:    []string{"nope"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cols, err := resolveColumns(typ, tt.keys, transactionsLayout.derived)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("resolveColumns(%v) expected error, got nil", tt.keys)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("resolveColumns(%v) unexpected error: %v", tt.keys, err)
+			}
+
+			got := make([]string, len(cols))
+			for i, c := range cols {
+				got[i] = c.Key
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolveColumns(%v) = %v, want %v", tt.keys, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmbeddedFields(t *testing.T) {
+	t.Parallel()
+
+	row := AccountTransaction{
+		Account:     "Main",
+		Transaction: api.Transaction{ID: "t1", Amount: -12.5},
+	}
+
+	cols, err := resolveColumns(reflect.TypeOf(row), []string{"account", "id", "amount"}, nil)
+	if err != nil {
+		t.Fatalf("resolveColumns() unexpected error: %v", err)
+	}
+
+	got := rowValues(cols, row)
+	want := []string{"Main", "t1", "-12.50"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rowValues() = %v, want %v", got, want)
+	}
+}
+
+func TestSortRows(t *testing.T) {
+	t.Parallel()
+
+	txns := []api.Transaction{
+		{ID: "a", Amount: 10, CounterpartName: "Beta"},
+		{ID: "b", Amount: -250, CounterpartName: "Alpha"},
+		{ID: "c", Amount: 9.5, CounterpartName: "Gamma"},
+	}
+
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{key: "amount", want: []string{"b", "c", "a"}},
+		{key: "-amount", want: []string{"a", "c", "b"}},
+		{key: "counterpartName", want: []string{"b", "a", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			t.Parallel()
+
+			rows := make([]any, len(txns))
+			for i := range txns {
+				rows[i] = txns[i]
+			}
+
+			if err := sortRows(reflect.TypeOf(api.Transaction{}), rows, tt.key, nil); err != nil {
+				t.Fatalf("sortRows(%q) unexpected error: %v", tt.key, err)
+			}
+
+			got := make([]string, len(rows))
+			for i, r := range rows {
+				got[i] = r.(api.Transaction).ID
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sortRows(%q) = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProjectRows(t *testing.T) {
+	t.Parallel()
+
+	cols, err := resolveColumns(reflect.TypeOf(api.Transaction{}), []string{"id", "amount"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := json.Marshal(projectRows([]any{api.Transaction{ID: "x", Amount: -1.5}}, cols))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `[{"id":"x","amount":-1.5}]`
+	if string(b) != want {
+		t.Errorf("projectRows() = %s, want %s", b, want)
+	}
+}
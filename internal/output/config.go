@@ -0,0 +1,38 @@
+package output
+
+import "context"
+
+// ConfigEntry is a config key with the value in effect for a profile.
+type ConfigEntry struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Source      string `json:"source"`
+	Scope       string `json:"scope"`
+	Description string `json:"description"`
+}
+
+// ConfigEntries outputs config keys and their values.
+func ConfigEntries(ctx context.Context, entries []ConfigEntry) error {
+	return renderList(ctx, entries, configEntriesLayout)
+}
+
+var configEntriesLayout = layout{
+	table: []Column{
+		{Key: "key", Title: "KEY"},
+		{Key: "value", Title: "VALUE", Width: 40},
+		{Key: "source", Title: "SOURCE"},
+		{Key: "description", Title: "DESCRIPTION"},
+	},
+	csv: []Column{
+		{Key: "key", Header: "key"},
+		{Key: "value", Header: "value"},
+		{Key: "source", Header: "source"},
+		{Key: "scope", Header: "scope"},
+		{Key: "description", Header: "description"},
+	},
+	plain: []Column{
+		{Key: "key"},
+		{Key: "value"},
+		{Key: "source"},
+	},
+}
@@ -3,7 +3,7 @@ package output
 import (
 	"encoding/csv"
 	"fmt"
-	"os"
+	"io"
 )
 
 // CSV provides RFC 4180 compliant CSV output.
@@ -11,10 +11,10 @@ type CSV struct {
 	w *csv.Writer
 }
 
-// NewCSV creates a new CSV writer.
-func NewCSV() *CSV {
+// NewCSV creates a new CSV writer on w.
+func NewCSV(w io.Writer) *CSV {
 	return &CSV{
-		w: csv.NewWriter(os.Stdout),
+		w: csv.NewWriter(w),
 	}
 }
 
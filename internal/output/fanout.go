@@ -0,0 +1,174 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+const fanoutKey contextKey = "output_fanout"
+
+// Fanout collects the list output of a command run for several profiles
+// and renders it as one list with a leading profile column.
+type Fanout struct {
+	mu       sync.Mutex
+	profiles []string
+	rows     map[string][]any
+	typ      reflect.Type
+	layout   layout
+	mode     Mode
+	streamed bool
+}
+
+type fanoutTarget struct {
+	fanout  *Fanout
+	profile string
+}
+
+// NewFanout creates a Fanout that renders rows in the order of profiles.
+func NewFanout(profiles []string) *Fanout {
+	return &Fanout{profiles: profiles, rows: make(map[string][]any)}
+}
+
+// WithProfile returns a context whose list output is collected for profile.
+func (f *Fanout) WithProfile(ctx context.Context, profile string) context.Context {
+	return context.WithValue(ctx, fanoutKey, fanoutTarget{fanout: f, profile: profile})
+}
+
+// Collecting reports whether list output in ctx is collected by a Fanout.
+func Collecting(ctx context.Context) bool {
+	_, ok := ctx.Value(fanoutKey).(fanoutTarget)
+
+	return ok
+}
+
+// collect records rows for profile. Streamable output is written right
+// away, one tagged line at a time.
+func (f *Fanout) collect(ctx context.Context, profile string, typ reflect.Type, rows []any, l layout) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.typ == nil {
+		f.typ, f.layout, f.mode = typ, l, ModeFrom(ctx)
+	} else if f.typ != typ {
+		return fmt.Errorf("cannot merge %s output with %s output", typ.Name(), f.typ.Name())
+	}
+
+	tagged := make([]any, len(rows))
+	for i, row := range rows {
+		tagged[i] = profileRow{profile: profile, row: row}
+	}
+
+	if Streams(ctx) && len(ColumnsFrom(ctx)) == 0 {
+		f.streamed = true
+
+		return NDJSON(WriterFrom(ctx), tagged)
+	}
+
+	f.rows[profile] = append(f.rows[profile], tagged...)
+
+	return nil
+}
+
+// Render writes the collected rows in the mode of the collected command,
+// grouped by profile unless a sort order is given.
+func (f *Fanout) Render(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.typ == nil || f.streamed {
+		return nil
+	}
+
+	var rows []any
+	for _, p := range f.profiles {
+		rows = append(rows, f.rows[p]...)
+	}
+
+	if cols := ColumnsFrom(ctx); len(cols) > 0 && !containsFold(cols, profileColumn.Key) {
+		ctx = WithColumns(ctx, append([]string{profileColumn.Key}, cols...))
+	}
+
+	return renderRows(WithMode(ctx, f.mode), f.typ, rows, f.layout.withProfile())
+}
+
+// profileRow tags a list row with the profile it was fetched for.
+type profileRow struct {
+	profile string
+	row     any
+}
+
+// MarshalJSON adds a leading "profile" field to the row's JSON object.
+func (r profileRow) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal(r.row)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := json.Marshal(r.profile)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) < 2 || b[0] != '{' {
+		return nil, fmt.Errorf("cannot add profile to %s", b)
+	}
+
+	var buf bytes.Buffer
+
+	buf.WriteString(`{"profile":`)
+	buf.Write(p)
+
+	if rest := b[1:]; rest[0] != '}' {
+		buf.WriteByte(',')
+		buf.Write(rest)
+	} else {
+		buf.WriteByte('}')
+	}
+
+	return buf.Bytes(), nil
+}
+
+var profileColumn = Column{Key: "profile", Value: func(v any) string {
+	return v.(profileRow).profile
+}}
+
+// withProfile adapts a layout to profileRow rows and adds the profile column.
+func (l layout) withProfile() layout {
+	return layout{
+		table:   append([]Column{withTitle(profileColumn, "PROFILE", 0)}, unwrapColumns(l.table)...),
+		csv:     append([]Column{withHeader(profileColumn, "profile")}, unwrapColumns(l.csv)...),
+		plain:   append([]Column{profileColumn}, unwrapColumns(l.plain)...),
+		derived: append([]Column{profileColumn}, unwrapColumns(l.derived)...),
+	}
+}
+
+// unwrapColumns makes computed columns read the row inside a profileRow;
+// field columns already do via fieldValue.
+func unwrapColumns(cols []Column) []Column {
+	out := make([]Column, len(cols))
+
+	for i, c := range cols {
+		if value := c.Value; value != nil {
+			c.Value = func(v any) string { return value(v.(profileRow).row) }
+		}
+
+		out[i] = c
+	}
+
+	return out
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+
+	return false
+}
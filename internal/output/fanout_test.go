@@ -0,0 +1,93 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/dedene/ponto-cli/internal/api"
+)
+
+func TestFanoutRender(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		ctx  func(context.Context) context.Context
+		want string
+	}{
+		{
+			name: "csv",
+			ctx: func(ctx context.Context) context.Context {
+				return WithColumns(WithMode(ctx, ModeCSV), []string{"id", "amount"})
+			},
+			want: "profile,id,amount\nacme,a1,-5.00\nbeta,b1,10.00\n",
+		},
+		{
+			name: "plain sorted",
+			ctx: func(ctx context.Context) context.Context {
+				return WithSort(WithColumns(WithMode(ctx, ModePlain), []string{"id", "profile"}), "-amount")
+			},
+			want: "b1\tbeta\na1\tacme\n",
+		},
+		{
+			name: "ndjson",
+			ctx: func(ctx context.Context) context.Context {
+				return WithColumns(WithMode(ctx, ModeNDJSON), []string{"id"})
+			},
+			want: "{\"profile\":\"acme\",\"id\":\"a1\"}\n{\"profile\":\"beta\",\"id\":\"b1\"}\n",
+		},
+		{
+			name: "template",
+			ctx: func(ctx context.Context) context.Context {
+				return WithTemplate(WithMode(ctx, ModeTemplate), "{{profile}} {{money .Amount}}")
+			},
+			want: "acme -5.00\nbeta 10.00\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			ctx := tt.ctx(WithWriter(context.Background(), &buf))
+			fan := NewFanout([]string{"acme", "beta"})
+
+			// Collect out of order; rows render in profile order.
+			if err := Transactions(fan.WithProfile(ctx, "beta"), []api.Transaction{{ID: "b1", Amount: 10}}); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := Transactions(fan.WithProfile(ctx, "acme"), []api.Transaction{{ID: "a1", Amount: -5}}); err != nil {
+				t.Fatal(err)
+			}
+
+			if buf.Len() != 0 {
+				t.Fatalf("output written before Render: %q", buf.String())
+			}
+
+			if err := fan.Render(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			if buf.String() != tt.want {
+				t.Errorf("Render() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestProfileRowJSON(t *testing.T) {
+	t.Parallel()
+
+	b, err := profileRow{profile: "acme", row: api.Account{ID: "x"}}.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.HasPrefix(b, []byte(`{"profile":"acme","id":"x"`)) {
+		t.Errorf("MarshalJSON() = %s", b)
+	}
+}
@@ -1,6 +1,7 @@
 package output
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -8,247 +9,376 @@ import (
 )
 
 // Accounts outputs a list of accounts.
-func Accounts(mode Mode, accounts []api.Account) error {
-	switch mode {
-	case ModeJSON:
-		return JSON(accounts)
-	case ModeCSV:
-		return accountsCSV(accounts)
-	case ModePlain:
-		return accountsPlain(accounts)
-	default:
-		return accountsTable(accounts)
-	}
+func Accounts(ctx context.Context, accounts []api.Account) error {
+	return renderList(ctx, accounts, accountsLayout)
 }
 
-func accountsTable(accounts []api.Account) error {
-	t := NewTable()
-	t.Header("ID", "NAME", "IBAN", "BALANCE", "CURRENCY")
-
-	for _, a := range accounts {
-		t.Row(a.ID, Truncate(a.Description, 30), a.Reference, formatAmount(a.CurrentBalance), a.Currency)
-	}
-
-	return t.Flush()
+var accountsLayout = layout{
+	table: []Column{
+		{Key: "id", Title: "ID"},
+		{Key: "description", Title: "NAME", Width: 30},
+		{Key: "reference", Title: "IBAN"},
+		{Key: "currentBalance", Title: "BALANCE"},
+		{Key: "currency", Title: "CURRENCY"},
+	},
+	csv: []Column{
+		{Key: "id", Header: "id"},
+		{Key: "description", Header: "name"},
+		{Key: "reference", Header: "iban"},
+		{Key: "currentBalance", Header: "balance"},
+		{Key: "currency", Header: "currency"},
+	},
+	plain: []Column{
+		{Key: "id"},
+		{Key: "description"},
+		{Key: "reference"},
+		{Key: "currentBalance"},
+		{Key: "currency"},
+	},
 }
 
-func accountsCSV(accounts []api.Account) error {
-	c := NewCSV()
-	if err := c.Header("id", "name", "iban", "balance", "currency"); err != nil {
+// Account outputs a single account.
+func Account(ctx context.Context, account *api.Account) error {
+	if ok, err := writeStructured(ctx, account); ok {
 		return err
 	}
 
-	for _, a := range accounts {
-		if err := c.Row(a.ID, a.Description, a.Reference, formatAmount(a.CurrentBalance), a.Currency); err != nil {
-			return err
-		}
-	}
+	w := WriterFrom(ctx)
 
-	return c.Flush()
-}
-
-func accountsPlain(accounts []api.Account) error {
-	for _, a := range accounts {
-		fmt.Printf("%s\t%s\t%s\t%s\t%s\n", a.ID, a.Description, a.Reference, formatAmount(a.CurrentBalance), a.Currency)
-	}
+	fmt.Fprintf(w, "ID:          %s\n", account.ID)
+	fmt.Fprintf(w, "Description: %s\n", account.Description)
+	fmt.Fprintf(w, "Reference:   %s\n", account.Reference)
+	fmt.Fprintf(w, "Product:     %s\n", account.Product)
+	fmt.Fprintf(w, "Balance:     %s %s\n", formatAmount(account.CurrentBalance), account.Currency)
+	fmt.Fprintf(w, "Available:   %s %s\n", formatAmount(account.AvailableBalance), account.Currency)
 
 	return nil
 }
 
-// Account outputs a single account.
-func Account(mode Mode, account *api.Account) error {
-	if mode == ModeJSON {
-		return JSON(account)
-	}
+// Transactions outputs a list of transactions.
+func Transactions(ctx context.Context, txns []api.Transaction) error {
+	return renderList(ctx, txns, transactionsLayout)
+}
 
-	fmt.Printf("ID:          %s\n", account.ID)
-	fmt.Printf("Description: %s\n", account.Description)
-	fmt.Printf("Reference:   %s\n", account.Reference)
-	fmt.Printf("Product:     %s\n", account.Product)
-	fmt.Printf("Balance:     %s %s\n", formatAmount(account.CurrentBalance), account.Currency)
-	fmt.Printf("Available:   %s %s\n", formatAmount(account.AvailableBalance), account.Currency)
+var (
+	txDate = Column{Key: "date", Date: true, Value: func(v any) string {
+		return formatDate(v.(api.Transaction).ExecutionDate)
+	}}
+	txCommunication = Column{Key: "communication", Value: func(v any) string {
+		return Communication(v.(api.Transaction))
+	}}
+)
 
-	return nil
+var transactionsLayout = layout{
+	table: []Column{
+		{Key: "id", Title: "ID"},
+		withTitle(txDate, "DATE", 0),
+		{Key: "counterpartName", Title: "COUNTERPART", Width: 25},
+		{Key: "counterpartReference", Title: "IBAN"},
+		withTitle(txCommunication, "COMMUNICATION", 40),
+		{Key: "amount", Title: "AMOUNT"},
+	},
+	csv: []Column{
+		{Key: "id", Header: "id"},
+		withHeader(txDate, "date"),
+		{Key: "counterpartName", Header: "counterpart_name"},
+		{Key: "counterpartReference", Header: "counterpart_iban"},
+		withHeader(txCommunication, "communication"),
+		{Key: "remittanceInformationType", Header: "remittance_type"},
+		{Key: "remittanceInformation", Header: "remittance_info"},
+		{Key: "amount", Header: "amount"},
+		{Key: "currency", Header: "currency"},
+	},
+	plain: []Column{
+		{Key: "id"},
+		txDate,
+		{Key: "counterpartName"},
+		{Key: "counterpartReference"},
+		txCommunication,
+		{Key: "amount"},
+	},
+	derived: []Column{txDate, txCommunication},
 }
 
-// Transactions outputs a list of transactions.
-func Transactions(mode Mode, txns []api.Transaction) error {
-	switch mode {
-	case ModeJSON:
-		return JSON(txns)
-	case ModeCSV:
-		return transactionsCSV(txns)
-	case ModePlain:
-		return transactionsPlain(txns)
-	default:
-		return transactionsTable(txns)
-	}
+// AccountTransaction is a transaction tagged with the account it was booked
+// on, for lists that span several accounts.
+type AccountTransaction struct {
+	AccountID string `json:"accountId"`
+	Account   string `json:"account"`
+	IBAN      string `json:"iban"`
+	api.Transaction
 }
 
-func transactionsTable(txns []api.Transaction) error {
-	t := NewTable()
-	t.Header("ID", "DATE", "COUNTERPART", "IBAN", "COMMUNICATION", "AMOUNT")
-
-	for _, tx := range txns {
-		comm := extractCommunication(tx.RemittanceInfo, tx.RemittanceInfoType, tx.CounterpartName)
-		t.Row(tx.ID, formatDate(tx.ExecutionDate), Truncate(tx.CounterpartName, 25), tx.CounterpartRef, Truncate(comm, 40), formatAmount(tx.Amount))
-	}
-
-	return t.Flush()
+// AccountTransactions outputs a list of transactions of several accounts.
+func AccountTransactions(ctx context.Context, txns []AccountTransaction) error {
+	return renderList(ctx, txns, accountTransactionsLayout)
 }
 
-func transactionsCSV(txns []api.Transaction) error {
-	c := NewCSV()
-	if err := c.Header("id", "date", "counterpart_name", "counterpart_iban", "communication", "remittance_type", "remittance_info", "amount", "currency"); err != nil {
-		return err
-	}
+var accountTransactionsLayout = layout{
+	table: []Column{
+		{Key: "id", Title: "ID"},
+		withTitle(onTransaction(txDate), "DATE", 0),
+		{Key: "account", Title: "ACCOUNT", Width: 20},
+		{Key: "counterpartName", Title: "COUNTERPART", Width: 25},
+		{Key: "counterpartReference", Title: "IBAN"},
+		withTitle(onTransaction(txCommunication), "COMMUNICATION", 40),
+		{Key: "amount", Title: "AMOUNT"},
+	},
+	csv: []Column{
+		{Key: "id", Header: "id"},
+		withHeader(onTransaction(txDate), "date"),
+		{Key: "account", Header: "account"},
+		{Key: "iban", Header: "iban"},
+		{Key: "counterpartName", Header: "counterpart_name"},
+		{Key: "counterpartReference", Header: "counterpart_iban"},
+		withHeader(onTransaction(txCommunication), "communication"),
+		{Key: "remittanceInformationType", Header: "remittance_type"},
+		{Key: "remittanceInformation", Header: "remittance_info"},
+		{Key: "amount", Header: "amount"},
+		{Key: "currency", Header: "currency"},
+	},
+	plain: []Column{
+		{Key: "id"},
+		onTransaction(txDate),
+		{Key: "account"},
+		{Key: "counterpartName"},
+		{Key: "counterpartReference"},
+		onTransaction(txCommunication),
+		{Key: "amount"},
+	},
+	derived: []Column{onTransaction(txDate), onTransaction(txCommunication)},
+}
 
-	for _, tx := range txns {
-		comm := extractCommunication(tx.RemittanceInfo, tx.RemittanceInfoType, tx.CounterpartName)
-		if err := c.Row(tx.ID, formatDate(tx.ExecutionDate), tx.CounterpartName, tx.CounterpartRef, comm, tx.RemittanceInfoType, tx.RemittanceInfo, formatAmount(tx.Amount), tx.Currency); err != nil {
-			return err
-		}
-	}
+// onTransaction adapts a computed transaction column to AccountTransaction rows.
+func onTransaction(c Column) Column {
+	value := c.Value
+	c.Value = func(v any) string { return value(v.(AccountTransaction).Transaction) }
 
-	return c.Flush()
+	return c
 }
 
-func transactionsPlain(txns []api.Transaction) error {
-	for _, tx := range txns {
-		comm := extractCommunication(tx.RemittanceInfo, tx.RemittanceInfoType, tx.CounterpartName)
-		fmt.Printf("%s\t%s\t%s\t%s\t%s\t%s\n", tx.ID, formatDate(tx.ExecutionDate), tx.CounterpartName, tx.CounterpartRef, comm, formatAmount(tx.Amount))
-	}
-
-	return nil
+// Communication returns the payment reference of tx, stripped of the
+// bank noise around unstructured remittance information.
+func Communication(tx api.Transaction) string {
+	return extractCommunication(tx.RemittanceInfo, tx.RemittanceInfoType, tx.CounterpartName)
 }
 
 // Transaction outputs a single transaction.
-func Transaction(mode Mode, tx *api.Transaction) error {
-	if mode == ModeJSON {
-		return JSON(tx)
+func Transaction(ctx context.Context, tx *api.Transaction) error {
+	if ok, err := writeStructured(ctx, tx); ok {
+		return err
 	}
 
-	comm := extractCommunication(tx.RemittanceInfo, tx.RemittanceInfoType, tx.CounterpartName)
+	w := WriterFrom(ctx)
 
-	fmt.Printf("ID:            %s\n", tx.ID)
-	fmt.Printf("Date:          %s\n", formatDate(tx.ExecutionDate))
-	fmt.Printf("Counterpart:   %s\n", tx.CounterpartName)
-	fmt.Printf("IBAN:          %s\n", tx.CounterpartRef)
-	fmt.Printf("Amount:        %s %s\n", formatAmount(tx.Amount), tx.Currency)
-	fmt.Printf("Communication: %s\n", comm)
+	comm := Communication(*tx)
+
+	fmt.Fprintf(w, "ID:            %s\n", tx.ID)
+	fmt.Fprintf(w, "Date:          %s\n", formatDate(tx.ExecutionDate))
+	fmt.Fprintf(w, "Counterpart:   %s\n", tx.CounterpartName)
+	fmt.Fprintf(w, "IBAN:          %s\n", tx.CounterpartRef)
+	fmt.Fprintf(w, "Amount:        %s %s\n", formatAmount(tx.Amount), tx.Currency)
+	fmt.Fprintf(w, "Communication: %s\n", comm)
 
 	if tx.RemittanceInfoType == "unstructured" && comm != tx.RemittanceInfo {
-		fmt.Printf("Full info:     %s\n", tx.RemittanceInfo)
+		fmt.Fprintf(w, "Full info:     %s\n", tx.RemittanceInfo)
 	}
 
 	return nil
 }
 
+// PendingWarning is shown above pending transactions in table output.
+const PendingWarning = "Warning: Pending transactions may change or disappear when booked."
+
 // PendingTransactions outputs a list of pending transactions.
-func PendingTransactions(mode Mode, txns []api.PendingTransaction) error {
-	switch mode {
-	case ModeJSON:
-		return JSON(txns)
-	default:
-		return pendingTransactionsTable(txns)
-	}
-}
+func PendingTransactions(ctx context.Context, txns []api.PendingTransaction) error {
+	if ModeFrom(ctx) == ModeTable && len(txns) > 0 && !Collecting(ctx) {
+		w := WriterFrom(ctx)
 
-func pendingTransactionsTable(txns []api.PendingTransaction) error {
-	if len(txns) > 0 {
-		fmt.Println("Warning: Pending transactions may change or disappear when booked.")
-		fmt.Println()
+		fmt.Fprintln(w, PendingWarning)
+		fmt.Fprintln(w)
 	}
 
-	t := NewTable()
-	t.Header("STATUS", "DATE", "COUNTERPART", "DESCRIPTION", "AMOUNT")
-
-	for _, tx := range txns {
-		t.Row("[PENDING]", formatDate(tx.ValueDate), Truncate(tx.CounterpartName, 25), Truncate(tx.Description, 35), formatAmount(tx.Amount))
-	}
+	return renderList(ctx, txns, pendingTransactionsLayout)
+}
 
-	return t.Flush()
+var pendingStatus = Column{Key: "status", Value: func(any) string { return "[PENDING]" }}
+
+var pendingTransactionsLayout = layout{
+	table: []Column{
+		withTitle(pendingStatus, "STATUS", 0),
+		{Key: "valueDate", Title: "DATE", Value: func(v any) string {
+			return formatDate(v.(api.PendingTransaction).ValueDate)
+		}},
+		{Key: "counterpartName", Title: "COUNTERPART", Width: 25},
+		{Key: "description", Title: "DESCRIPTION", Width: 35},
+		{Key: "amount", Title: "AMOUNT"},
+	},
+	csv: []Column{
+		{Key: "id", Header: "id"},
+		{Key: "valueDate", Header: "date"},
+		{Key: "counterpartName", Header: "counterpart_name"},
+		{Key: "counterpartReference", Header: "counterpart_iban"},
+		{Key: "description", Header: "description"},
+		{Key: "amount", Header: "amount"},
+		{Key: "currency", Header: "currency"},
+	},
+	plain: []Column{
+		{Key: "id"},
+		{Key: "valueDate"},
+		{Key: "counterpartName"},
+		{Key: "description"},
+		{Key: "amount"},
+	},
+	derived: []Column{pendingStatus},
 }
 
 // Sync outputs a sync.
-func Sync(mode Mode, sync *api.Synchronization) error {
-	if mode == ModeJSON {
-		return JSON(sync)
+func Sync(ctx context.Context, sync *api.Synchronization) error {
+	if ok, err := writeStructured(ctx, sync); ok {
+		return err
 	}
 
-	fmt.Printf("ID:      %s\n", sync.ID)
-	fmt.Printf("Status:  %s\n", sync.Status)
-	fmt.Printf("Subtype: %s\n", sync.Subtype)
+	w := WriterFrom(ctx)
+
+	fmt.Fprintf(w, "ID:      %s\n", sync.ID)
+	fmt.Fprintf(w, "Status:  %s\n", sync.Status)
+	fmt.Fprintf(w, "Subtype: %s\n", sync.Subtype)
 
 	if sync.UpdatedAt != "" {
-		fmt.Printf("Updated: %s\n", formatDate(sync.UpdatedAt))
+		fmt.Fprintf(w, "Updated: %s\n", formatDate(sync.UpdatedAt))
 	}
 
 	return nil
 }
 
 // Syncs outputs a list of syncs.
-func Syncs(mode Mode, syncs []api.Synchronization) error {
-	if mode == ModeJSON {
-		return JSON(syncs)
-	}
-
-	t := NewTable()
-	t.Header("ID", "STATUS", "SUBTYPE", "UPDATED")
-
-	for _, s := range syncs {
-		t.Row(s.ID, s.Status, s.Subtype, formatDate(s.UpdatedAt))
-	}
+func Syncs(ctx context.Context, syncs []api.Synchronization) error {
+	return renderList(ctx, syncs, syncsLayout)
+}
 
-	return t.Flush()
+var syncUpdated = Column{Key: "updated", Date: true, Value: func(v any) string {
+	return formatDate(v.(api.Synchronization).UpdatedAt)
+}}
+
+var syncsLayout = layout{
+	table: []Column{
+		{Key: "id", Title: "ID"},
+		{Key: "status", Title: "STATUS"},
+		{Key: "subtype", Title: "SUBTYPE"},
+		withTitle(syncUpdated, "UPDATED", 0),
+	},
+	csv: []Column{
+		{Key: "id", Header: "id"},
+		{Key: "status", Header: "status"},
+		{Key: "subtype", Header: "subtype"},
+		{Key: "updatedAt", Header: "updated"},
+	},
+	plain: []Column{
+		{Key: "id"},
+		{Key: "status"},
+		{Key: "subtype"},
+		{Key: "updatedAt"},
+	},
+	derived: []Column{syncUpdated},
 }
 
 // Organization outputs organization info.
-func Organization(mode Mode, org *api.Organization) error {
-	if mode == ModeJSON {
-		return JSON(org)
+func Organization(ctx context.Context, org *api.Organization) error {
+	if ok, err := writeStructured(ctx, org); ok {
+		return err
 	}
 
-	fmt.Printf("ID:   %s\n", org.ID)
-	fmt.Printf("Name: %s\n", org.Name)
+	w := WriterFrom(ctx)
+
+	fmt.Fprintf(w, "ID:   %s\n", org.ID)
+	fmt.Fprintf(w, "Name: %s\n", org.Name)
 
 	return nil
 }
 
 // FinancialInstitutions outputs a list of financial institutions.
-func FinancialInstitutions(mode Mode, institutions []api.FinancialInstitution) error {
-	if mode == ModeJSON {
-		return JSON(institutions)
-	}
+func FinancialInstitutions(ctx context.Context, institutions []api.FinancialInstitution) error {
+	return renderList(ctx, institutions, financialInstitutionsLayout)
+}
 
-	t := NewTable()
-	t.Header("ID", "NAME", "COUNTRY", "STATUS")
+var financialInstitutionsLayout = layout{
+	table: []Column{
+		{Key: "id", Title: "ID"},
+		{Key: "name", Title: "NAME", Width: 40},
+		{Key: "country", Title: "COUNTRY"},
+		{Key: "status", Title: "STATUS"},
+	},
+	csv: []Column{
+		{Key: "id", Header: "id"},
+		{Key: "name", Header: "name"},
+		{Key: "country", Header: "country"},
+		{Key: "status", Header: "status"},
+	},
+	plain: []Column{
+		{Key: "id"},
+		{Key: "name"},
+		{Key: "country"},
+		{Key: "status"},
+	},
+}
 
-	for _, fi := range institutions {
-		t.Row(fi.ID, Truncate(fi.Name, 40), fi.Country, fi.Status)
-	}
+// WebhookKeys outputs the keys Ponto signs webhooks with.
+func WebhookKeys(ctx context.Context, keys []api.WebhookKey) error {
+	return renderList(ctx, keys, webhookKeysLayout)
+}
 
-	return t.Flush()
+var webhookKeysLayout = layout{
+	table: []Column{
+		{Key: "id", Title: "KID"},
+		{Key: "alg", Title: "ALG"},
+		{Key: "status", Title: "STATUS"},
+	},
+	csv: []Column{
+		{Key: "id", Header: "id"},
+		{Key: "alg", Header: "alg"},
+		{Key: "status", Header: "status"},
+	},
+	plain: []Column{
+		{Key: "id"},
+		{Key: "alg"},
+		{Key: "status"},
+	},
 }
 
 // FinancialInstitution outputs a single financial institution.
-func FinancialInstitution(mode Mode, fi *api.FinancialInstitution) error {
-	if mode == ModeJSON {
-		return JSON(fi)
+func FinancialInstitution(ctx context.Context, fi *api.FinancialInstitution) error {
+	if ok, err := writeStructured(ctx, fi); ok {
+		return err
 	}
 
-	fmt.Printf("ID:      %s\n", fi.ID)
-	fmt.Printf("Name:    %s\n", fi.Name)
-	fmt.Printf("Country: %s\n", fi.Country)
-	fmt.Printf("Status:  %s\n", fi.Status)
+	w := WriterFrom(ctx)
+
+	fmt.Fprintf(w, "ID:      %s\n", fi.ID)
+	fmt.Fprintf(w, "Name:    %s\n", fi.Name)
+	fmt.Fprintf(w, "Country: %s\n", fi.Country)
+	fmt.Fprintf(w, "Status:  %s\n", fi.Status)
 
 	if fi.MaintenanceFrom != "" {
-		fmt.Printf("\n⚠ Maintenance: %s to %s\n", fi.MaintenanceFrom, fi.MaintenanceTo)
+		fmt.Fprintf(w, "\n⚠ Maintenance: %s to %s\n", fi.MaintenanceFrom, fi.MaintenanceTo)
 	}
 
 	return nil
 }
 
+func withTitle(c Column, title string, width int) Column {
+	c.Title = title
+	c.Width = width
+
+	return c
+}
+
+func withHeader(c Column, header string) Column {
+	c.Header = header
+
+	return c
+}
+
 func formatAmount(amount float64) string {
 	return fmt.Sprintf("%.2f", amount)
 }
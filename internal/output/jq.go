@@ -0,0 +1,109 @@
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/itchyny/gojq"
+)
+
+const jqKey contextKey = "output_jq"
+
+// WithJQ adds the jq expression used by ModeJQ to the context.
+func WithJQ(ctx context.Context, expr string) context.Context {
+	return context.WithValue(ctx, jqKey, expr)
+}
+
+// JQFrom retrieves the jq expression from the context.
+func JQFrom(ctx context.Context) string {
+	if v, ok := ctx.Value(jqKey).(string); ok {
+		return v
+	}
+
+	return ""
+}
+
+// ParseJQ parses a --jq expression.
+func ParseJQ(expr string) (*gojq.Code, error) {
+	query, err := gojq.Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("parse jq: %w", err)
+	}
+
+	code, err := gojq.Compile(query)
+	if err != nil {
+		return nil, fmt.Errorf("compile jq: %w", err)
+	}
+
+	return code, nil
+}
+
+// executeJQ runs the jq expression from ctx against the JSON form of v.
+func executeJQ(ctx context.Context, v any) error {
+	code, err := ParseJQ(JQFrom(ctx))
+	if err != nil {
+		return err
+	}
+
+	return writeJQ(ctx, WriterFrom(ctx), code, v)
+}
+
+// writeJQ writes each result on its own line. Strings are written raw, like
+// `jq -r`; everything else is written as JSON.
+func writeJQ(ctx context.Context, w io.Writer, code *gojq.Code, v any) error {
+	input, err := toJQValue(v)
+	if err != nil {
+		return err
+	}
+
+	iter := code.RunWithContext(ctx, input)
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	for {
+		result, ok := iter.Next()
+		if !ok {
+			return nil
+		}
+
+		if err, isErr := result.(error); isErr {
+			var halt *gojq.HaltError
+			if errors.As(err, &halt) && halt.Value() == nil {
+				return nil
+			}
+
+			return fmt.Errorf("jq: %w", err)
+		}
+
+		if s, isString := result.(string); isString {
+			if _, err := fmt.Fprintln(w, s); err != nil {
+				return fmt.Errorf("write jq output: %w", err)
+			}
+
+			continue
+		}
+
+		if err := enc.Encode(result); err != nil {
+			return fmt.Errorf("encode jq result: %w", err)
+		}
+	}
+}
+
+// toJQValue converts v to the plain maps, slices and scalars gojq expects.
+func toJQValue(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encode json: %w", err)
+	}
+
+	var out any
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, fmt.Errorf("decode json: %w", err)
+	}
+
+	return out, nil
+}
@@ -3,12 +3,12 @@ package output
 import (
 	"encoding/json"
 	"fmt"
-	"os"
+	"io"
 )
 
 // JSON outputs data as JSON.
-func JSON(v any) error {
-	enc := json.NewEncoder(os.Stdout)
+func JSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
 	enc.SetIndent("", "  ")
 
 	if err := enc.Encode(v); err != nil {
@@ -19,8 +19,8 @@ func JSON(v any) error {
 }
 
 // JSONCompact outputs data as compact JSON.
-func JSONCompact(v any) error {
-	enc := json.NewEncoder(os.Stdout)
+func JSONCompact(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
 
 	if err := enc.Encode(v); err != nil {
 		return fmt.Errorf("encode json: %w", err)
@@ -28,3 +28,16 @@ func JSONCompact(v any) error {
 
 	return nil
 }
+
+// NDJSON outputs each item as a compact JSON object on its own line.
+func NDJSON[T any](w io.Writer, items []T) error {
+	enc := json.NewEncoder(w)
+
+	for _, item := range items {
+		if err := enc.Encode(item); err != nil {
+			return fmt.Errorf("encode json: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -10,6 +10,9 @@ const (
 	ModeJSON
 	ModeCSV
 	ModePlain
+	ModeTemplate
+	ModeJQ
+	ModeNDJSON
 )
 
 type contextKey string
@@ -29,3 +32,9 @@ func ModeFrom(ctx context.Context) Mode {
 
 	return ModeTable
 }
+
+// Streams reports whether list output can be written page by page as it is
+// fetched. Only NDJSON streams, and only when no sort order is requested.
+func Streams(ctx context.Context) bool {
+	return ModeFrom(ctx) == ModeNDJSON && SortFrom(ctx) == ""
+}
@@ -0,0 +1,60 @@
+package output
+
+import "context"
+
+// ProfileInfo summarises a configured profile.
+type ProfileInfo struct {
+	Name          string `json:"name"`
+	Default       bool   `json:"default"`
+	Authenticated bool   `json:"authenticated"`
+	Connect       bool   `json:"connect"`
+	AccountID     string `json:"accountId"`
+}
+
+// Profiles outputs a list of profiles.
+func Profiles(ctx context.Context, profiles []ProfileInfo) error {
+	return renderList(ctx, profiles, profilesLayout)
+}
+
+func profileMarker(v any) string {
+	if v.(ProfileInfo).Default {
+		return "*"
+	}
+
+	return ""
+}
+
+func profileAuth(v any) string {
+	p := v.(ProfileInfo)
+
+	switch {
+	case !p.Authenticated:
+		return "no"
+	case p.Connect:
+		return "yes (connect)"
+	default:
+		return "yes"
+	}
+}
+
+var profilesLayout = layout{
+	table: []Column{
+		{Key: "default", Title: "DEFAULT", Value: profileMarker},
+		{Key: "name", Title: "NAME"},
+		{Key: "authenticated", Title: "AUTHENTICATED", Value: profileAuth},
+		{Key: "accountId", Title: "DEFAULT ACCOUNT"},
+	},
+	csv: []Column{
+		{Key: "name", Header: "name"},
+		{Key: "default", Header: "default"},
+		{Key: "authenticated", Header: "authenticated"},
+		{Key: "connect", Header: "connect"},
+		{Key: "accountId", Header: "account_id"},
+	},
+	plain: []Column{
+		{Key: "name"},
+		{Key: "default"},
+		{Key: "authenticated"},
+		{Key: "accountId"},
+	},
+}
@@ -0,0 +1,191 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AuthStatus is the result of an authentication check.
+type AuthStatus struct {
+	Profile         string           `json:"profile"`
+	Authenticated   bool             `json:"authenticated"`
+	Backend         string           `json:"backend,omitempty"`
+	ClientID        string           `json:"client_id,omitempty"` // masked
+	ClientSecret    string           `json:"-"`                   // masked, text output only
+	SecretRotatedAt *time.Time       `json:"secret_rotated_at,omitempty"`
+	Verified        bool             `json:"verified"`
+	Scopes          []string         `json:"scopes,omitempty"`
+	TokenExpiresAt  *time.Time       `json:"token_expires_at,omitempty"`
+	Organization    string           `json:"organization,omitempty"`
+	Accounts        []AccountConsent `json:"accounts,omitempty"`
+	Warnings        []string         `json:"warnings,omitempty"`
+	Problems        []string         `json:"problems,omitempty"`
+	// StoreFailed is set when the credential store failed, as opposed to
+	// having no credentials for the profile.
+	StoreFailed bool `json:"store_failed,omitempty"`
+}
+
+// AccountConsent describes the consent (authorization) state of an account.
+type AccountConsent struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	Reference   string `json:"reference"`
+	ExpiresAt   string `json:"expires_at,omitempty"`
+	DaysLeft    *int   `json:"days_left,omitempty"`
+	Status      string `json:"status"` // ok, expiring, expired or unknown
+}
+
+// Healthy reports whether the check found no problems.
+func (s *AuthStatus) Healthy() bool {
+	return s.Authenticated && len(s.Problems) == 0
+}
+
+// Status outputs an authentication check.
+func Status(ctx context.Context, status *AuthStatus) error {
+	if ok, err := writeStructured(ctx, status); ok {
+		return err
+	}
+
+	w := WriterFrom(ctx)
+	fields := status.fields()
+
+	switch ModeFrom(ctx) {
+	case ModeCSV:
+		c := NewCSV(w)
+
+		if err := c.Header("field", "value"); err != nil {
+			return err
+		}
+
+		for _, f := range fields {
+			if err := c.Row(f[0], f[1]); err != nil {
+				return err
+			}
+		}
+
+		return c.Flush()
+	case ModePlain:
+		for _, f := range fields {
+			fmt.Fprintf(w, "%s\t%s\n", f[0], f[1])
+		}
+
+		return nil
+	}
+
+	for _, f := range fields {
+		if strings.HasPrefix(f[0], "account:") || f[0] == "warning" || f[0] == "problem" {
+			continue
+		}
+
+		fmt.Fprintf(w, "%-16s%s\n", statusLabels[f[0]]+":", f[1])
+	}
+
+	if len(status.Accounts) > 0 {
+		fmt.Fprintln(w)
+
+		if err := writeTable(w, consentColumns, consentRows(status.Accounts)); err != nil {
+			return err
+		}
+	}
+
+	for _, warning := range status.Warnings {
+		fmt.Fprintf(w, "Warning: %s\n", warning)
+	}
+
+	for _, problem := range status.Problems {
+		fmt.Fprintf(w, "Problem: %s\n", problem)
+	}
+
+	if !status.Authenticated && !status.StoreFailed {
+		fmt.Fprintln(w, "Run 'ponto auth login' to authenticate.")
+	}
+
+	return nil
+}
+
+var statusLabels = map[string]string{
+	"profile":           "Profile",
+	"status":            "Status",
+	"backend":           "Backend",
+	"client_id":         "Client ID",
+	"client_secret":     "Client Secret",
+	"secret_rotated_at": "Secret Rotated",
+	"scopes":            "Scopes",
+	"token_expires_at":  "Token Expires",
+	"organization":      "Organization",
+}
+
+var consentColumns = []Column{
+	{Key: "id", Title: "ID"},
+	{Key: "description", Title: "NAME", Width: 30},
+	{Key: "reference", Title: "IBAN"},
+	{Key: "expires_at", Title: "CONSENT EXPIRES"},
+	{Key: "status", Title: "STATUS"},
+}
+
+func consentRows(accounts []AccountConsent) []any {
+	rows := make([]any, len(accounts))
+	for i := range accounts {
+		rows[i] = accounts[i]
+	}
+
+	return rows
+}
+
+// fields flattens the status into ordered key/value pairs for the text modes.
+func (s *AuthStatus) fields() [][2]string {
+	state := "not authenticated"
+
+	switch {
+	case s.StoreFailed:
+		state = "unknown (credential store failed)"
+	case s.Authenticated && s.Verified && s.Healthy():
+		state = "authenticated (verified)"
+	case s.Authenticated && s.Verified:
+		state = "authenticated (verification failed)"
+	case s.Authenticated:
+		state = "authenticated"
+	}
+
+	fields := [][2]string{{"profile", s.Profile}, {"status", state}}
+
+	add := func(key, value string) {
+		if value != "" {
+			fields = append(fields, [2]string{key, value})
+		}
+	}
+
+	add("backend", s.Backend)
+	add("client_id", s.ClientID)
+	add("client_secret", s.ClientSecret)
+
+	if s.SecretRotatedAt != nil {
+		add("secret_rotated_at", fmt.Sprintf("%s (%s days ago)",
+			s.SecretRotatedAt.Local().Format("2006-01-02"), strconv.Itoa(int(time.Since(*s.SecretRotatedAt).Hours()/24))))
+	}
+
+	add("scopes", strings.Join(s.Scopes, " "))
+
+	if s.TokenExpiresAt != nil {
+		add("token_expires_at", s.TokenExpiresAt.Local().Format("2006-01-02 15:04:05"))
+	}
+
+	add("organization", s.Organization)
+
+	for _, a := range s.Accounts {
+		add("account:"+a.ID, strings.TrimSpace(a.Status+" "+a.ExpiresAt))
+	}
+
+	for _, warning := range s.Warnings {
+		add("warning", warning)
+	}
+
+	for _, problem := range s.Problems {
+		add("problem", problem)
+	}
+
+	return fields
+}
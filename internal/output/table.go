@@ -2,7 +2,7 @@ package output
 
 import (
 	"fmt"
-	"os"
+	"io"
 	"text/tabwriter"
 	"unicode/utf8"
 )
@@ -12,10 +12,10 @@ type Table struct {
 	w *tabwriter.Writer
 }
 
-// NewTable creates a new table writer.
-func NewTable() *Table {
+// NewTable creates a new table writer on w.
+func NewTable(w io.Writer) *Table {
 	return &Table{
-		w: tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0),
+		w: tabwriter.NewWriter(w, 0, 0, 2, ' ', 0),
 	}
 }
 
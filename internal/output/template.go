@@ -0,0 +1,117 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+)
+
+const templateKey contextKey = "output_template"
+
+// WithTemplate adds the Go template used by ModeTemplate to the context.
+func WithTemplate(ctx context.Context, tmpl string) context.Context {
+	return context.WithValue(ctx, templateKey, tmpl)
+}
+
+// TemplateFrom retrieves the Go template from the context.
+func TemplateFrom(ctx context.Context) string {
+	if v, ok := ctx.Value(templateKey).(string); ok {
+		return v
+	}
+
+	return ""
+}
+
+// templateFuncs are the helpers available to --template.
+var templateFuncs = template.FuncMap{
+	"money":    templateMoney,
+	"date":     formatDate,
+	"pad":      templatePad,
+	"truncate": func(n int, s string) string { return Truncate(s, n) },
+	"upper":    strings.ToUpper,
+	"lower":    strings.ToLower,
+	"profile":  func() string { return "" },
+}
+
+// ParseTemplate parses a --template value with the output helpers.
+func ParseTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("output").Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse template: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// executeTemplate renders each item with the template from ctx, one per line.
+func executeTemplate(ctx context.Context, items ...any) error {
+	tmpl, err := ParseTemplate(TemplateFrom(ctx))
+	if err != nil {
+		return err
+	}
+
+	return writeTemplate(WriterFrom(ctx), tmpl, items)
+}
+
+func writeTemplate(w io.Writer, tmpl *template.Template, items []any) error {
+	for _, item := range items {
+		// Multi-profile rows expose their profile through the profile helper.
+		if r, ok := item.(profileRow); ok {
+			tmpl.Funcs(template.FuncMap{"profile": func() string { return r.profile }})
+			item = r.row
+		}
+
+		var b strings.Builder
+		if err := tmpl.Execute(&b, item); err != nil {
+			return fmt.Errorf("execute template: %w", err)
+		}
+
+		out := b.String()
+		if !strings.HasSuffix(out, "\n") {
+			out += "\n"
+		}
+
+		if _, err := io.WriteString(w, out); err != nil {
+			return fmt.Errorf("write template output: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func templateMoney(v any) string {
+	switch n := v.(type) {
+	case float64:
+		return formatAmount(n)
+	case float32:
+		return formatAmount(float64(n))
+	case int:
+		return formatAmount(float64(n))
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// templatePad pads s with spaces to width runes; a negative width right-aligns.
+func templatePad(width int, v any) string {
+	s := fmt.Sprint(v)
+
+	right := width < 0
+	if right {
+		width = -width
+	}
+
+	n := width - utf8.RuneCountInString(s)
+	if n <= 0 {
+		return s
+	}
+
+	if right {
+		return strings.Repeat(" ", n) + s
+	}
+
+	return s + strings.Repeat(" ", n)
+}
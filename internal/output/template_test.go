@@ -0,0 +1,61 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/dedene/ponto-cli/internal/api"
+)
+
+func TestWriteTemplate(t *testing.T) {
+	t.Parallel()
+
+	tmpl, err := ParseTemplate(`{{date .ValueDate}} {{money .Amount | pad -8}} {{.CounterpartName | truncate 6}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items := []any{
+		api.Transaction{ValueDate: "2024-03-01T00:00:00Z", Amount: -12.5, CounterpartName: "Acme Corporation"},
+		api.Transaction{ValueDate: "2024-03-02", Amount: 1000, CounterpartName: "Bob"},
+	}
+
+	var buf bytes.Buffer
+	if err := writeTemplate(&buf, tmpl, items); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "2024-03-01   -12.50 Acm...\n2024-03-02  1000.00 Bob\n"
+	if buf.String() != want {
+		t.Errorf("writeTemplate() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestParseTemplateError(t *testing.T) {
+	t.Parallel()
+
+	if _, err := ParseTemplate("{{.Amount"); err == nil {
+		t.Error("ParseTemplate() expected error for unclosed action")
+	}
+}
+
+func TestWriteJQ(t *testing.T) {
+	t.Parallel()
+
+	code, err := ParseJQ(`.[] | select(.amount < 0) | .id`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txns := []api.Transaction{{ID: "in", Amount: 5}, {ID: "out", Amount: -5}}
+
+	var buf bytes.Buffer
+	if err := writeJQ(context.Background(), &buf, code, txns); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != "out\n" {
+		t.Errorf("writeJQ() = %q, want %q", buf.String(), "out\n")
+	}
+}
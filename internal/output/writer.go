@@ -0,0 +1,132 @@
+package output
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+const writerKey contextKey = "output_writer"
+
+// WithWriter sets the destination for all formatters.
+func WithWriter(ctx context.Context, w io.Writer) context.Context {
+	return context.WithValue(ctx, writerKey, w)
+}
+
+// WriterFrom retrieves the output destination from the context.
+func WriterFrom(ctx context.Context) io.Writer {
+	if v, ok := ctx.Value(writerKey).(io.Writer); ok && v != nil {
+		return v
+	}
+
+	return os.Stdout
+}
+
+// AtomicFile writes to a temporary file next to its destination and only
+// replaces the destination on Commit, so readers never see partial output.
+type AtomicFile struct {
+	f    *os.File
+	path string
+	done bool
+}
+
+// CreateAtomic creates an AtomicFile for path, creating parent directories
+// as needed. The file gets the mode of the file it replaces, or 0666 less
+// the umask when it is new, like a file created in place.
+func CreateAtomic(path string) (*AtomicFile, error) {
+	dir := filepath.Dir(path)
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create output dir: %w", err)
+	}
+
+	f, err := createTemp(dir, "."+filepath.Base(path)+".", ".tmp")
+	if err != nil {
+		return nil, fmt.Errorf("create %s: %w", path, err)
+	}
+
+	if fi, err := os.Stat(path); err == nil {
+		if err := f.Chmod(fi.Mode().Perm()); err != nil {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+
+			return nil, fmt.Errorf("create %s: %w", path, err)
+		}
+	}
+
+	return &AtomicFile{f: f, path: path}, nil
+}
+
+// createTemp is os.CreateTemp with mode 0666, so the umask applies instead
+// of the owner-only mode os.CreateTemp uses.
+func createTemp(dir, prefix, suffix string) (*os.File, error) {
+	for range 100 {
+		name := filepath.Join(dir, prefix+strconv.FormatUint(uint64(rand.Uint32()), 10)+suffix)
+
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o666)
+		if os.IsExist(err) {
+			continue
+		}
+
+		return f, err
+	}
+
+	return nil, errors.New("no unused temporary file name")
+}
+
+// Write implements io.Writer.
+func (a *AtomicFile) Write(p []byte) (int, error) {
+	return a.f.Write(p)
+}
+
+// Path returns the destination path.
+func (a *AtomicFile) Path() string {
+	return a.path
+}
+
+// Commit flushes the temporary file and renames it over the destination.
+func (a *AtomicFile) Commit() error {
+	if a.done {
+		return nil
+	}
+
+	a.done = true
+
+	if err := a.f.Sync(); err != nil {
+		_ = a.f.Close()
+		_ = os.Remove(a.f.Name())
+
+		return fmt.Errorf("sync %s: %w", a.path, err)
+	}
+
+	if err := a.f.Close(); err != nil {
+		_ = os.Remove(a.f.Name())
+
+		return fmt.Errorf("close %s: %w", a.path, err)
+	}
+
+	if err := os.Rename(a.f.Name(), a.path); err != nil {
+		_ = os.Remove(a.f.Name())
+
+		return fmt.Errorf("commit %s: %w", a.path, err)
+	}
+
+	return nil
+}
+
+// Abort discards the temporary file. It is a no-op after Commit.
+func (a *AtomicFile) Abort() {
+	if a.done {
+		return
+	}
+
+	a.done = true
+
+	_ = a.f.Close()
+	_ = os.Remove(a.f.Name())
+}
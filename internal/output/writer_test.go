@@ -0,0 +1,106 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAtomicFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nested", "out.csv")
+
+	f, err := CreateAtomic(path)
+	if err != nil {
+		t.Fatalf("CreateAtomic() error = %v", err)
+	}
+
+	if _, err := f.Write([]byte("a,b\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("destination exists before Commit (err = %v)", err)
+	}
+
+	if err := f.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(b) != "a,b\n" {
+		t.Errorf("content = %q, want %q", b, "a,b\n")
+	}
+
+	aborted, err := CreateAtomic(filepath.Join(dir, "aborted.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	aborted.Abort()
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("dir has %d entries after Abort, want 1 (temp files left behind)", len(entries))
+	}
+}
+
+func TestAtomicFileMode(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.csv")
+
+	if err := os.WriteFile(existing, []byte("old\n"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Chmod(existing, 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	probe := filepath.Join(dir, "probe")
+	if err := os.WriteFile(probe, nil, 0o666); err != nil {
+		t.Fatal(err)
+	}
+
+	probeInfo, err := os.Stat(probe)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want os.FileMode
+	}{
+		{name: "new file gets 0666 less the umask", path: filepath.Join(dir, "new.csv"), want: probeInfo.Mode().Perm()},
+		{name: "replaced file keeps its mode", path: existing, want: 0o640},
+	}
+
+	for _, tt := range tests {
+		f, err := CreateAtomic(tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := f.Commit(); err != nil {
+			t.Fatal(err)
+		}
+
+		fi, err := os.Stat(tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := fi.Mode().Perm(); got != tt.want {
+			t.Errorf("%s: mode = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,420 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/dedene/ponto-cli/internal/api"
+)
+
+const (
+	xlsxSummarySheet  = "Summary"
+	xlsxBalancesSheet = "Balances"
+	xlsxMaxSheetName  = 31
+	xlsxDateFormat    = "yyyy-mm-dd"
+)
+
+// TransactionSheet groups the transactions of one account for XLSX export.
+type TransactionSheet struct {
+	Account      api.Account
+	Transactions []api.Transaction
+}
+
+// TransactionsXLSX writes a workbook with one sheet per account, using the
+// same columns as the CSV export, followed by a summary sheet of totals.
+func TransactionsXLSX(ctx context.Context, w io.Writer, sheets []TransactionSheet) error {
+	cols, err := selectedColumns(ctx, reflect.TypeOf(api.Transaction{}), transactionsLayout.derived)
+	if err != nil {
+		return err
+	}
+
+	if cols == nil {
+		cols = transactionsLayout.csv
+	}
+
+	x := newWorkbook()
+	defer x.f.Close()
+
+	x.uniqueSheetName(xlsxSummarySheet)
+
+	names := make([]string, len(sheets))
+
+	for i, sheet := range sheets {
+		rows, err := sortedRows(ctx, sheet.Transactions, transactionsLayout.derived)
+		if err != nil {
+			return err
+		}
+
+		names[i] = x.uniqueSheetName(accountSheetName(sheet.Account))
+
+		if err := x.writeSheet(names[i], cols, rows, sheet.Account.Currency); err != nil {
+			return err
+		}
+	}
+
+	if err := x.writeSummary(sheets, names); err != nil {
+		return err
+	}
+
+	return x.finish(w)
+}
+
+// AccountsXLSX writes a workbook with a single balances sheet.
+func AccountsXLSX(ctx context.Context, w io.Writer, accounts []api.Account) error {
+	cols, err := selectedColumns(ctx, reflect.TypeOf(api.Account{}), accountsLayout.derived)
+	if err != nil {
+		return err
+	}
+
+	if cols == nil {
+		cols = []Column{
+			{Key: "description", Header: "name"},
+			{Key: "reference", Header: "iban"},
+			{Key: "product", Header: "product"},
+			{Key: "currency", Header: "currency"},
+			{Key: "currentBalance", Header: "current_balance"},
+			{Key: "availableBalance", Header: "available_balance"},
+			{Key: "id", Header: "id"},
+		}
+	}
+
+	rows, err := sortedRows(ctx, accounts, accountsLayout.derived)
+	if err != nil {
+		return err
+	}
+
+	x := newWorkbook()
+	defer x.f.Close()
+
+	x.uniqueSheetName(xlsxBalancesSheet)
+
+	if err := x.writeSheet(xlsxBalancesSheet, cols, rows, ""); err != nil {
+		return err
+	}
+
+	return x.finish(w)
+}
+
+// workbook wraps an excelize file with the shared header and cell styles.
+type workbook struct {
+	f      *excelize.File
+	used   map[string]bool
+	header int
+	date   int
+	money  map[string]int
+}
+
+func newWorkbook() *workbook {
+	return &workbook{
+		f:     excelize.NewFile(),
+		used:  map[string]bool{},
+		money: map[string]int{},
+	}
+}
+
+func (x *workbook) headerStyle() (int, error) {
+	if x.header != 0 {
+		return x.header, nil
+	}
+
+	id, err := x.f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"DDE4EE"}},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("create header style: %w", err)
+	}
+
+	x.header = id
+
+	return id, nil
+}
+
+func (x *workbook) dateStyle() (int, error) {
+	if x.date != 0 {
+		return x.date, nil
+	}
+
+	format := xlsxDateFormat
+
+	id, err := x.f.NewStyle(&excelize.Style{CustomNumFmt: &format})
+	if err != nil {
+		return 0, fmt.Errorf("create date style: %w", err)
+	}
+
+	x.date = id
+
+	return id, nil
+}
+
+func (x *workbook) moneyStyle(currency string) (int, error) {
+	if id, ok := x.money[currency]; ok {
+		return id, nil
+	}
+
+	format := "#,##0.00"
+	if currency != "" {
+		format += ` "` + currency + `"`
+	}
+
+	id, err := x.f.NewStyle(&excelize.Style{CustomNumFmt: &format})
+	if err != nil {
+		return 0, fmt.Errorf("create currency style: %w", err)
+	}
+
+	x.money[currency] = id
+
+	return id, nil
+}
+
+// uniqueSheetName reserves a valid, unused sheet name derived from name.
+func (x *workbook) uniqueSheetName(name string) string {
+	name = sanitizeSheetName(name)
+	candidate := name
+
+	for i := 2; x.used[strings.ToLower(candidate)]; i++ {
+		suffix := fmt.Sprintf(" (%d)", i)
+		candidate = Truncate(name, xlsxMaxSheetName-len(suffix)) + suffix
+	}
+
+	x.used[strings.ToLower(candidate)] = true
+
+	return candidate
+}
+
+// writeSheet writes a header row and one typed row per item, then freezes
+// the header and enables an autofilter over the data.
+func (x *workbook) writeSheet(name string, cols []Column, rows []any, currency string) error {
+	if err := x.addSheet(name); err != nil {
+		return err
+	}
+
+	headers := make([]any, len(cols))
+	for i, c := range cols {
+		headers[i] = c.Header
+	}
+
+	if err := x.f.SetSheetRow(name, "A1", &headers); err != nil {
+		return fmt.Errorf("write header: %w", err)
+	}
+
+	headerStyle, err := x.headerStyle()
+	if err != nil {
+		return err
+	}
+
+	lastCol, _ := excelize.ColumnNumberToName(len(cols))
+	if err := x.f.SetCellStyle(name, "A1", lastCol+"1", headerStyle); err != nil {
+		return fmt.Errorf("style header: %w", err)
+	}
+
+	for r, row := range rows {
+		for c, col := range cols {
+			cell, _ := excelize.CoordinatesToCellName(c+1, r+2)
+
+			value, style, err := x.cell(col, row, currency)
+			if err != nil {
+				return err
+			}
+
+			if err := x.f.SetCellValue(name, cell, value); err != nil {
+				return fmt.Errorf("write cell %s: %w", cell, err)
+			}
+
+			if style != 0 {
+				if err := x.f.SetCellStyle(name, cell, cell, style); err != nil {
+					return fmt.Errorf("style cell %s: %w", cell, err)
+				}
+			}
+		}
+	}
+
+	if err := x.f.SetColWidth(name, "A", lastCol, 18); err != nil {
+		return fmt.Errorf("set column width: %w", err)
+	}
+
+	return x.freezeAndFilter(name, lastCol, len(rows)+1)
+}
+
+// cell returns the typed value of a column for XLSX along with its style.
+func (x *workbook) cell(col Column, row any, currency string) (any, int, error) {
+	if col.Value == nil {
+		if v, ok := fieldValue(row, col.Key); ok {
+			switch v.Kind() {
+			case reflect.Float32, reflect.Float64:
+				if c, ok := fieldValue(row, "currency"); ok && c.String() != "" {
+					currency = c.String()
+				}
+
+				style, err := x.moneyStyle(currency)
+
+				return v.Float(), style, err
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				return v.Int(), 0, nil
+			case reflect.Bool:
+				return v.Bool(), 0, nil
+			}
+		}
+	}
+
+	s := col.value(row)
+
+	if isDateColumn(col) {
+		if t, err := time.Parse("2006-01-02", formatDate(s)); err == nil {
+			style, err := x.dateStyle()
+
+			return t, style, err
+		}
+	}
+
+	return s, 0, nil
+}
+
+func (x *workbook) writeSummary(sheets []TransactionSheet, names []string) error {
+	if err := x.addSheet(xlsxSummarySheet); err != nil {
+		return err
+	}
+
+	headers := []any{"sheet", "account", "iban", "currency", "transactions", "income", "expenses", "net", "balance"}
+	if err := x.f.SetSheetRow(xlsxSummarySheet, "A1", &headers); err != nil {
+		return fmt.Errorf("write summary header: %w", err)
+	}
+
+	headerStyle, err := x.headerStyle()
+	if err != nil {
+		return err
+	}
+
+	if err := x.f.SetCellStyle(xlsxSummarySheet, "A1", "I1", headerStyle); err != nil {
+		return fmt.Errorf("style summary header: %w", err)
+	}
+
+	for i, sheet := range sheets {
+		var income, expenses float64
+
+		for _, tx := range sheet.Transactions {
+			if tx.Amount > 0 {
+				income += tx.Amount
+			} else {
+				expenses += tx.Amount
+			}
+		}
+
+		row := []any{
+			names[i],
+			sheet.Account.Description,
+			sheet.Account.Reference,
+			sheet.Account.Currency,
+			len(sheet.Transactions),
+			income,
+			expenses,
+			income + expenses,
+			sheet.Account.CurrentBalance,
+		}
+
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		if err := x.f.SetSheetRow(xlsxSummarySheet, cell, &row); err != nil {
+			return fmt.Errorf("write summary row: %w", err)
+		}
+
+		style, err := x.moneyStyle(sheet.Account.Currency)
+		if err != nil {
+			return err
+		}
+
+		if err := x.f.SetCellStyle(xlsxSummarySheet, fmt.Sprintf("F%d", i+2), fmt.Sprintf("I%d", i+2), style); err != nil {
+			return fmt.Errorf("style summary row: %w", err)
+		}
+	}
+
+	if err := x.f.SetColWidth(xlsxSummarySheet, "A", "I", 18); err != nil {
+		return fmt.Errorf("set column width: %w", err)
+	}
+
+	return x.freezeAndFilter(xlsxSummarySheet, "I", len(sheets)+1)
+}
+
+// addSheet creates a sheet, reusing the default sheet for the first one.
+func (x *workbook) addSheet(name string) error {
+	if x.f.SheetCount == 1 && x.f.GetSheetName(0) == "Sheet1" && name != "Sheet1" {
+		if err := x.f.SetSheetName("Sheet1", name); err != nil {
+			return fmt.Errorf("rename sheet: %w", err)
+		}
+
+		return nil
+	}
+
+	if _, err := x.f.NewSheet(name); err != nil {
+		return fmt.Errorf("create sheet %q: %w", name, err)
+	}
+
+	return nil
+}
+
+func (x *workbook) freezeAndFilter(sheet, lastCol string, lastRow int) error {
+	if err := x.f.SetPanes(sheet, &excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	}); err != nil {
+		return fmt.Errorf("freeze header: %w", err)
+	}
+
+	if err := x.f.AutoFilter(sheet, fmt.Sprintf("A1:%s%d", lastCol, lastRow), nil); err != nil {
+		return fmt.Errorf("set autofilter: %w", err)
+	}
+
+	return nil
+}
+
+func (x *workbook) finish(w io.Writer) error {
+	x.f.SetActiveSheet(0)
+
+	if err := x.f.Write(w); err != nil {
+		return fmt.Errorf("write xlsx: %w", err)
+	}
+
+	return nil
+}
+
+func accountSheetName(a api.Account) string {
+	if a.Description != "" {
+		return a.Description
+	}
+
+	if a.Reference != "" {
+		return a.Reference
+	}
+
+	return a.ID
+}
+
+// sanitizeSheetName strips characters Excel forbids in sheet names and
+// enforces the 31 character limit.
+func sanitizeSheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '-'
+		}
+
+		return r
+	}, name)
+
+	name = strings.Trim(strings.TrimSpace(name), "'")
+	if name == "" {
+		name = "Sheet"
+	}
+
+	return Truncate(name, xlsxMaxSheetName)
+}
+
+func isDateColumn(c Column) bool {
+	return c.Date || strings.HasSuffix(c.Key, "Date") || strings.HasSuffix(c.Key, "At")
+}
@@ -0,0 +1,70 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/dedene/ponto-cli/internal/api"
+)
+
+func TestTransactionsXLSX(t *testing.T) {
+	t.Parallel()
+
+	sheets := []TransactionSheet{
+		{
+			Account: api.Account{ID: "a1", Description: "Ops: main/EUR", Reference: "BE68539007547034", Currency: "EUR", CurrentBalance: 100},
+			Transactions: []api.Transaction{
+				{ID: "t1", ExecutionDate: "2024-03-01T10:00:00Z", Amount: 250, Currency: "EUR"},
+				{ID: "t2", ExecutionDate: "2024-03-02", Amount: -50.25, Currency: "EUR"},
+			},
+		},
+		{
+			Account: api.Account{ID: "a2", Description: "Summary", Currency: "USD"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := TransactionsXLSX(context.Background(), &buf, sheets); err != nil {
+		t.Fatalf("TransactionsXLSX() error = %v", err)
+	}
+
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("OpenReader() error = %v", err)
+	}
+	defer f.Close()
+
+	wantSheets := []string{"Ops- main-EUR", "Summary (2)", "Summary"}
+	if got := f.GetSheetList(); !reflect.DeepEqual(got, wantSheets) {
+		t.Errorf("sheets = %v, want %v", got, wantSheets)
+	}
+
+	header, _ := f.GetCellValue("Ops- main-EUR", "A1")
+	if header != "id" {
+		t.Errorf("A1 = %q, want %q", header, "id")
+	}
+
+	// Amounts are numeric cells, dates are real dates.
+	amount, _ := f.GetCellValue("Ops- main-EUR", "H3", excelize.Options{RawCellValue: true})
+	if amount != "-50.25" {
+		t.Errorf("H3 raw = %q, want -50.25", amount)
+	}
+
+	if typ, _ := f.GetCellType("Ops- main-EUR", "H3"); typ == excelize.CellTypeSharedString || typ == excelize.CellTypeInlineString {
+		t.Errorf("H3 type = %v, want numeric", typ)
+	}
+
+	date, _ := f.GetCellValue("Ops- main-EUR", "B2")
+	if date != "2024-03-01" {
+		t.Errorf("B2 = %q, want 2024-03-01", date)
+	}
+
+	net, _ := f.GetCellValue("Summary", "H2", excelize.Options{RawCellValue: true})
+	if net != "199.75" {
+		t.Errorf("summary net = %q, want 199.75", net)
+	}
+}
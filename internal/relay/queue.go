@@ -0,0 +1,254 @@
+// Package relay delivers events to a webhook as signed JSON, through a
+// queue on disk that survives restarts and receiver outages.
+package relay
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultMaxAttempts is how often a delivery is tried before it is
+	// moved to the failed directory.
+	DefaultMaxAttempts = 10
+
+	baseBackoff = 5 * time.Second
+	maxBackoff  = time.Hour
+)
+
+// Delivery is a queued event.
+type Delivery struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+
+	Attempts    int       `json:"attempts"`
+	NextAttempt time.Time `json:"nextAttempt"`
+	LastError   string    `json:"lastError,omitempty"`
+
+	file string
+}
+
+// Queue is a directory with one file per pending delivery. File names sort
+// in enqueue order; deliveries that exhaust their attempts move to the
+// "failed" subdirectory.
+type Queue struct {
+	dir         string
+	maxAttempts int
+	last        int64
+
+	// Now returns the current time; nil means time.Now.
+	Now func() time.Time
+}
+
+// OpenQueue opens the queue in dir, creating it as needed. maxAttempts <= 0
+// means DefaultMaxAttempts.
+func OpenQueue(dir string, maxAttempts int) (*Queue, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "failed"), 0o700); err != nil {
+		return nil, fmt.Errorf("create queue: %w", err)
+	}
+
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+
+	return &Queue{dir: dir, maxAttempts: maxAttempts}, nil
+}
+
+// Enqueue adds an event of type typ with data as its payload.
+func (q *Queue) Enqueue(typ string, data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", typ, err)
+	}
+
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+
+	now := q.now()
+
+	// Strictly increasing names keep the order of events enqueued within
+	// the clock's resolution.
+	seq := max(now.UnixNano(), q.last+1)
+	q.last = seq
+
+	d := &Delivery{
+		ID:          id,
+		Type:        typ,
+		CreatedAt:   now.UTC(),
+		Data:        raw,
+		NextAttempt: now,
+		file:        filepath.Join(q.dir, fmt.Sprintf("%020d-%s.json", seq, id)),
+	}
+
+	return writeDelivery(d)
+}
+
+// Pending returns the queued deliveries, oldest first.
+func (q *Queue) Pending() ([]*Delivery, error) {
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return nil, fmt.Errorf("read queue: %w", err)
+	}
+
+	var out []*Delivery
+
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+
+		file := filepath.Join(q.dir, e.Name())
+
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read delivery: %w", err)
+		}
+
+		var d Delivery
+		if err := json.Unmarshal(b, &d); err != nil {
+			return nil, fmt.Errorf("parse delivery %s: %w", file, err)
+		}
+
+		d.file = file
+		out = append(out, &d)
+	}
+
+	slices.SortFunc(out, func(a, b *Delivery) int { return strings.Compare(a.file, b.file) })
+
+	return out, nil
+}
+
+// Flush sends the due deliveries in order with send. It stops at the first
+// failure, so the receiver sees events in order, and schedules a retry with
+// exponential backoff. It returns when the next retry is due, or the zero
+// time when the queue is empty.
+func (q *Queue) Flush(ctx context.Context, send func(context.Context, *Delivery) error) (time.Time, error) {
+	pending, err := q.Pending()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	for _, d := range pending {
+		if ctx.Err() != nil {
+			return time.Time{}, ctx.Err()
+		}
+
+		if d.NextAttempt.After(q.now()) {
+			return d.NextAttempt, nil
+		}
+
+		sendErr := send(ctx, d)
+		if sendErr == nil {
+			if err := os.Remove(d.file); err != nil {
+				return time.Time{}, fmt.Errorf("remove delivery: %w", err)
+			}
+
+			continue
+		}
+
+		if ctx.Err() != nil {
+			return time.Time{}, ctx.Err()
+		}
+
+		d.Attempts++
+		d.LastError = sendErr.Error()
+
+		if d.Attempts >= q.maxAttempts {
+			slog.Error("giving up on delivery", "id", d.ID, "type", d.Type, "attempts", d.Attempts, "error", sendErr)
+
+			if err := q.fail(d); err != nil {
+				return time.Time{}, err
+			}
+
+			continue
+		}
+
+		d.NextAttempt = q.now().Add(backoff(d.Attempts))
+		slog.Warn("delivery failed", "id", d.ID, "type", d.Type, "attempt", d.Attempts, "retry", d.NextAttempt, "error", sendErr)
+
+		if err := writeDelivery(d); err != nil {
+			return time.Time{}, err
+		}
+
+		return d.NextAttempt, nil
+	}
+
+	return time.Time{}, nil
+}
+
+// fail moves d to the failed directory.
+func (q *Queue) fail(d *Delivery) error {
+	old := d.file
+	d.file = filepath.Join(q.dir, "failed", filepath.Base(old))
+
+	if err := writeDelivery(d); err != nil {
+		return err
+	}
+
+	if err := os.Remove(old); err != nil {
+		return fmt.Errorf("remove delivery: %w", err)
+	}
+
+	return nil
+}
+
+func (q *Queue) now() time.Time {
+	if q.Now != nil {
+		return q.Now()
+	}
+
+	return time.Now()
+}
+
+// backoff doubles the wait after every failed attempt, up to maxBackoff.
+func backoff(attempts int) time.Duration {
+	d := baseBackoff
+	for i := 1; i < attempts && d < maxBackoff; i++ {
+		d *= 2
+	}
+
+	return min(d, maxBackoff)
+}
+
+func writeDelivery(d *Delivery) error {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return fmt.Errorf("marshal delivery: %w", err)
+	}
+
+	tmp := filepath.Join(filepath.Dir(d.file), "."+filepath.Base(d.file)+".tmp")
+
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return fmt.Errorf("write delivery: %w", err)
+	}
+
+	if err := os.Rename(tmp, d.file); err != nil {
+		_ = os.Remove(tmp)
+
+		return fmt.Errorf("commit delivery: %w", err)
+	}
+
+	return nil
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate delivery id: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,187 @@
+package relay
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// receiver is a webhook that verifies signatures and fails the first
+// failures requests.
+type receiver struct {
+	mu       sync.Mutex
+	secret   string
+	failures int
+	got      []Payload
+	errs     []string
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	body, _ := io.ReadAll(req.Body)
+
+	if err := Verify(r.secret, req.Header, body, time.Now(), time.Minute); err != nil {
+		r.errs = append(r.errs, err.Error())
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+
+		return
+	}
+
+	if r.failures > 0 {
+		r.failures--
+		http.Error(w, "try later", http.StatusServiceUnavailable)
+
+		return
+	}
+
+	var p Payload
+	if err := json.Unmarshal(body, &p); err != nil || req.Header.Get(HeaderEvent) != p.Type || req.Header.Get(HeaderDelivery) != p.ID {
+		r.errs = append(r.errs, "bad payload "+string(body))
+	}
+
+	r.got = append(r.got, p)
+}
+
+func TestRelayDelivers(t *testing.T) {
+	t.Parallel()
+
+	rcv := &receiver{secret: "s3cret", failures: 2}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	now := time.Now()
+
+	q, err := OpenQueue(dir, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q.Now = func() time.Time { return now }
+
+	for _, id := range []string{"t1", "t2", "t3"} {
+		if err := q.Enqueue("transaction", map[string]string{"id": id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := &Sender{URL: srv.URL, Secret: "s3cret", Client: srv.Client()}
+	ctx := context.Background()
+
+	// The first attempt fails; nothing else is sent before it.
+	retry, err := q.Flush(ctx, s.Send)
+	if err != nil || !retry.Equal(now.Add(baseBackoff)) {
+		t.Fatalf("Flush() = %v, %v; want a retry after %s", retry, err, baseBackoff)
+	}
+
+	if retry, _ := q.Flush(ctx, s.Send); !retry.Equal(now.Add(baseBackoff)) {
+		t.Fatalf("Flush() before the retry is due = %v, want no attempt", retry)
+	}
+
+	// A reopened queue picks up where the previous one stopped.
+	q, _ = OpenQueue(dir, 5)
+	now = now.Add(baseBackoff)
+	q.Now = func() time.Time { return now }
+
+	if retry, _ := q.Flush(ctx, s.Send); !retry.Equal(now.Add(2 * baseBackoff)) {
+		t.Fatalf("second retry at %v, want backoff doubled to %s", retry, 2*baseBackoff)
+	}
+
+	now = now.Add(2 * baseBackoff)
+
+	if retry, err := q.Flush(ctx, s.Send); err != nil || !retry.IsZero() {
+		t.Fatalf("Flush() = %v, %v; want the queue drained", retry, err)
+	}
+
+	if len(rcv.errs) > 0 {
+		t.Errorf("receiver errors: %v", rcv.errs)
+	}
+
+	var ids []string
+
+	for _, p := range rcv.got {
+		var data map[string]string
+		_ = json.Unmarshal(p.Data, &data)
+		ids = append(ids, data["id"])
+	}
+
+	if len(ids) != 3 || ids[0] != "t1" || ids[1] != "t2" || ids[2] != "t3" {
+		t.Errorf("delivered %v, want t1 t2 t3 in order", ids)
+	}
+
+	if pending, _ := q.Pending(); len(pending) != 0 {
+		t.Errorf("%d deliveries left in the queue", len(pending))
+	}
+}
+
+func TestRelayGivesUp(t *testing.T) {
+	t.Parallel()
+
+	rcv := &receiver{secret: "other"}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+
+	dir := t.TempDir()
+
+	q, err := OpenQueue(dir, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = q.Enqueue("synchronization", map[string]string{"status": "success"})
+
+	s := &Sender{URL: srv.URL, Secret: "s3cret", Client: srv.Client()}
+
+	if retry, err := q.Flush(context.Background(), s.Send); err != nil || !retry.IsZero() {
+		t.Fatalf("Flush() = %v, %v; want the delivery dropped from the queue", retry, err)
+	}
+
+	failed, _ := os.ReadDir(filepath.Join(dir, "failed"))
+	if len(failed) != 1 || len(rcv.errs) != 1 || rcv.errs[0] != "signature mismatch" {
+		t.Errorf("failed queue has %d entries, receiver errors %v; want 1 rejected delivery", len(failed), rcv.errs)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"id":"x"}`)
+
+	header := http.Header{}
+	header.Set(HeaderTimestamp, "1700000000")
+	header.Set(HeaderSignature, Sign("k", "1700000000", body))
+
+	tests := []struct {
+		name    string
+		secret  string
+		body    []byte
+		now     time.Time
+		wantErr bool
+	}{
+		{name: "valid", secret: "k", body: body, now: now},
+		{name: "wrong secret", secret: "x", body: body, now: now, wantErr: true},
+		{name: "tampered body", secret: "k", body: []byte(`{"id":"y"}`), now: now, wantErr: true},
+		{name: "replayed later", secret: "k", body: body, now: now.Add(10 * time.Minute), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := Verify(tt.secret, header, tt.body, tt.now, 5*time.Minute)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
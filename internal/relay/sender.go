@@ -0,0 +1,123 @@
+package relay
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers of a delivery. The signature is "sha256=" and the hex HMAC-SHA256
+// of "<timestamp>.<body>" under the shared secret.
+const (
+	HeaderSignature = "X-Ponto-Relay-Signature"
+	HeaderTimestamp = "X-Ponto-Relay-Timestamp"
+	HeaderEvent     = "X-Ponto-Relay-Event"
+	HeaderDelivery  = "X-Ponto-Relay-Delivery"
+)
+
+// Payload is the JSON body POSTed for a delivery.
+type Payload struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Sender POSTs deliveries to a webhook.
+type Sender struct {
+	URL    string
+	Secret string
+	Client *http.Client
+
+	// Now returns the current time; nil means time.Now.
+	Now func() time.Time
+}
+
+// Send POSTs d. Any status outside 2xx is an error.
+func (s *Sender) Send(ctx context.Context, d *Delivery) error {
+	body, err := json.Marshal(Payload{ID: d.ID, Type: d.Type, CreatedAt: d.CreatedAt, Data: d.Data})
+	if err != nil {
+		return fmt.Errorf("marshal payload: %w", err)
+	}
+
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+
+	ts := strconv.FormatInt(now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ponto-cli-relay")
+	req.Header.Set(HeaderSignature, Sign(s.Secret, ts, body))
+	req.Header.Set(HeaderTimestamp, ts)
+	req.Header.Set(HeaderEvent, d.Type)
+	req.Header.Set(HeaderDelivery, d.ID)
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post %s: %w", d.Type, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return nil
+}
+
+// Sign returns the signature header value of body sent at timestamp.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a delivery's signature and rejects timestamps further than
+// tolerance from now, so captured requests can't be replayed later.
+func Verify(secret string, header http.Header, body []byte, now time.Time, tolerance time.Duration) error {
+	ts := header.Get(HeaderTimestamp)
+
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return errors.New("missing or invalid timestamp")
+	}
+
+	if d := now.Sub(time.Unix(sec, 0)); d > tolerance || d < -tolerance {
+		return fmt.Errorf("timestamp is %s off", d.Round(time.Second))
+	}
+
+	if !hmac.Equal([]byte(header.Get(HeaderSignature)), []byte(Sign(secret, ts, body))) {
+		return errors.New("signature mismatch")
+	}
+
+	return nil
+}
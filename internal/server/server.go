@@ -0,0 +1,341 @@
+// Package server serves a simplified local HTTP API backed by the Ponto API,
+// so other programs can read accounts and transactions without their own
+// Ponto Connect client.
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dedene/ponto-cli/internal/api"
+	"github.com/dedene/ponto-cli/internal/errfmt"
+)
+
+// Source is the part of api.Client that the server needs.
+type Source interface {
+	ListAccounts(ctx context.Context) ([]api.Account, error)
+	GetAccount(ctx context.Context, id string) (*api.Account, error)
+	ListTransactions(ctx context.Context, accountID string, opts api.TransactionListOptions) ([]api.Transaction, error)
+	ListPendingTransactions(ctx context.Context, accountID string) ([]api.PendingTransaction, error)
+	CreateSync(ctx context.Context, accountID, subtype string) (*api.Synchronization, error)
+	GetSync(ctx context.Context, id string) (*api.Synchronization, error)
+}
+
+// Server is the local API of `ponto serve`. Every endpoint corresponds to a
+// CLI command, e.g. GET /accounts to accounts.list, and is only served when
+// Allowed lets that command run.
+type Server struct {
+	Source Source
+
+	// Token, when set, must be sent as "Authorization: Bearer <token>".
+	Token string
+
+	// ReadOnly refuses the endpoints that change anything, i.e. all but
+	// GET.
+	ReadOnly bool
+
+	// Allowed reports whether a dotted command path such as
+	// "transactions.list" is enabled; nil allows every command.
+	Allowed func(command string) bool
+
+	// ResolveAccount turns the account in a URL, an ID, alias, IBAN or
+	// name, into an account ID; nil takes it as an ID.
+	ResolveAccount func(ctx context.Context, ref string) (string, error)
+}
+
+// statusError is an error with the HTTP status it is served with.
+type statusError struct {
+	status int
+	err    error
+}
+
+func (e *statusError) Error() string { return e.err.Error() }
+func (e *statusError) Unwrap() error { return e.err }
+
+func badRequest(format string, args ...any) error {
+	return &statusError{status: http.StatusBadRequest, err: fmt.Errorf(format, args...)}
+}
+
+type handlerFunc func(r *http.Request) (status int, data any, err error)
+
+// Handler returns the HTTP handler with all endpoints:
+//
+//	GET  /healthz                               no auth, no API call
+//	GET  /accounts                              accounts.list
+//	GET  /accounts/{id}                         accounts.get
+//	GET  /accounts/{id}/transactions            transactions.list
+//	GET  /accounts/{id}/pending-transactions    pending-transactions.list
+//	POST /accounts/{id}/synchronizations        accounts.sync
+//	GET  /synchronizations/{id}                 sync.get
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+
+	s.handle(mux, "GET /accounts", "accounts.list", s.listAccounts)
+	s.handle(mux, "GET /accounts/{id}", "accounts.get", s.getAccount)
+	s.handle(mux, "GET /accounts/{id}/transactions", "transactions.list", s.listTransactions)
+	s.handle(mux, "GET /accounts/{id}/pending-transactions", "pending-transactions.list", s.listPendingTransactions)
+	s.handle(mux, "POST /accounts/{id}/synchronizations", "accounts.sync", s.createSync)
+	s.handle(mux, "GET /synchronizations/{id}", "sync.get", s.getSync)
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no endpoint %s %s", r.Method, r.URL.Path))
+	})
+
+	return mux
+}
+
+func (s *Server) handle(mux *http.ServeMux, pattern, command string, fn handlerFunc) {
+	mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		if !s.authorized(r) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="ponto"`)
+			writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+
+			return
+		}
+
+		if s.ReadOnly && r.Method != http.MethodGet {
+			writeError(w, http.StatusForbidden, fmt.Sprintf("command %q is not allowed on a read-only server", command))
+
+			return
+		}
+
+		if s.Allowed != nil && !s.Allowed(command) {
+			writeError(w, http.StatusForbidden, fmt.Sprintf("command %q is not enabled", command))
+
+			return
+		}
+
+		status, data, err := fn(r)
+		if err != nil {
+			status = statusOf(err)
+			slog.Warn("serve request", "method", r.Method, "path", r.URL.Path, "status", status, "error", err)
+			writeError(w, status, errfmt.Format(err))
+
+			return
+		}
+
+		writeJSON(w, status, data)
+	})
+}
+
+// authorized compares the bearer token in constant time. Both sides are
+// hashed first so their lengths do not leak either.
+func (s *Server) authorized(r *http.Request) bool {
+	if s.Token == "" {
+		return true
+	}
+
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+
+	want := sha256.Sum256([]byte(s.Token))
+	sum := sha256.Sum256([]byte(strings.TrimSpace(got)))
+
+	return subtle.ConstantTimeCompare(want[:], sum[:]) == 1
+}
+
+func (s *Server) accountID(r *http.Request) (string, error) {
+	ref := r.PathValue("id")
+
+	if s.ResolveAccount == nil {
+		return ref, nil
+	}
+
+	id, err := s.ResolveAccount(r.Context(), ref)
+	if err != nil {
+		var apiErr *api.APIError
+		if errors.As(err, &apiErr) {
+			return "", err
+		}
+
+		return "", &statusError{status: http.StatusNotFound, err: err}
+	}
+
+	return id, nil
+}
+
+func (s *Server) listAccounts(r *http.Request) (int, any, error) {
+	accounts, err := s.Source.ListAccounts(r.Context())
+	if err != nil {
+		return 0, nil, fmt.Errorf("list accounts: %w", err)
+	}
+
+	return http.StatusOK, nonNil(accounts), nil
+}
+
+func (s *Server) getAccount(r *http.Request) (int, any, error) {
+	id, err := s.accountID(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	account, err := s.Source.GetAccount(r.Context(), id)
+	if err != nil {
+		return 0, nil, fmt.Errorf("get account: %w", err)
+	}
+
+	return http.StatusOK, account, nil
+}
+
+func (s *Server) listTransactions(r *http.Request) (int, any, error) {
+	opts, err := transactionOptions(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	id, err := s.accountID(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	txs, err := s.Source.ListTransactions(r.Context(), id, opts)
+	if err != nil {
+		return 0, nil, fmt.Errorf("list transactions: %w", err)
+	}
+
+	return http.StatusOK, nonNil(txs), nil
+}
+
+func (s *Server) listPendingTransactions(r *http.Request) (int, any, error) {
+	id, err := s.accountID(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	txs, err := s.Source.ListPendingTransactions(r.Context(), id)
+	if err != nil {
+		return 0, nil, fmt.Errorf("list pending transactions: %w", err)
+	}
+
+	return http.StatusOK, nonNil(txs), nil
+}
+
+// createSync starts a synchronization of the account. The subtype query
+// parameter defaults to accountTransactions, like accounts sync.
+func (s *Server) createSync(r *http.Request) (int, any, error) {
+	subtype := r.URL.Query().Get("subtype")
+
+	switch subtype {
+	case "":
+		subtype = "accountTransactions"
+	case "accountTransactions", "accountDetails":
+	default:
+		return 0, nil, badRequest("invalid subtype %q (use accountTransactions or accountDetails)", subtype)
+	}
+
+	id, err := s.accountID(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	sync, err := s.Source.CreateSync(r.Context(), id, subtype)
+	if err != nil {
+		return 0, nil, fmt.Errorf("create sync: %w", err)
+	}
+
+	return http.StatusCreated, sync, nil
+}
+
+func (s *Server) getSync(r *http.Request) (int, any, error) {
+	sync, err := s.Source.GetSync(r.Context(), r.PathValue("id"))
+	if err != nil {
+		return 0, nil, fmt.Errorf("get sync: %w", err)
+	}
+
+	return http.StatusOK, sync, nil
+}
+
+// transactionOptions reads the since, until and limit query parameters.
+// Dates take the same forms as transactions list: YYYY-MM-DD or -Nd.
+func transactionOptions(r *http.Request) (api.TransactionListOptions, error) {
+	var opts api.TransactionListOptions
+
+	q := r.URL.Query()
+
+	for _, p := range []struct {
+		name string
+		dst  *string
+	}{{"since", &opts.Since}, {"until", &opts.Until}} {
+		v := q.Get(p.name)
+		if v == "" {
+			continue
+		}
+
+		date, err := api.ParseDate(v)
+		if err != nil {
+			return opts, badRequest("%s: %w", p.name, err)
+		}
+
+		*p.dst = date
+	}
+
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return opts, badRequest("limit must be a positive number, got %q", v)
+		}
+
+		opts.Limit = n
+	}
+
+	return opts, nil
+}
+
+// statusOf maps an error to the status it is served with. Ponto's own 404
+// and 429 pass through; other API failures are the gateway's.
+func statusOf(err error) int {
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.status
+	}
+
+	var apiErr *api.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusBadRequest, http.StatusNotFound, http.StatusTooManyRequests:
+			return apiErr.StatusCode
+		}
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout
+	}
+
+	return http.StatusBadGateway
+}
+
+// nonNil makes empty lists encode as [] rather than null.
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+
+	return items
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Warn("write response", "error", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
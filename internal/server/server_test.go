@@ -0,0 +1,179 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dedene/ponto-cli/internal/api"
+)
+
+type fakeSource struct {
+	opts api.TransactionListOptions
+}
+
+func (f *fakeSource) ListAccounts(context.Context) ([]api.Account, error) {
+	return []api.Account{{ID: "a1"}, {ID: "a2"}}, nil
+}
+
+func (f *fakeSource) GetAccount(_ context.Context, id string) (*api.Account, error) {
+	if id != "a1" {
+		return nil, &api.APIError{StatusCode: http.StatusNotFound, Message: "account " + id}
+	}
+
+	return &api.Account{ID: id}, nil
+}
+
+func (f *fakeSource) ListTransactions(_ context.Context, _ string, opts api.TransactionListOptions) ([]api.Transaction, error) {
+	f.opts = opts
+
+	return nil, nil
+}
+
+func (f *fakeSource) ListPendingTransactions(context.Context, string) ([]api.PendingTransaction, error) {
+	return nil, &api.APIError{StatusCode: http.StatusInternalServerError, Message: "boom"}
+}
+
+func (f *fakeSource) CreateSync(_ context.Context, accountID, subtype string) (*api.Synchronization, error) {
+	return &api.Synchronization{ID: "s1"}, nil
+}
+
+func (f *fakeSource) GetSync(_ context.Context, id string) (*api.Synchronization, error) {
+	return &api.Synchronization{ID: id}, nil
+}
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+
+	src := &fakeSource{}
+	s := &Server{
+		Source:  src,
+		Token:   "secret",
+		Allowed: func(command string) bool { return command != "accounts.sync" },
+		ResolveAccount: func(_ context.Context, ref string) (string, error) {
+			if ref == "main" {
+				return "a1", nil
+			}
+
+			if strings.HasPrefix(ref, "a") {
+				return ref, nil
+			}
+
+			return "", errors.New("no account matches " + ref)
+		},
+	}
+
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		token    string
+		wantCode int
+		wantBody string
+	}{
+		{name: "health without token", method: http.MethodGet, path: "/healthz", wantCode: http.StatusOK, wantBody: `{"status":"ok"}`},
+		{name: "missing token", method: http.MethodGet, path: "/accounts", wantCode: http.StatusUnauthorized},
+		{name: "wrong token", method: http.MethodGet, path: "/accounts", token: "nope", wantCode: http.StatusUnauthorized},
+		{name: "accounts", method: http.MethodGet, path: "/accounts", token: "secret", wantCode: http.StatusOK, wantBody: `"id":"a2"`},
+		{name: "account by alias", method: http.MethodGet, path: "/accounts/main", token: "secret", wantCode: http.StatusOK, wantBody: `"id":"a1"`},
+		{name: "unknown account", method: http.MethodGet, path: "/accounts/zz", token: "secret", wantCode: http.StatusNotFound, wantBody: "no account matches zz"},
+		{name: "account not in Ponto", method: http.MethodGet, path: "/accounts/a9", token: "secret", wantCode: http.StatusNotFound},
+		{name: "no transactions", method: http.MethodGet, path: "/accounts/main/transactions?since=2024-01-01&limit=5", token: "secret", wantCode: http.StatusOK, wantBody: "[]"},
+		{name: "bad since", method: http.MethodGet, path: "/accounts/main/transactions?since=yesterday", token: "secret", wantCode: http.StatusBadRequest, wantBody: "since"},
+		{name: "bad limit", method: http.MethodGet, path: "/accounts/main/transactions?limit=0", token: "secret", wantCode: http.StatusBadRequest, wantBody: "limit"},
+		{name: "upstream failure", method: http.MethodGet, path: "/accounts/main/pending-transactions", token: "secret", wantCode: http.StatusBadGateway, wantBody: "boom"},
+		{name: "sync not enabled", method: http.MethodPost, path: "/accounts/main/synchronizations", token: "secret", wantCode: http.StatusForbidden, wantBody: "accounts.sync"},
+		{name: "get sync", method: http.MethodGet, path: "/synchronizations/s1", token: "secret", wantCode: http.StatusOK, wantBody: `"id":"s1"`},
+		{name: "unknown endpoint", method: http.MethodGet, path: "/organization", token: "secret", wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, srv.URL+tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			body, _ := io.ReadAll(resp.Body)
+
+			if resp.StatusCode != tt.wantCode {
+				t.Errorf("%s %s = %d, want %d (%s)", tt.method, tt.path, resp.StatusCode, tt.wantCode, body)
+			}
+
+			if !json.Valid(body) {
+				t.Errorf("%s %s body %q is not JSON", tt.method, tt.path, body)
+			}
+
+			if !strings.Contains(string(body), tt.wantBody) {
+				t.Errorf("%s %s body = %s, want it to contain %s", tt.method, tt.path, body, tt.wantBody)
+			}
+		})
+	}
+
+	if src.opts.Since != "2024-01-01" || src.opts.Limit != 5 {
+		t.Errorf("transaction options = %+v, want since 2024-01-01 and limit 5", src.opts)
+	}
+}
+
+func TestServerWithoutToken(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer((&Server{Source: &fakeSource{}}).Handler())
+	defer srv.Close()
+
+	resp, err := srv.Client().Post(srv.URL+"/accounts/a1/synchronizations?subtype=accountDetails", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("create sync = %d, want 201", resp.StatusCode)
+	}
+}
+
+func TestServerReadOnly(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer((&Server{Source: &fakeSource{}, ReadOnly: true}).Handler())
+	defer srv.Close()
+
+	tests := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{method: http.MethodGet, path: "/accounts/a1/transactions", want: http.StatusOK},
+		{method: http.MethodPost, path: "/accounts/a1/synchronizations", want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, srv.URL+tt.path, nil)
+
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.want {
+			t.Errorf("%s %s = %d, want %d", tt.method, tt.path, resp.StatusCode, tt.want)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package tui
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+
+	"github.com/dedene/ponto-cli/internal/api"
+)
+
+// syncPollInterval is how often a running synchronization is checked.
+const syncPollInterval = 2 * time.Second
+
+// resizeInterval is how often the terminal size is checked.
+const resizeInterval = 250 * time.Millisecond
+
+// Source provides the data shown by the dashboard.
+type Source interface {
+	Accounts(ctx context.Context) ([]api.Account, error)
+	Transactions(ctx context.Context, accountID string) ([]api.Transaction, error)
+	PendingTransactions(ctx context.Context, accountID string) ([]api.PendingTransaction, error)
+	CreateSync(ctx context.Context, accountID string) (*api.Synchronization, error)
+	GetSync(ctx context.Context, id string) (*api.Synchronization, error)
+}
+
+// msg is an event handled by the dashboard model: a key press, a resize or
+// the result of a task.
+type msg any
+
+// task is work started by the model, run outside the event loop. Its
+// result is fed back to the model.
+type task func(ctx context.Context) msg
+
+type keyMsg Key
+
+type resizeMsg struct {
+	width, height int
+}
+
+type accountsMsg struct {
+	accounts []api.Account
+	err      error
+}
+
+type transactionsMsg struct {
+	accountID string
+	txns      []api.Transaction
+	err       error
+}
+
+type pendingMsg struct {
+	accountID string
+	pending   []api.PendingTransaction
+	err       error
+}
+
+type syncMsg struct {
+	accountID string
+	sync      *api.Synchronization
+	err       error
+}
+
+// Run shows the dashboard for profile until the user quits. in and out
+// must be terminals.
+func Run(ctx context.Context, in *os.File, out io.Writer, profile string, src Source) error {
+	scr, err := openScreen(in, out)
+	if err != nil {
+		return err
+	}
+	defer scr.close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	width, height := scr.size()
+	m := newModel(src, profile, width, height, ColorProfile(out))
+
+	msgs := make(chan msg)
+	keys := make(chan Key)
+	readErr := make(chan error, 1)
+
+	go func() {
+		r := NewKeyReader(in)
+
+		for {
+			key, err := r.ReadKey()
+			if err != nil {
+				readErr <- err
+
+				return
+			}
+
+			select {
+			case keys <- key:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	start := func(tasks []task) {
+		for _, t := range tasks {
+			go func() {
+				result := t(ctx)
+
+				select {
+				case msgs <- result:
+				case <-ctx.Done():
+				}
+			}()
+		}
+	}
+
+	start(m.init())
+
+	ticker := time.NewTicker(resizeInterval)
+	defer ticker.Stop()
+
+	redraw := true
+
+	for !m.quit {
+		if redraw {
+			scr.draw(m.view())
+		}
+
+		redraw = true
+
+		select {
+		case key := <-keys:
+			start(m.update(keyMsg(key)))
+		case result := <-msgs:
+			start(m.update(result))
+		case <-ticker.C:
+			w, h := scr.size()
+			if w == m.width && h == m.height {
+				redraw = false
+
+				continue
+			}
+
+			m.update(resizeMsg{width: w, height: h})
+		case err := <-readErr:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/muesli/termenv"
+
+	"github.com/dedene/ponto-cli/internal/api"
+)
+
+type fakeSource struct {
+	mu           sync.Mutex
+	accountLoads int
+	syncStatuses []string
+}
+
+func (s *fakeSource) Accounts(context.Context) ([]api.Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.accountLoads++
+
+	return []api.Account{
+		{ID: "a1", Description: "Main", Reference: "BE68539007547034", CurrentBalance: 1250, Currency: "EUR"},
+		{ID: "a2", Description: "Savings", Reference: "BE71096123456769", CurrentBalance: 5000, Currency: "EUR"},
+	}, nil
+}
+
+func (s *fakeSource) Transactions(_ context.Context, accountID string) ([]api.Transaction, error) {
+	return []api.Transaction{
+		{ID: accountID + "-t1", Amount: -10, CounterpartName: "Shop", ExecutionDate: "2024-02-20T10:00:00Z",
+			RemittanceInfo: "+++090/9337/55493+++", RemittanceInfoType: "structured"},
+		{ID: accountID + "-t2", Amount: 50, CounterpartName: "Client", ExecutionDate: "2024-01-10T10:00:00Z"},
+	}, nil
+}
+
+func (s *fakeSource) PendingTransactions(context.Context, string) ([]api.PendingTransaction, error) {
+	return []api.PendingTransaction{{ID: "p1", Amount: -3.5, CounterpartName: "Cafe"}}, nil
+}
+
+func (s *fakeSource) CreateSync(context.Context, string) (*api.Synchronization, error) {
+	return s.GetSync(context.Background(), "s1")
+}
+
+func (s *fakeSource) GetSync(_ context.Context, id string) (*api.Synchronization, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := s.syncStatuses[0]
+	s.syncStatuses = s.syncStatuses[1:]
+
+	return &api.Synchronization{ID: id, Status: status}, nil
+}
+
+// press feeds keys to m, running the resulting tasks to completion.
+func press(m *model, keys ...Key) {
+	for _, k := range keys {
+		run(m, m.update(keyMsg(k)))
+	}
+}
+
+func run(m *model, tasks []task) {
+	for _, t := range tasks {
+		run(m, m.update(t(context.Background())))
+	}
+}
+
+func runes(s string) []Key {
+	keys := make([]Key, 0, len(s))
+	for _, r := range s {
+		keys = append(keys, Key{Rune: r})
+	}
+
+	return keys
+}
+
+func newTestModel(src Source) *model {
+	m := newModel(src, "default", 60, 10, termenv.Ascii)
+	m.poll = 0
+	run(m, m.init())
+
+	return m
+}
+
+func TestDashboardNavigation(t *testing.T) {
+	t.Parallel()
+
+	m := newTestModel(&fakeSource{})
+
+	if len(m.accounts) != 2 || m.loading != 0 {
+		t.Fatalf("accounts = %d (loading %d), want 2 loaded", len(m.accounts), m.loading)
+	}
+
+	press(m, Key{Code: KeyDown}, Key{Code: KeyEnter})
+
+	if m.page != pageTransactions || m.account.ID != "a2" || len(m.visible) != 2 {
+		t.Fatalf("page %d for %s with %d rows, want transactions of a2 with 2 rows", m.page, m.account.ID, len(m.visible))
+	}
+
+	press(m, Key{Rune: '/'})
+	press(m, runes("9337")...)
+	press(m, Key{Code: KeyEnter})
+
+	if len(m.visible) != 1 || m.txns[m.visible[0]].ID != "a2-t1" {
+		t.Errorf("search 9337 matches %v, want a2-t1 by its communication", m.visible)
+	}
+
+	press(m, Key{Code: KeyEsc}, Key{Rune: 't'})
+
+	if m.kind != "income" || len(m.visible) != 1 || m.txns[m.visible[0]].ID != "a2-t2" {
+		t.Errorf("income filter = %v, want only a2-t2", m.visible)
+	}
+
+	press(m, Key{Code: KeyEnter})
+
+	if m.page != pageDetail || !strings.Contains(strings.Join(m.detail, "\n"), "Counterpart:   Client") {
+		t.Errorf("detail = %q, want the transaction of Client", m.detail)
+	}
+
+	press(m, Key{Code: KeyEsc}, Key{Rune: 'p'})
+
+	if m.page != pagePending || len(m.pending) != 1 {
+		t.Errorf("page %d with %d pending, want pending list with 1 row", m.page, len(m.pending))
+	}
+
+	press(m, Key{Code: KeyEsc}, Key{Code: KeyEsc})
+
+	if m.page != pageAccounts {
+		t.Errorf("page = %d after going back twice, want accounts", m.page)
+	}
+
+	press(m, Key{Rune: 'q'})
+
+	if !m.quit {
+		t.Error("q did not quit")
+	}
+}
+
+func TestDashboardSync(t *testing.T) {
+	t.Parallel()
+
+	src := &fakeSource{syncStatuses: []string{"pending", "running", "success"}}
+	m := newTestModel(src)
+
+	press(m, Key{Rune: 's'})
+
+	if m.syncs["a1"] != "success" || m.status != "Synchronized Main" {
+		t.Errorf("sync status %q (%q), want success", m.syncs["a1"], m.status)
+	}
+
+	if src.accountLoads != 2 {
+		t.Errorf("accounts loaded %d times, want a reload after the sync", src.accountLoads)
+	}
+}
+
+func TestDashboardView(t *testing.T) {
+	t.Parallel()
+
+	m := newTestModel(&fakeSource{})
+	m.update(resizeMsg{width: 72, height: 10})
+
+	want := []string{
+		"ponto › default › Accounts",
+		"NAME             IBAN                           BALANCE  CUR  SYNC      ",
+		"Main             BE68539007547034               1250.00  EUR            ",
+		"Savings          BE71096123456769               5000.00  EUR            ",
+		"", "", "", "", "",
+		"↑/↓ move · enter transactions · p pending · s sync · r refresh · q quit",
+	}
+
+	got := m.view()
+	if len(got) != len(want) {
+		t.Fatalf("view() has %d lines, want %d:\n%s", len(got), len(want), strings.Join(got, "\n"))
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
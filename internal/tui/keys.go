@@ -0,0 +1,156 @@
+// Package tui implements the interactive terminal parts of the CLI.
+package tui
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+// KeyCode identifies a key press; printable characters are KeyRune.
+type KeyCode int
+
+const (
+	KeyRune KeyCode = iota
+	KeyEnter
+	KeyEsc
+	KeyTab
+	KeyBackspace
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyPgUp
+	KeyPgDown
+	KeyHome
+	KeyEnd
+	KeyCtrlC
+	KeyCtrlN
+	KeyCtrlP
+	KeyCtrlU
+)
+
+// Key is a single decoded key press.
+type Key struct {
+	Code KeyCode
+	Rune rune
+}
+
+var escapeKeys = map[string]KeyCode{
+	"[A": KeyUp, "OA": KeyUp,
+	"[B": KeyDown, "OB": KeyDown,
+	"[C": KeyRight, "OC": KeyRight,
+	"[D": KeyLeft, "OD": KeyLeft,
+	"[H": KeyHome, "OH": KeyHome, "[1~": KeyHome,
+	"[F": KeyEnd, "OF": KeyEnd, "[4~": KeyEnd,
+	"[5~": KeyPgUp,
+	"[6~": KeyPgDown,
+}
+
+var controlKeys = map[byte]KeyCode{
+	'\r': KeyEnter,
+	'\n': KeyEnter,
+	'\t': KeyTab,
+	0x7f: KeyBackspace,
+	0x08: KeyBackspace,
+	0x03: KeyCtrlC,
+	0x0e: KeyCtrlN,
+	0x10: KeyCtrlP,
+	0x15: KeyCtrlU,
+	0x1b: KeyEsc,
+}
+
+// ParseKeys decodes the bytes of one terminal read into key presses.
+// Unknown escape sequences and control characters are dropped.
+func ParseKeys(b []byte) []Key {
+	var keys []Key
+
+	for len(b) > 0 {
+		if b[0] == 0x1b && len(b) > 1 {
+			if code, n, ok := parseEscape(b[1:]); ok {
+				keys = append(keys, Key{Code: code})
+				b = b[1+n:]
+
+				continue
+			}
+
+			if n, ok := csiLength(b[1:]); ok {
+				b = b[1+n:]
+
+				continue
+			}
+		}
+
+		if code, ok := controlKeys[b[0]]; ok {
+			keys = append(keys, Key{Code: code})
+			b = b[1:]
+
+			continue
+		}
+
+		r, size := utf8.DecodeRune(b)
+		if r >= 0x20 && r != utf8.RuneError {
+			keys = append(keys, Key{Code: KeyRune, Rune: r})
+		}
+
+		b = b[size:]
+	}
+
+	return keys
+}
+
+// parseEscape matches a known sequence at the start of b (after ESC) and
+// returns its key and length.
+func parseEscape(b []byte) (KeyCode, int, bool) {
+	for n := 2; n <= 3 && n <= len(b); n++ {
+		if code, ok := escapeKeys[string(b[:n])]; ok {
+			return code, n, true
+		}
+	}
+
+	return 0, 0, false
+}
+
+// csiLength returns the length of an unrecognised control sequence
+// ("[" parameters final-byte) at the start of b so it can be skipped.
+func csiLength(b []byte) (int, bool) {
+	if len(b) == 0 || b[0] != '[' {
+		return 0, false
+	}
+
+	for i := 1; i < len(b); i++ {
+		if b[i] >= 0x40 && b[i] <= 0x7e {
+			return i + 1, true
+		}
+	}
+
+	return 0, false
+}
+
+// KeyReader reads key presses from a terminal in raw mode.
+type KeyReader struct {
+	r       io.Reader
+	buf     [64]byte
+	pending []Key
+}
+
+// NewKeyReader returns a KeyReader reading from r.
+func NewKeyReader(r io.Reader) *KeyReader {
+	return &KeyReader{r: r}
+}
+
+// ReadKey blocks until the next key press.
+func (k *KeyReader) ReadKey() (Key, error) {
+	for len(k.pending) == 0 {
+		n, err := k.r.Read(k.buf[:])
+		if err != nil {
+			return Key{}, err
+		}
+
+		k.pending = ParseKeys(k.buf[:n])
+	}
+
+	key := k.pending[0]
+	k.pending = k.pending[1:]
+
+	return key, nil
+}
@@ -0,0 +1,34 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		in   string
+		want []Key
+	}{
+		{name: "runes", in: "ab€", want: []Key{{Rune: 'a'}, {Rune: 'b'}, {Rune: '€'}}},
+		{name: "arrows", in: "\x1b[A\x1bOB", want: []Key{{Code: KeyUp}, {Code: KeyDown}}},
+		{name: "page keys", in: "\x1b[5~\x1b[6~", want: []Key{{Code: KeyPgUp}, {Code: KeyPgDown}}},
+		{name: "lone escape", in: "\x1b", want: []Key{{Code: KeyEsc}}},
+		{name: "control keys", in: "\r\x7f\x03\x15", want: []Key{{Code: KeyEnter}, {Code: KeyBackspace}, {Code: KeyCtrlC}, {Code: KeyCtrlU}}},
+		{name: "unknown sequence skipped", in: "\x1b[1;5Cx", want: []Key{{Rune: 'x'}}},
+		{name: "unknown control dropped", in: "\x01y", want: []Key{{Rune: 'y'}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := ParseKeys([]byte(tt.in)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseKeys(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
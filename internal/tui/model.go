@@ -0,0 +1,562 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/muesli/termenv"
+
+	"github.com/dedene/ponto-cli/internal/api"
+	"github.com/dedene/ponto-cli/internal/output"
+)
+
+type page int
+
+const (
+	pageAccounts page = iota
+	pageTransactions
+	pagePending
+	pageDetail
+)
+
+// transaction type filters, cycled with "t".
+var kinds = []string{"all", "income", "expense"}
+
+// cursor is the selected row and scroll offset of a list.
+type cursor struct {
+	pos    int
+	offset int
+}
+
+// move moves the selection by delta rows within n rows, scrolling so that
+// it stays within the height visible rows.
+func (c *cursor) move(delta, n, height int) {
+	c.pos = max(0, min(c.pos+delta, n-1))
+
+	if c.pos < c.offset {
+		c.offset = c.pos
+	}
+
+	if height > 0 && c.pos >= c.offset+height {
+		c.offset = c.pos - height + 1
+	}
+}
+
+// model is the dashboard state. It does no I/O itself: API calls are
+// returned as tasks and their results come back through update.
+type model struct {
+	src     Source
+	profile string
+	width   int
+	height  int
+	color   termenv.Profile
+	poll    time.Duration
+
+	page page
+	back page // page the pending list returns to
+
+	accounts    []api.Account
+	accountsSel cursor
+	account     api.Account // account whose transactions are shown
+
+	txns      []api.Transaction
+	visible   []int // indexes into txns that pass the search and type filter
+	txSel     cursor
+	query     []rune
+	searching bool
+	kind      string
+
+	pending    []api.PendingTransaction
+	pendingSel cursor
+
+	detail []string
+
+	syncs   map[string]string // last known sync status per account ID
+	loading int
+	status  string
+	quit    bool
+}
+
+func newModel(src Source, profile string, width, height int, color termenv.Profile) *model {
+	return &model{
+		src:     src,
+		profile: profile,
+		width:   width,
+		height:  height,
+		color:   color,
+		poll:    syncPollInterval,
+		kind:    kinds[0],
+		syncs:   make(map[string]string),
+	}
+}
+
+func (m *model) init() []task {
+	return m.loadAccounts()
+}
+
+func (m *model) update(msg msg) []task {
+	switch msg := msg.(type) {
+	case keyMsg:
+		return m.handleKey(Key(msg))
+	case resizeMsg:
+		m.width, m.height = msg.width, msg.height
+		m.accountsSel.move(0, len(m.accounts), m.listHeight())
+		m.txSel.move(0, len(m.visible), m.listHeight())
+		m.pendingSel.move(0, len(m.pending), m.listHeight())
+	case accountsMsg:
+		m.loading--
+
+		if msg.err != nil {
+			m.status = "Load accounts: " + msg.err.Error()
+
+			return nil
+		}
+
+		m.accounts = msg.accounts
+		m.accountsSel.move(0, len(m.accounts), m.listHeight())
+	case transactionsMsg:
+		m.loading--
+
+		if msg.accountID != m.account.ID {
+			return nil
+		}
+
+		if msg.err != nil {
+			m.status = "Load transactions: " + msg.err.Error()
+
+			return nil
+		}
+
+		m.txns = msg.txns
+		m.refilter()
+	case pendingMsg:
+		m.loading--
+
+		if msg.accountID != m.account.ID {
+			return nil
+		}
+
+		if msg.err != nil {
+			m.status = "Load pending transactions: " + msg.err.Error()
+
+			return nil
+		}
+
+		m.pending = msg.pending
+		m.pendingSel.move(0, len(m.pending), m.listHeight())
+	case syncMsg:
+		return m.handleSync(msg)
+	}
+
+	return nil
+}
+
+func (m *model) handleKey(k Key) []task {
+	if k.Code == KeyCtrlC {
+		m.quit = true
+
+		return nil
+	}
+
+	if m.searching {
+		m.handleSearchKey(k)
+
+		return nil
+	}
+
+	switch m.page {
+	case pageAccounts:
+		return m.handleAccountsKey(k)
+	case pageTransactions:
+		return m.handleTransactionsKey(k)
+	case pagePending:
+		return m.handlePendingKey(k)
+	default:
+		m.handleDetailKey(k)
+
+		return nil
+	}
+}
+
+func (m *model) handleAccountsKey(k Key) []task {
+	if m.navigate(&m.accountsSel, len(m.accounts), k) {
+		return nil
+	}
+
+	a, ok := m.selectedAccount()
+
+	switch {
+	case isRune(k, 'q') || k.Code == KeyEsc:
+		m.quit = true
+	case isRune(k, 'r'):
+		return m.loadAccounts()
+	case !ok:
+		return nil
+	case k.Code == KeyEnter || k.Code == KeyRight || isRune(k, 'l'):
+		return m.openTransactions(a)
+	case isRune(k, 'p'):
+		m.account = a
+
+		return m.openPending(pageAccounts)
+	case isRune(k, 's'):
+		return m.startSync(a)
+	}
+
+	return nil
+}
+
+func (m *model) handleTransactionsKey(k Key) []task {
+	if m.navigate(&m.txSel, len(m.visible), k) {
+		return nil
+	}
+
+	switch {
+	case isRune(k, 'q'):
+		m.quit = true
+	case k.Code == KeyEsc && len(m.query) > 0:
+		m.query = nil
+		m.refilter()
+	case isBack(k):
+		m.page = pageAccounts
+	case k.Code == KeyEnter || k.Code == KeyRight || isRune(k, 'l'):
+		m.openDetail()
+	case isRune(k, '/'):
+		m.searching = true
+	case isRune(k, 't'):
+		m.kind = nextKind(m.kind)
+		m.refilter()
+	case isRune(k, 'p'):
+		return m.openPending(pageTransactions)
+	case isRune(k, 's'):
+		return m.startSync(m.account)
+	case isRune(k, 'r'):
+		return m.loadTransactions()
+	}
+
+	return nil
+}
+
+func (m *model) handleSearchKey(k Key) {
+	switch k.Code {
+	case KeyEnter:
+		m.searching = false
+	case KeyEsc:
+		m.searching = false
+		m.query = nil
+	case KeyBackspace:
+		if len(m.query) > 0 {
+			m.query = m.query[:len(m.query)-1]
+		}
+	case KeyCtrlU:
+		m.query = nil
+	case KeyRune:
+		m.query = append(m.query, k.Rune)
+	default:
+		return
+	}
+
+	m.refilter()
+}
+
+func (m *model) handlePendingKey(k Key) []task {
+	if m.navigate(&m.pendingSel, len(m.pending), k) {
+		return nil
+	}
+
+	switch {
+	case isRune(k, 'q'):
+		m.quit = true
+	case isBack(k):
+		m.page = m.back
+	case isRune(k, 's'):
+		return m.startSync(m.account)
+	case isRune(k, 'r'):
+		return m.openPending(m.back)
+	}
+
+	return nil
+}
+
+func (m *model) handleDetailKey(k Key) {
+	switch {
+	case isRune(k, 'q'):
+		m.quit = true
+	case isBack(k) || k.Code == KeyEnter:
+		m.page = pageTransactions
+	}
+}
+
+// navigate moves c for the list movement keys and reports whether k was one.
+func (m *model) navigate(c *cursor, n int, k Key) bool {
+	height := m.listHeight()
+
+	switch {
+	case k.Code == KeyUp || k.Code == KeyCtrlP || isRune(k, 'k'):
+		c.move(-1, n, height)
+	case k.Code == KeyDown || k.Code == KeyCtrlN || isRune(k, 'j'):
+		c.move(1, n, height)
+	case k.Code == KeyPgUp:
+		c.move(-height, n, height)
+	case k.Code == KeyPgDown:
+		c.move(height, n, height)
+	case k.Code == KeyHome || isRune(k, 'g'):
+		c.move(-n, n, height)
+	case k.Code == KeyEnd || isRune(k, 'G'):
+		c.move(n, n, height)
+	default:
+		return false
+	}
+
+	return true
+}
+
+func isRune(k Key, r rune) bool {
+	return k.Code == KeyRune && k.Rune == r
+}
+
+func isBack(k Key) bool {
+	return k.Code == KeyEsc || k.Code == KeyLeft || k.Code == KeyBackspace || isRune(k, 'h')
+}
+
+func nextKind(kind string) string {
+	for i, k := range kinds {
+		if k == kind {
+			return kinds[(i+1)%len(kinds)]
+		}
+	}
+
+	return kinds[0]
+}
+
+func (m *model) selectedAccount() (api.Account, bool) {
+	if len(m.accounts) == 0 {
+		return api.Account{}, false
+	}
+
+	return m.accounts[m.accountsSel.pos], true
+}
+
+func (m *model) openTransactions(a api.Account) []task {
+	m.page = pageTransactions
+	m.account = a
+	m.txns, m.visible = nil, nil
+	m.query, m.kind = nil, kinds[0]
+	m.txSel = cursor{}
+	m.status = ""
+
+	return m.loadTransactions()
+}
+
+func (m *model) openPending(back page) []task {
+	m.page = pagePending
+	m.back = back
+	m.pending = nil
+	m.pendingSel = cursor{}
+
+	m.loading++
+
+	id := m.account.ID
+
+	return []task{func(ctx context.Context) msg {
+		pending, err := m.src.PendingTransactions(ctx, id)
+
+		return pendingMsg{accountID: id, pending: pending, err: err}
+	}}
+}
+
+// openDetail shows the selected transaction the way 'transactions get'
+// prints it.
+func (m *model) openDetail() {
+	if len(m.visible) == 0 {
+		return
+	}
+
+	tx := m.txns[m.visible[m.txSel.pos]]
+
+	var buf bytes.Buffer
+
+	ctx := output.WithWriter(output.WithMode(context.Background(), output.ModeTable), &buf)
+	if err := output.Transaction(ctx, &tx); err != nil {
+		m.status = err.Error()
+
+		return
+	}
+
+	m.detail = append([]string{"Account:       " + accountLabel(m.account)}, strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")...)
+	m.page = pageDetail
+}
+
+func (m *model) loadAccounts() []task {
+	m.loading++
+
+	return []task{func(ctx context.Context) msg {
+		accounts, err := m.src.Accounts(ctx)
+
+		return accountsMsg{accounts: accounts, err: err}
+	}}
+}
+
+func (m *model) loadTransactions() []task {
+	m.loading++
+
+	id := m.account.ID
+
+	return []task{func(ctx context.Context) msg {
+		txns, err := m.src.Transactions(ctx, id)
+
+		return transactionsMsg{accountID: id, txns: txns, err: err}
+	}}
+}
+
+func (m *model) startSync(a api.Account) []task {
+	if syncRunning(m.syncs[a.ID]) {
+		m.status = "A synchronization of " + accountLabel(a) + " is already running"
+
+		return nil
+	}
+
+	m.syncs[a.ID] = "requested"
+	m.status = "Synchronizing " + accountLabel(a) + "…"
+
+	return []task{func(ctx context.Context) msg {
+		sync, err := m.src.CreateSync(ctx, a.ID)
+
+		return syncMsg{accountID: a.ID, sync: sync, err: err}
+	}}
+}
+
+// handleSync records a sync status and keeps polling until the sync ends;
+// a successful sync reloads the data it may have changed.
+func (m *model) handleSync(r syncMsg) []task {
+	name := m.accountName(r.accountID)
+
+	if r.err != nil {
+		m.syncs[r.accountID] = "failed"
+		m.status = "Sync " + name + ": " + r.err.Error()
+
+		return nil
+	}
+
+	s := r.sync
+	m.syncs[r.accountID] = s.Status
+
+	switch s.Status {
+	case "success":
+		m.status = "Synchronized " + name
+
+		tasks := m.loadAccounts()
+
+		if r.accountID == m.account.ID {
+			switch m.page {
+			case pageTransactions, pageDetail:
+				tasks = append(tasks, m.loadTransactions()...)
+			case pagePending:
+				tasks = append(tasks, m.openPending(m.back)...)
+			}
+		}
+
+		return tasks
+	case "error":
+		reasons := make([]string, len(s.Errors))
+		for i, e := range s.Errors {
+			reasons[i] = firstNonEmpty(e.Message, e.Code)
+		}
+
+		m.status = "Sync " + name + " failed"
+		if len(reasons) > 0 {
+			m.status += ": " + strings.Join(reasons, "; ")
+		}
+
+		return nil
+	}
+
+	m.status = fmt.Sprintf("Synchronizing %s… (%s)", name, s.Status)
+
+	return []task{func(ctx context.Context) msg {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(m.poll):
+		}
+
+		sync, err := m.src.GetSync(ctx, s.ID)
+
+		return syncMsg{accountID: r.accountID, sync: sync, err: err}
+	}}
+}
+
+func syncRunning(status string) bool {
+	switch status {
+	case "", "success", "error", "failed":
+		return false
+	default:
+		return true
+	}
+}
+
+func (m *model) accountName(id string) string {
+	for _, a := range m.accounts {
+		if a.ID == id {
+			return accountLabel(a)
+		}
+	}
+
+	return id
+}
+
+func accountLabel(a api.Account) string {
+	return firstNonEmpty(a.Description, a.Reference, a.ID)
+}
+
+// refilter recomputes the visible transactions: every word of the search
+// must occur in the date, counterpart, communication or amount.
+func (m *model) refilter() {
+	words := strings.Fields(strings.ToLower(string(m.query)))
+
+	m.visible = m.visible[:0]
+
+	for i, tx := range m.txns {
+		if m.kind == "income" && tx.Amount <= 0 || m.kind == "expense" && tx.Amount >= 0 {
+			continue
+		}
+
+		text := strings.ToLower(strings.Join([]string{
+			tx.ExecutionDate, tx.CounterpartName, tx.CounterpartRef, tx.Description,
+			output.Communication(tx), formatMoney(tx.Amount),
+		}, " "))
+
+		if matchAll(words, text, strings.Contains) {
+			m.visible = append(m.visible, i)
+		}
+	}
+
+	m.txSel = cursor{}
+}
+
+// listHeight is the number of list rows that fit between the title,
+// column header, status and help lines.
+func (m *model) listHeight() int {
+	chrome := 4
+	if m.page == pagePending && len(m.pending) > 0 {
+		chrome++
+	}
+
+	return max(1, m.height-chrome)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
+
+func formatMoney(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
@@ -0,0 +1,268 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/muesli/termenv"
+	"golang.org/x/term"
+)
+
+// ErrCancelled is returned when the user dismisses a prompt.
+var ErrCancelled = errors.New("cancelled")
+
+const (
+	pickerHeight = 10   // items shown at once
+	maxWidth     = 1024 // line width when the terminal size is unknown
+)
+
+// Item is a choice in a picker. Both fields are matched by the filter.
+type Item struct {
+	Title  string
+	Detail string
+}
+
+// Pick shows items in an inline picker on out and returns the index of the
+// chosen item. Typing filters the items; in must be a terminal and is put in
+// raw mode while the picker is shown.
+func Pick(in *os.File, out io.Writer, prompt string, items []Item) (int, error) {
+	fd := int(in.Fd())
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return -1, fmt.Errorf("enter raw mode: %w", err)
+	}
+	defer func() { _ = term.Restore(fd, state) }()
+
+	p := newPicker(items, pickerHeight)
+	keys := NewKeyReader(in)
+	profile := ColorProfile(out)
+	width, _, _ := term.GetSize(fd)
+
+	fmt.Fprint(out, hideCursor)
+	defer fmt.Fprint(out, showCursor)
+
+	lines := 0
+
+	for {
+		lines = redraw(out, lines, p.view(prompt, profile, width))
+
+		key, err := keys.ReadKey()
+		if err != nil {
+			redraw(out, lines, nil)
+
+			return -1, err
+		}
+
+		switch p.handle(key) {
+		case pickChosen:
+			redraw(out, lines, nil)
+
+			return p.selected(), nil
+		case pickCancelled:
+			redraw(out, lines, nil)
+
+			return -1, ErrCancelled
+		}
+	}
+}
+
+const (
+	hideCursor = "\x1b[?25l"
+	showCursor = "\x1b[?25h"
+)
+
+// ColorProfile returns the color profile for out, honouring NO_COLOR.
+func ColorProfile(out io.Writer) termenv.Profile {
+	if termenv.EnvNoColor() {
+		return termenv.Ascii
+	}
+
+	return termenv.NewOutput(out, termenv.WithProfile(termenv.EnvColorProfile())).Profile
+}
+
+// redraw replaces the prev lines written by the last redraw with lines and
+// returns the number of lines now on screen. The cursor stays on the last
+// line, in column one.
+func redraw(out io.Writer, prev int, lines []string) int {
+	if prev > 1 {
+		fmt.Fprintf(out, "\x1b[%dA", prev-1)
+	}
+
+	fmt.Fprint(out, "\r\x1b[J", strings.Join(lines, "\r\n"))
+
+	return len(lines)
+}
+
+type pickAction int
+
+const (
+	pickNone pickAction = iota
+	pickChosen
+	pickCancelled
+)
+
+// picker is the state of a Pick prompt, kept apart from the terminal so it
+// can be tested.
+type picker struct {
+	items   []Item
+	query   []rune
+	matches []int  // indexes into items, best match first
+	sel     cursor // position in matches
+	height  int
+}
+
+func newPicker(items []Item, height int) *picker {
+	p := &picker{items: items, height: height}
+	p.filter()
+
+	return p
+}
+
+// filter recomputes the matches for the query: items containing every
+// word of the query first, then items whose title contains the letters of
+// every word in order. Details, typically numbers, only match exactly.
+func (p *picker) filter() {
+	words := strings.Fields(strings.ToLower(string(p.query)))
+
+	var exact, fuzzy []int
+
+	for i, item := range p.items {
+		title := strings.ToLower(item.Title)
+
+		switch {
+		case matchAll(words, title+" "+strings.ToLower(item.Detail), strings.Contains):
+			exact = append(exact, i)
+		case matchAll(words, title, func(s, word string) bool { return isSubsequence(word, s) }):
+			fuzzy = append(fuzzy, i)
+		}
+	}
+
+	p.matches = append(exact, fuzzy...)
+	p.sel = cursor{}
+}
+
+func matchAll(words []string, text string, match func(s, word string) bool) bool {
+	for _, w := range words {
+		if !match(text, w) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isSubsequence reports whether the runes of sub appear in s in order.
+func isSubsequence(sub, s string) bool {
+	rest := []rune(sub)
+	if len(rest) == 0 {
+		return true
+	}
+
+	for _, r := range s {
+		if r == rest[0] {
+			rest = rest[1:]
+			if len(rest) == 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (p *picker) handle(key Key) pickAction {
+	switch key.Code {
+	case KeyEnter:
+		if len(p.matches) > 0 {
+			return pickChosen
+		}
+	case KeyEsc, KeyCtrlC:
+		return pickCancelled
+	case KeyUp, KeyCtrlP:
+		p.sel.move(-1, len(p.matches), p.height)
+	case KeyDown, KeyCtrlN, KeyTab:
+		p.sel.move(1, len(p.matches), p.height)
+	case KeyPgUp:
+		p.sel.move(-p.height, len(p.matches), p.height)
+	case KeyPgDown:
+		p.sel.move(p.height, len(p.matches), p.height)
+	case KeyHome:
+		p.sel.move(-len(p.matches), len(p.matches), p.height)
+	case KeyEnd:
+		p.sel.move(len(p.matches), len(p.matches), p.height)
+	case KeyBackspace:
+		if len(p.query) > 0 {
+			p.query = p.query[:len(p.query)-1]
+			p.filter()
+		}
+	case KeyCtrlU:
+		p.query = nil
+		p.filter()
+	case KeyRune:
+		p.query = append(p.query, key.Rune)
+		p.filter()
+	}
+
+	return pickNone
+}
+
+// selected returns the index of the item under the cursor.
+func (p *picker) selected() int {
+	return p.matches[p.sel.pos]
+}
+
+// view renders the prompt, the visible matches and a help line, each cut
+// to width runes (0 for no limit) so that lines never wrap.
+func (p *picker) view(prompt string, profile termenv.Profile, width int) []string {
+	faint := func(s string) string { return profile.String(s).Faint().String() }
+	bold := func(s string) string { return profile.String(s).Bold().String() }
+
+	if width <= 0 {
+		width = maxWidth
+	}
+
+	lines := []string{bold("? ") + fit(prompt+" "+string(p.query), width-2)}
+
+	end := min(p.sel.offset+p.height, len(p.matches))
+
+	for i := p.sel.offset; i < end; i++ {
+		item := p.items[p.matches[i]]
+
+		title := fit(item.Title, width-2)
+		detail := fit(item.Detail, width-4-utf8.RuneCountInString(title))
+
+		if i == p.sel.pos {
+			lines = append(lines, bold("> ")+bold(title)+"  "+faint(detail))
+		} else {
+			lines = append(lines, "  "+title+"  "+faint(detail))
+		}
+	}
+
+	if len(p.matches) == 0 {
+		lines = append(lines, faint("  no matches"))
+	}
+
+	help := fmt.Sprintf("  %d/%d · ↑/↓ move · type to filter · enter select · esc cancel", len(p.matches), len(p.items))
+
+	return append(lines, faint(fit(help, width)))
+}
+
+// fit cuts s to n runes, ending in an ellipsis when cut.
+func fit(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	if n <= 0 {
+		return ""
+	}
+
+	r := []rune(s)
+
+	return string(r[:n-1]) + "…"
+}
@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/muesli/termenv"
+)
+
+var testItems = []Item{
+	{Title: "Main Account", Detail: "BE68539007547034  1250.00 EUR"},
+	{Title: "Savings", Detail: "BE71096123456769  5000.00 EUR"},
+	{Title: "Operations", Detail: "BE62510007547034  -20.50 EUR"},
+}
+
+func TestPickerFilter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{query: "", want: []int{0, 1, 2}},
+		{query: "sav", want: []int{1}},
+		{query: "7034", want: []int{0, 2}},
+		{query: "5000", want: []int{1}},
+		{query: "ops", want: []int{2}},
+		{query: "main 7034", want: []int{0}},
+		{query: "mnacc", want: []int{0}},
+		{query: "payroll", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			t.Parallel()
+
+			p := newPicker(testItems, pickerHeight)
+			for _, r := range tt.query {
+				p.handle(Key{Rune: r})
+			}
+
+			if !reflect.DeepEqual(p.matches, tt.want) {
+				t.Errorf("matches for %q = %v, want %v", tt.query, p.matches, tt.want)
+			}
+		})
+	}
+}
+
+func TestPickerHandle(t *testing.T) {
+	t.Parallel()
+
+	p := newPicker(testItems, 2)
+
+	p.handle(Key{Code: KeyDown})
+	p.handle(Key{Code: KeyDown})
+	p.handle(Key{Code: KeyDown})
+
+	if p.sel != (cursor{pos: 2, offset: 1}) {
+		t.Errorf("selection = %+v, want pos 2, offset 1", p.sel)
+	}
+
+	if got := p.handle(Key{Code: KeyEnter}); got != pickChosen || p.selected() != 2 {
+		t.Errorf("enter = %v selecting %d, want chosen selecting 2", got, p.selected())
+	}
+
+	p.handle(Key{Rune: 'x'})
+	p.handle(Key{Rune: 'y'})
+
+	if got := p.handle(Key{Code: KeyEnter}); got != pickNone {
+		t.Errorf("enter without matches = %v, want none", got)
+	}
+
+	p.handle(Key{Code: KeyCtrlU})
+
+	if len(p.matches) != 3 {
+		t.Errorf("matches after clearing the query = %v, want all", p.matches)
+	}
+
+	if got := p.handle(Key{Code: KeyEsc}); got != pickCancelled {
+		t.Errorf("esc = %v, want cancelled", got)
+	}
+}
+
+func TestPickerView(t *testing.T) {
+	t.Parallel()
+
+	p := newPicker(testItems, pickerHeight)
+	p.handle(Key{Code: KeyDown})
+
+	lines := p.view("Select an account:", termenv.Ascii, 24)
+
+	want := []string{
+		"? Select an account: ",
+		"  Main Account  BE68539…",
+		"> Savings  BE7109612345…",
+		"  Operations  BE6251000…",
+		"  3/3 · ↑/↓ move · type…",
+	}
+
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("view() =\n%s\nwant\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+}
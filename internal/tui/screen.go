@@ -0,0 +1,76 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+const (
+	enterAltScreen = "\x1b[?1049h"
+	exitAltScreen  = "\x1b[?1049l"
+	clearLine      = "\x1b[K"
+	clearBelow     = "\x1b[J"
+	cursorHome     = "\x1b[H"
+)
+
+// screen is a full-screen terminal session on the alternate screen buffer.
+type screen struct {
+	fd    int
+	state *term.State
+	out   io.Writer
+}
+
+// openScreen puts in in raw mode and switches out to the alternate screen.
+func openScreen(in *os.File, out io.Writer) (*screen, error) {
+	fd := int(in.Fd())
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, fmt.Errorf("enter raw mode: %w", err)
+	}
+
+	fmt.Fprint(out, enterAltScreen, hideCursor)
+
+	return &screen{fd: fd, state: state, out: out}, nil
+}
+
+// size returns the terminal size, falling back to 80x24.
+func (s *screen) size() (int, int) {
+	w, h, err := term.GetSize(s.fd)
+	if err != nil || w <= 0 || h <= 0 {
+		return 80, 24
+	}
+
+	return w, h
+}
+
+// draw replaces the screen contents with lines in a single write.
+func (s *screen) draw(lines []string) {
+	var b strings.Builder
+
+	b.WriteString(cursorHome)
+
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString("\r\n")
+		}
+
+		b.WriteString(line)
+		b.WriteString(clearLine)
+	}
+
+	b.WriteString(clearBelow)
+
+	io.WriteString(s.out, b.String())
+}
+
+// close restores the main screen and the terminal mode.
+func (s *screen) close() {
+	fmt.Fprint(s.out, showCursor, exitAltScreen)
+
+	_ = term.Restore(s.fd, s.state)
+}
@@ -0,0 +1,254 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dedene/ponto-cli/internal/output"
+)
+
+// column is a column of a dashboard table; a zero width shares the space
+// left by the fixed-width columns.
+type column struct {
+	title string
+	width int
+	right bool
+}
+
+const columnGap = "  "
+
+var (
+	accountColumns = []column{
+		{title: "NAME"},
+		{title: "IBAN", width: 22},
+		{title: "BALANCE", width: 14, right: true},
+		{title: "CUR", width: 3},
+		{title: "SYNC", width: 10},
+	}
+	transactionColumns = []column{
+		{title: "DATE", width: 10},
+		{title: "COUNTERPART"},
+		{title: "COMMUNICATION"},
+		{title: "AMOUNT", width: 12, right: true},
+	}
+	pendingColumns = []column{
+		{title: "DATE", width: 10},
+		{title: "COUNTERPART"},
+		{title: "DESCRIPTION"},
+		{title: "AMOUNT", width: 12, right: true},
+	}
+)
+
+var help = map[page]string{
+	pageAccounts:     "↑/↓ move · enter transactions · p pending · s sync · r refresh · q quit",
+	pageTransactions: "↑/↓ move · enter details · / search · t type · p pending · s sync · r reload · esc back · q quit",
+	pagePending:      "↑/↓ move · s sync · r reload · esc back · q quit",
+	pageDetail:       "esc back · q quit",
+}
+
+// view renders the whole screen: a title line, the page, a status line and
+// a help line.
+func (m *model) view() []string {
+	height := max(m.height, 4)
+
+	lines := []string{m.bold(fit(m.title(), m.width))}
+
+	switch m.page {
+	case pageAccounts:
+		lines = append(lines, m.accountsView()...)
+	case pageTransactions:
+		lines = append(lines, m.transactionsView()...)
+	case pagePending:
+		lines = append(lines, m.pendingView()...)
+	case pageDetail:
+		for _, line := range m.detail {
+			lines = append(lines, fit(line, m.width))
+		}
+	}
+
+	for len(lines) < height-2 {
+		lines = append(lines, "")
+	}
+
+	lines = lines[:height-2]
+
+	return append(lines, fit(m.statusLine(), m.width), m.faint(fit(help[m.page], m.width)))
+}
+
+func (m *model) title() string {
+	parts := []string{"ponto", m.profile, "Accounts"}
+
+	if m.page != pageAccounts {
+		parts = append(parts, accountLabel(m.account))
+	}
+
+	switch m.page {
+	case pagePending:
+		parts = append(parts, "Pending")
+	case pageDetail:
+		parts = append(parts, "Transaction")
+	}
+
+	return strings.Join(parts, " › ")
+}
+
+func (m *model) statusLine() string {
+	switch {
+	case m.searching:
+		return "/" + string(m.query) + "▏"
+	case m.status != "":
+		return m.status
+	case m.loading > 0:
+		return "Loading…"
+	case m.page == pageTransactions:
+		s := fmt.Sprintf("%d of %d transactions", len(m.visible), len(m.txns))
+		if m.kind != kinds[0] {
+			s += " · " + m.kind
+		}
+
+		if len(m.query) > 0 {
+			s += fmt.Sprintf(" · matching %q", string(m.query))
+		}
+
+		return s
+	default:
+		return ""
+	}
+}
+
+func (m *model) accountsView() []string {
+	rows := make([][]string, len(m.accounts))
+	for i, a := range m.accounts {
+		rows[i] = []string{accountLabel(a), a.Reference, formatMoney(a.CurrentBalance), a.Currency, m.syncs[a.ID]}
+	}
+
+	return m.table(accountColumns, rows, m.accountsSel, "No accounts")
+}
+
+func (m *model) transactionsView() []string {
+	rows := make([][]string, len(m.visible))
+	for i, idx := range m.visible {
+		tx := m.txns[idx]
+		rows[i] = []string{day(tx.ExecutionDate), tx.CounterpartName, output.Communication(tx), formatMoney(tx.Amount)}
+	}
+
+	empty := "No transactions"
+	if len(m.txns) > 0 {
+		empty = "No matching transactions"
+	}
+
+	return m.table(transactionColumns, rows, m.txSel, empty)
+}
+
+func (m *model) pendingView() []string {
+	var lines []string
+
+	if len(m.pending) > 0 {
+		lines = append(lines, m.warn(fit(output.PendingWarning, m.width)))
+	}
+
+	rows := make([][]string, len(m.pending))
+	for i, tx := range m.pending {
+		rows[i] = []string{day(tx.ValueDate), tx.CounterpartName, tx.Description, formatMoney(tx.Amount)}
+	}
+
+	return append(lines, m.table(pendingColumns, rows, m.pendingSel, "No pending transactions")...)
+}
+
+// table renders the column header and the visible rows, highlighting the
+// selected one. Without rows it shows empty, or a loading note.
+func (m *model) table(cols []column, rows [][]string, sel cursor, empty string) []string {
+	widths := columnWidths(cols, m.width)
+
+	titles := make([]string, len(cols))
+	for i, c := range cols {
+		titles[i] = c.title
+	}
+
+	lines := []string{m.bold(formatRow(cols, widths, titles))}
+
+	if len(rows) == 0 {
+		if m.loading > 0 {
+			empty = "Loading…"
+		}
+
+		return append(lines, m.faint("  "+empty))
+	}
+
+	end := min(sel.offset+m.listHeight(), len(rows))
+
+	for i := sel.offset; i < end; i++ {
+		line := formatRow(cols, widths, rows[i])
+		if i == sel.pos {
+			line = m.color.String(line).Reverse().String()
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// columnWidths gives the fixed columns their width and splits the rest of
+// width evenly between the others.
+func columnWidths(cols []column, width int) []int {
+	widths := make([]int, len(cols))
+	rest := width - len(columnGap)*(len(cols)-1)
+	flex := 0
+
+	for i, c := range cols {
+		widths[i] = c.width
+		rest -= c.width
+
+		if c.width == 0 {
+			flex++
+		}
+	}
+
+	for i, c := range cols {
+		if c.width == 0 {
+			widths[i] = max(rest/flex, 4)
+		}
+	}
+
+	return widths
+}
+
+func formatRow(cols []column, widths []int, values []string) string {
+	cells := make([]string, len(cols))
+
+	for i, c := range cols {
+		v := fit(strings.ReplaceAll(values[i], "\n", " "), widths[i])
+		gap := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(v))
+
+		if c.right {
+			cells[i] = gap + v
+		} else {
+			cells[i] = v + gap
+		}
+	}
+
+	return strings.Join(cells, columnGap)
+}
+
+// day returns the date part of an ISO 8601 timestamp.
+func day(s string) string {
+	if len(s) >= 10 {
+		return s[:10]
+	}
+
+	return s
+}
+
+func (m *model) bold(s string) string {
+	return m.color.String(s).Bold().String()
+}
+
+func (m *model) faint(s string) string {
+	return m.color.String(s).Faint().String()
+}
+
+func (m *model) warn(s string) string {
+	return m.color.String(s).Foreground(m.color.Color("3")).String()
+}
@@ -0,0 +1,101 @@
+package watch
+
+import (
+	"github.com/dedene/ponto-cli/internal/api"
+	"github.com/dedene/ponto-cli/internal/output"
+)
+
+// Event types.
+const (
+	EventTransaction        = "transaction"
+	EventPendingTransaction = "pendingTransaction"
+)
+
+// Event is a new booked or pending transaction. Its fields are flat so
+// hooks can template them directly, e.g. {{.Amount}}.
+type Event struct {
+	Type                 string  `json:"type"`
+	Profile              string  `json:"profile"`
+	AccountID            string  `json:"accountId"`
+	Account              string  `json:"account"`
+	IBAN                 string  `json:"iban"`
+	ID                   string  `json:"id"`
+	Amount               float64 `json:"amount"`
+	Currency             string  `json:"currency"`
+	CounterpartName      string  `json:"counterpartName"`
+	CounterpartReference string  `json:"counterpartReference"`
+	Description          string  `json:"description"`
+	Communication        string  `json:"communication"`
+	ExecutionDate        string  `json:"executionDate,omitempty"`
+	ValueDate            string  `json:"valueDate"`
+}
+
+// SyncResult is the outcome of a synchronization started by the watcher.
+// Status is the synchronization's final status, or "failed" when it could
+// not be created or followed.
+type SyncResult struct {
+	Profile   string   `json:"profile"`
+	AccountID string   `json:"accountId"`
+	Account   string   `json:"account"`
+	IBAN      string   `json:"iban"`
+	ID        string   `json:"id,omitempty"`
+	Status    string   `json:"status"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+func syncResult(profile string, a api.Account, sync *api.Synchronization, err error) SyncResult {
+	r := SyncResult{Profile: profile, AccountID: a.ID, Account: a.Description, IBAN: a.Reference, Status: "failed"}
+
+	if sync != nil {
+		r.ID = sync.ID
+		r.Status = sync.Status
+
+		for _, e := range sync.Errors {
+			r.Errors = append(r.Errors, firstNonEmpty(e.Message, e.Code))
+		}
+	}
+
+	if err != nil {
+		r.Status = "failed"
+		r.Errors = append(r.Errors, err.Error())
+	}
+
+	return r
+}
+
+func transactionEvent(profile string, a api.Account, tx api.Transaction) Event {
+	return Event{
+		Type:                 EventTransaction,
+		Profile:              profile,
+		AccountID:            a.ID,
+		Account:              a.Description,
+		IBAN:                 a.Reference,
+		ID:                   tx.ID,
+		Amount:               tx.Amount,
+		Currency:             tx.Currency,
+		CounterpartName:      tx.CounterpartName,
+		CounterpartReference: tx.CounterpartRef,
+		Description:          tx.Description,
+		Communication:        output.Communication(tx),
+		ExecutionDate:        tx.ExecutionDate,
+		ValueDate:            tx.ValueDate,
+	}
+}
+
+func pendingEvent(profile string, a api.Account, tx api.PendingTransaction) Event {
+	return Event{
+		Type:                 EventPendingTransaction,
+		Profile:              profile,
+		AccountID:            a.ID,
+		Account:              a.Description,
+		IBAN:                 a.Reference,
+		ID:                   tx.ID,
+		Amount:               tx.Amount,
+		Currency:             tx.Currency,
+		CounterpartName:      tx.CounterpartName,
+		CounterpartReference: tx.CounterpartRef,
+		Description:          tx.Description,
+		Communication:        tx.RemittanceInfo,
+		ValueDate:            tx.ValueDate,
+	}
+}
@@ -0,0 +1,210 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/dedene/ponto-cli/internal/output"
+)
+
+// Hook runs a command for each event. Every word of the command line is a
+// Go template over the Event, rendered on its own, so transaction data
+// never passes through a shell. The event is also written to the command's
+// stdin as JSON.
+type Hook struct {
+	args []*template.Template
+}
+
+// ParseHook parses a command line such as `notify.sh {{.Amount}}`. Words
+// are split on spaces outside quotes and template actions.
+func ParseHook(cmdline string) (*Hook, error) {
+	words, err := splitWords(cmdline)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(words) == 0 {
+		return nil, errors.New("empty hook command")
+	}
+
+	h := &Hook{}
+
+	for _, w := range words {
+		tmpl, err := output.ParseTemplate(w)
+		if err != nil {
+			return nil, err
+		}
+
+		h.args = append(h.args, tmpl)
+	}
+
+	return h, nil
+}
+
+// Run runs the hook for e, passing through its output.
+func (h *Hook) Run(ctx context.Context, e Event) error {
+	args := make([]string, len(h.args))
+
+	for i, tmpl := range h.args {
+		var b strings.Builder
+		if err := tmpl.Execute(&b, e); err != nil {
+			return fmt.Errorf("execute hook template: %w", err)
+		}
+
+		args[i] = b.String()
+	}
+
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("hook %s: %w", args[0], err)
+	}
+
+	return nil
+}
+
+// splitWords splits s on unquoted spaces. Single and double quotes group
+// words and are removed, except inside {{ }} where they belong to the
+// template.
+func splitWords(s string) ([]string, error) {
+	var (
+		words   []string
+		word    strings.Builder
+		inWord  bool
+		quote   rune
+		actions int
+	)
+
+	rs := []rune(s)
+
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+
+		switch {
+		case r == '{' && i+1 < len(rs) && rs[i+1] == '{':
+			actions++
+			word.WriteString("{{")
+			inWord = true
+			i++
+		case r == '}' && actions > 0 && i+1 < len(rs) && rs[i+1] == '}':
+			actions--
+			word.WriteString("}}")
+			i++
+		case actions > 0:
+			word.WriteRune(r)
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in %q", quote, s)
+	}
+
+	if actions > 0 {
+		return nil, fmt.Errorf("unterminated {{ in %q", s)
+	}
+
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words, nil
+}
+
+// Notify shows e as a desktop notification.
+func Notify(ctx context.Context, e Event) error {
+	title, body := notification(e)
+
+	var cmd *exec.Cmd
+
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleString(body), appleString(title))
+		cmd = exec.CommandContext(ctx, "osascript", "-e", script)
+	default:
+		cmd = exec.CommandContext(ctx, "notify-send", "--app-name=ponto", title, body)
+	}
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("desktop notification: %w: %s", err, bytes.TrimSpace(out))
+	}
+
+	return nil
+}
+
+// CheckNotify reports whether desktop notifications can be shown.
+func CheckNotify() error {
+	name := "notify-send"
+	if runtime.GOOS == "darwin" {
+		name = "osascript"
+	}
+
+	if _, err := exec.LookPath(name); err != nil {
+		return fmt.Errorf("desktop notifications need %s: %w", name, err)
+	}
+
+	return nil
+}
+
+func notification(e Event) (string, string) {
+	title := fmt.Sprintf("%+.2f %s %s", e.Amount, e.Currency, firstNonEmpty(e.CounterpartName, e.Description))
+	if e.Type == EventPendingTransaction {
+		title = "Pending: " + title
+	}
+
+	body := firstNonEmpty(e.Communication, e.Description)
+	if e.Account != "" {
+		body = strings.TrimSpace(body + "\n" + e.Account)
+	}
+
+	return title, body
+}
+
+// appleString quotes s as an AppleScript string literal.
+func appleString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,74 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSplitWords(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{in: "notify.sh {{.Amount}}", want: []string{"notify.sh", "{{.Amount}}"}},
+		{in: `notify.sh {{printf "%.2f" .Amount}} --to "ops team"`, want: []string{"notify.sh", `{{printf "%.2f" .Amount}}`, "--to", "ops team"}},
+		{in: `say 'got {{.Amount}} from {{.CounterpartName}}'`, want: []string{"say", "got {{.Amount}} from {{.CounterpartName}}"}},
+		{in: "  a   b  ", want: []string{"a", "b"}},
+		{in: `a ""`, want: []string{"a", ""}},
+		{in: `a "b`, wantErr: true},
+		{in: "a {{.Amount", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := splitWords(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitWords(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !slices.Equal(got, tt.want) {
+				t.Errorf("splitWords(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHookRun(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("needs sh")
+	}
+
+	out := filepath.Join(t.TempDir(), "out")
+
+	hook, err := ParseHook(`sh -c 'printf "%s|%s|" "$1" "$2" > "$3"; cat >> "$3"' sh {{money .Amount}} {{.CounterpartName}} ` + out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := Event{Type: EventTransaction, ID: "t1", Amount: -12.5, CounterpartName: "Shop; rm -rf /"}
+	if err := hook.Run(context.Background(), e); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `-12.50|Shop; rm -rf /|{"type":"transaction"`
+	if got := string(b); !strings.HasPrefix(got, want) {
+		t.Errorf("hook got %q, want prefix %q", got, want)
+	}
+}
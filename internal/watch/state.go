@@ -0,0 +1,111 @@
+package watch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/dedene/ponto-cli/internal/config"
+)
+
+// State is the high-water mark of a watcher: per account, the newest
+// transaction and the pending transactions already reported, and when the
+// account was last synchronized.
+type State struct {
+	Accounts map[string]*AccountState `json:"accounts"`
+
+	path string
+}
+
+// AccountState is the state of one watched account.
+type AccountState struct {
+	LastTransactionID string    `json:"lastTransactionId,omitempty"`
+	LastValueDate     string    `json:"lastValueDate,omitempty"`
+	Pending           []string  `json:"pending,omitempty"`
+	LastSync          time.Time `json:"lastSync"`
+}
+
+func (a *AccountState) clone() *AccountState {
+	c := *a
+	c.Pending = slices.Clone(a.Pending)
+
+	return &c
+}
+
+// StatePath returns the state file of the named consumer (e.g. "watch") for
+// a profile. Each consumer keeps its own marks so they don't steal each
+// other's transactions.
+func StatePath(consumer, profile string) (string, error) {
+	dir, err := config.StateDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, consumer, url.PathEscape(profile)+".json"), nil
+}
+
+// LoadState reads the state at path. A missing file is an empty state.
+func LoadState(path string) (*State, error) {
+	s := &State{Accounts: map[string]*AccountState{}, path: path}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("read state: %w", err)
+	}
+
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("parse state %s: %w", path, err)
+	}
+
+	if s.Accounts == nil {
+		s.Accounts = map[string]*AccountState{}
+	}
+
+	return s, nil
+}
+
+// Clone returns a deep copy of the state that saves to the same file.
+func (s *State) Clone() *State {
+	c := &State{Accounts: make(map[string]*AccountState, len(s.Accounts)), path: s.path}
+
+	for id, a := range s.Accounts {
+		c.Accounts[id] = a.clone()
+	}
+
+	return c
+}
+
+// Save writes the state back to the file it was loaded from, atomically.
+func (s *State) Save() error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("ensure state dir: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+
+	if err := os.WriteFile(tmp, append(b, '\n'), 0o600); err != nil {
+		return fmt.Errorf("write state: %w", err)
+	}
+
+	if err := os.Rename(tmp, s.path); err != nil {
+		_ = os.Remove(tmp)
+
+		return fmt.Errorf("commit state: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,235 @@
+// Package watch polls Ponto for transactions that appeared since the last
+// poll, keeping its high-water marks on disk so restarts don't replay them.
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/dedene/ponto-cli/internal/api"
+)
+
+// lookback is how far before the last seen transaction's value date a poll
+// searches for it, bounding the scan when that transaction has vanished.
+const lookback = 7 * 24 * time.Hour
+
+var errFoundMark = errors.New("found high-water mark")
+
+// Source is the part of api.Client the watcher needs.
+type Source interface {
+	ListAccounts(ctx context.Context) ([]api.Account, error)
+	EachTransactionPage(ctx context.Context, accountID string, opts api.TransactionListOptions, fn func([]api.Transaction) error) error
+	ListPendingTransactions(ctx context.Context, accountID string) ([]api.PendingTransaction, error)
+	CreateSync(ctx context.Context, accountID, subtype string) (*api.Synchronization, error)
+	WaitForSync(ctx context.Context, id string) (*api.Synchronization, error)
+}
+
+// Watcher finds the transactions of a profile's accounts that are newer
+// than its state.
+type Watcher struct {
+	Source  Source
+	State   *State
+	Profile string
+
+	// AccountIDs limits the watched accounts; empty watches them all.
+	AccountIDs []string
+
+	// Sync synchronizes each account before polling it, at most once per
+	// SyncCooldown.
+	Sync         bool
+	SyncCooldown time.Duration
+
+	// OnSync, when set, receives the outcome of every synchronization.
+	OnSync func(SyncResult)
+
+	// Now returns the current time; nil means time.Now.
+	Now func() time.Time
+}
+
+// Poll returns the transactions that appeared since the previous poll,
+// oldest first, and advances the state past them. Accounts polled for the
+// first time only record their current transactions. The caller saves the
+// state once it has handled the events.
+func (w *Watcher) Poll(ctx context.Context) ([]Event, error) {
+	accounts, err := w.Source.ListAccounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list accounts: %w", err)
+	}
+
+	var (
+		events []Event
+		errs   []error
+	)
+
+	for _, a := range accounts {
+		if len(w.AccountIDs) > 0 && !slices.Contains(w.AccountIDs, a.ID) {
+			continue
+		}
+
+		found, err := w.pollAccount(ctx, a)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("account %s: %w", a.ID, err))
+		}
+
+		events = append(events, found...)
+	}
+
+	return events, errors.Join(errs...)
+}
+
+// pollAccount polls one account on a copy of its state, which replaces the
+// stored state only when the whole poll succeeds: a failure leaves the marks
+// where they were, so the next poll reports the same transactions. A new
+// account is only stored then too, so a failed first poll doesn't turn its
+// history into new transactions.
+func (w *Watcher) pollAccount(ctx context.Context, a api.Account) ([]Event, error) {
+	st := &AccountState{}
+
+	prev, known := w.State.Accounts[a.ID]
+	if known {
+		st = prev.clone()
+	}
+
+	if w.Sync {
+		w.sync(ctx, a, st)
+
+		// The cooldown holds even if the poll fails, so a failing poll
+		// doesn't synchronize on every attempt.
+		if known {
+			prev.LastSync = st.LastSync
+		}
+	}
+
+	booked, err := w.newTransactions(ctx, a.ID, st, known)
+	if err != nil {
+		return nil, fmt.Errorf("list transactions: %w", err)
+	}
+
+	pending, err := w.Source.ListPendingTransactions(ctx, a.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list pending transactions: %w", err)
+	}
+
+	events := make([]Event, 0, len(booked))
+	for _, tx := range booked {
+		events = append(events, transactionEvent(w.Profile, a, tx))
+	}
+
+	seen := make([]string, 0, len(pending))
+
+	for _, tx := range pending {
+		if known && !slices.Contains(st.Pending, tx.ID) {
+			events = append(events, pendingEvent(w.Profile, a, tx))
+		}
+
+		seen = append(seen, tx.ID)
+	}
+
+	// Only the currently pending IDs are kept: once booked, a pending
+	// transaction is reported again as a transaction.
+	st.Pending = seen
+	w.State.Accounts[a.ID] = st
+
+	return events, nil
+}
+
+// sync synchronizes the account's transactions unless it was synchronized
+// within the cooldown. A failed synchronization is logged, not fatal: the
+// poll still picks up what Ponto already has.
+func (w *Watcher) sync(ctx context.Context, a api.Account, st *AccountState) {
+	now := w.now()
+	if !st.LastSync.IsZero() && now.Sub(st.LastSync) < w.SyncCooldown {
+		slog.Debug("sync cooling down", "account", a.ID, "last", st.LastSync)
+
+		return
+	}
+
+	st.LastSync = now
+
+	sync, err := w.Source.CreateSync(ctx, a.ID, "accountTransactions")
+	if err == nil {
+		var done *api.Synchronization
+		if done, err = w.Source.WaitForSync(ctx, sync.ID); err == nil {
+			sync = done
+		}
+	}
+
+	switch {
+	case err != nil:
+		slog.Warn("sync failed", "account", a.ID, "error", err)
+	case sync.Status != "success":
+		slog.Warn("sync finished", "account", a.ID, "status", sync.Status)
+	}
+
+	if w.OnSync != nil && ctx.Err() == nil {
+		w.OnSync(syncResult(w.Profile, a, sync, err))
+	}
+}
+
+// newTransactions pages through the account's transactions, newest first,
+// until it reaches the last seen one, and moves the mark to the newest. For
+// an account that is not known yet it only sets the mark.
+func (w *Watcher) newTransactions(ctx context.Context, accountID string, st *AccountState, known bool) ([]api.Transaction, error) {
+	opts := api.TransactionListOptions{}
+
+	switch {
+	case !known:
+		opts.Limit = 1
+	case st.LastValueDate != "":
+		if d, err := time.Parse(time.DateOnly, day(st.LastValueDate)); err == nil {
+			opts.Since = d.Add(-lookback).Format(time.DateOnly)
+		}
+	}
+
+	var txns []api.Transaction
+
+	err := w.Source.EachTransactionPage(ctx, accountID, opts, func(page []api.Transaction) error {
+		for _, tx := range page {
+			if tx.ID == st.LastTransactionID {
+				return errFoundMark
+			}
+
+			txns = append(txns, tx)
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errFoundMark) {
+		return nil, err
+	}
+
+	if len(txns) == 0 {
+		return nil, nil
+	}
+
+	st.LastTransactionID = txns[0].ID
+	st.LastValueDate = txns[0].ValueDate
+
+	if !known {
+		return nil, nil
+	}
+
+	slices.Reverse(txns)
+
+	return txns, nil
+}
+
+func (w *Watcher) now() time.Time {
+	if w.Now != nil {
+		return w.Now()
+	}
+
+	return time.Now()
+}
+
+func day(s string) string {
+	if len(s) >= 10 {
+		return s[:10]
+	}
+
+	return s
+}
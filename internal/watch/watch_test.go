@@ -0,0 +1,259 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/dedene/ponto-cli/internal/api"
+)
+
+type fakeSource struct {
+	accounts []api.Account
+	txns     map[string][]api.Transaction // newest first
+	pending  map[string][]api.PendingTransaction
+	failTxns bool
+	failPend bool
+	syncs    []string
+	pageSize int
+}
+
+func (s *fakeSource) ListAccounts(context.Context) ([]api.Account, error) {
+	return s.accounts, nil
+}
+
+func (s *fakeSource) EachTransactionPage(_ context.Context, accountID string, opts api.TransactionListOptions, fn func([]api.Transaction) error) error {
+	if s.failTxns {
+		return errors.New("boom")
+	}
+
+	txns := s.txns[accountID]
+	if opts.Limit > 0 && len(txns) > opts.Limit {
+		txns = txns[:opts.Limit]
+	}
+
+	for len(txns) > 0 {
+		n := min(s.pageSize, len(txns))
+		if err := fn(txns[:n]); err != nil {
+			return err
+		}
+
+		txns = txns[n:]
+	}
+
+	return nil
+}
+
+func (s *fakeSource) ListPendingTransactions(_ context.Context, accountID string) ([]api.PendingTransaction, error) {
+	if s.failPend {
+		return nil, errors.New("boom")
+	}
+
+	return s.pending[accountID], nil
+}
+
+func (s *fakeSource) CreateSync(_ context.Context, accountID, _ string) (*api.Synchronization, error) {
+	s.syncs = append(s.syncs, accountID)
+
+	return &api.Synchronization{ID: "s-" + accountID, Status: "pending"}, nil
+}
+
+func (s *fakeSource) WaitForSync(_ context.Context, id string) (*api.Synchronization, error) {
+	return &api.Synchronization{ID: id, Status: "success"}, nil
+}
+
+func tx(id, valueDate string) api.Transaction {
+	return api.Transaction{ID: id, Amount: 1, ValueDate: valueDate, ExecutionDate: valueDate}
+}
+
+func ids(events []Event) []string {
+	out := make([]string, len(events))
+	for i, e := range events {
+		out[i] = e.Type + ":" + e.ID
+	}
+
+	return out
+}
+
+func TestWatcherPoll(t *testing.T) {
+	t.Parallel()
+
+	src := &fakeSource{
+		accounts: []api.Account{{ID: "a1", Description: "Main"}, {ID: "a2"}},
+		txns:     map[string][]api.Transaction{"a1": {tx("t2", "2024-03-02"), tx("t1", "2024-03-01")}},
+		pending:  map[string][]api.PendingTransaction{"a1": {{ID: "p1"}}},
+		pageSize: 2,
+	}
+
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := &Watcher{Source: src, State: state, Profile: "default", AccountIDs: []string{"a1"}}
+
+	events, err := w.Poll(context.Background())
+	if err != nil || len(events) != 0 {
+		t.Fatalf("first poll = %v, %v; want no events", ids(events), err)
+	}
+
+	if got := state.Accounts["a1"].LastTransactionID; got != "t2" {
+		t.Errorf("mark after first poll = %q, want t2", got)
+	}
+
+	if _, ok := state.Accounts["a2"]; ok {
+		t.Error("a2 is not watched but has state")
+	}
+
+	src.txns["a1"] = append([]api.Transaction{tx("t5", "2024-03-05"), tx("t4", "2024-03-04"), tx("t3", "2024-03-03")}, src.txns["a1"]...)
+	src.pending["a1"] = []api.PendingTransaction{{ID: "p1"}, {ID: "p2"}}
+
+	events, err = w.Poll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"transaction:t3", "transaction:t4", "transaction:t5", "pendingTransaction:p2"}
+	if !slices.Equal(ids(events), want) {
+		t.Errorf("second poll = %v, want %v", ids(events), want)
+	}
+
+	if events[0].Account != "Main" || events[0].Profile != "default" {
+		t.Errorf("event = %+v, want account Main of profile default", events[0])
+	}
+
+	events, err = w.Poll(context.Background())
+	if err != nil || len(events) != 0 {
+		t.Errorf("third poll = %v, %v; want no events", ids(events), err)
+	}
+}
+
+func TestWatcherFailedFirstPoll(t *testing.T) {
+	t.Parallel()
+
+	src := &fakeSource{
+		accounts: []api.Account{{ID: "a1"}},
+		txns:     map[string][]api.Transaction{"a1": {tx("t1", "2024-03-01")}},
+		failTxns: true,
+		pageSize: 10,
+	}
+
+	state, _ := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	w := &Watcher{Source: src, State: state}
+
+	if _, err := w.Poll(context.Background()); err == nil {
+		t.Fatal("poll succeeded, want the transactions error")
+	}
+
+	src.failTxns = false
+
+	events, err := w.Poll(context.Background())
+	if err != nil || len(events) != 0 {
+		t.Errorf("poll after failure = %v, %v; want history not to be reported", ids(events), err)
+	}
+}
+
+func TestWatcherFailedPendingKeepsMarks(t *testing.T) {
+	t.Parallel()
+
+	src := &fakeSource{
+		accounts: []api.Account{{ID: "a1"}},
+		txns:     map[string][]api.Transaction{"a1": {tx("t1", "2024-03-01")}},
+		pageSize: 10,
+	}
+
+	state, _ := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	w := &Watcher{Source: src, State: state}
+
+	if _, err := w.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	saved := state.Clone()
+
+	src.txns["a1"] = append([]api.Transaction{tx("t2", "2024-03-02")}, src.txns["a1"]...)
+	src.failPend = true
+
+	if _, err := w.Poll(context.Background()); err == nil {
+		t.Fatal("poll succeeded, want the pending transactions error")
+	}
+
+	if got := state.Accounts["a1"].LastTransactionID; got != "t1" {
+		t.Errorf("mark after failed poll = %q, want t1", got)
+	}
+
+	src.failPend = false
+
+	events, err := w.Poll(context.Background())
+	if err != nil || !slices.Equal(ids(events), []string{"transaction:t2"}) {
+		t.Errorf("poll after failure = %v, %v; want t2 reported", ids(events), err)
+	}
+
+	if got := saved.Accounts["a1"].LastTransactionID; got != "t1" {
+		t.Errorf("clone mark = %q, want t1 unaffected by later polls", got)
+	}
+}
+
+func TestWatcherSyncCooldown(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	src := &fakeSource{accounts: []api.Account{{ID: "a1"}}, pageSize: 10}
+	state, _ := LoadState(filepath.Join(t.TempDir(), "state.json"))
+
+	var results []SyncResult
+
+	w := &Watcher{
+		Source:       src,
+		State:        state,
+		Sync:         true,
+		SyncCooldown: 30 * time.Minute,
+		Now:          func() time.Time { return now },
+		OnSync:       func(r SyncResult) { results = append(results, r) },
+	}
+
+	for _, step := range []time.Duration{0, 10 * time.Minute, 25 * time.Minute} {
+		now = now.Add(step)
+
+		if _, err := w.Poll(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(src.syncs) != 2 {
+		t.Errorf("synced %d times over 35 minutes, want 2 with a 30m cooldown", len(src.syncs))
+	}
+
+	if len(results) != 2 || results[0].Status != "success" || results[0].ID != "s-a1" {
+		t.Errorf("sync results = %+v, want 2 successes of s-a1", results)
+	}
+}
+
+func TestStateRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "watch", "default.json")
+
+	state, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state.Accounts["a1"] = &AccountState{LastTransactionID: "t1", Pending: []string{"p1"}}
+
+	if err := state.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := loaded.Accounts["a1"]; got == nil || got.LastTransactionID != "t1" || !slices.Equal(got.Pending, []string{"p1"}) {
+		t.Errorf("loaded state = %+v, want the saved marks", got)
+	}
+}
@@ -0,0 +1,100 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dedene/ponto-cli/internal/api"
+)
+
+// Event is a decoded webhook, e.g. pontoConnect.account.transactionsCreated
+// or pontoConnect.synchronization.failed. The IDs of the resources it
+// refers to are lifted out of its relationships and attributes.
+type Event struct {
+	ID                string         `json:"id"`
+	Type              string         `json:"type"`
+	CreatedAt         string         `json:"createdAt,omitempty"`
+	AccountID         string         `json:"accountId,omitempty"`
+	SynchronizationID string         `json:"synchronizationId,omitempty"`
+	OrganizationID    string         `json:"organizationId,omitempty"`
+	Attributes        map[string]any `json:"attributes,omitempty"`
+}
+
+type payload struct {
+	Data struct {
+		ID            string         `json:"id"`
+		Type          string         `json:"type"`
+		Attributes    map[string]any `json:"attributes"`
+		Relationships map[string]struct {
+			Data struct {
+				ID string `json:"id"`
+			} `json:"data"`
+		} `json:"relationships"`
+	} `json:"data"`
+}
+
+// Decode parses a webhook body.
+func Decode(body []byte) (*Event, error) {
+	var p payload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("decode webhook: %w", err)
+	}
+
+	if p.Data.Type == "" {
+		return nil, errors.New("decode webhook: no event type")
+	}
+
+	e := &Event{ID: p.Data.ID, Type: p.Data.Type, Attributes: p.Data.Attributes}
+
+	ref := func(name string) string {
+		if r, ok := p.Data.Relationships[name]; ok && r.Data.ID != "" {
+			return r.Data.ID
+		}
+
+		s, _ := p.Data.Attributes[name+"Id"].(string)
+
+		return s
+	}
+
+	e.AccountID = ref("account")
+	e.SynchronizationID = ref("synchronization")
+	e.OrganizationID = ref("organization")
+	e.CreatedAt, _ = p.Data.Attributes["createdAt"].(string)
+
+	return e, nil
+}
+
+// Source is the part of api.Client that Fetch needs.
+type Source interface {
+	GetAccount(ctx context.Context, id string) (*api.Account, error)
+	ListTransactions(ctx context.Context, accountID string, opts api.TransactionListOptions) ([]api.Transaction, error)
+	ListPendingTransactions(ctx context.Context, accountID string) ([]api.PendingTransaction, error)
+	GetSync(ctx context.Context, id string) (*api.Synchronization, error)
+}
+
+// Fetch loads the resources e refers to: the account's latest transactions
+// (as many as the event's count) for transactionsCreated, its pending
+// transactions for pending events, the synchronization for synchronization
+// events and the account for other account events. Other events have
+// nothing to fetch and return nil.
+func Fetch(ctx context.Context, src Source, e *Event) (any, error) {
+	kind := e.Type[strings.LastIndex(e.Type, ".")+1:]
+
+	switch {
+	case e.AccountID != "" && kind == "transactionsCreated":
+		count, _ := e.Attributes["count"].(float64)
+
+		return src.ListTransactions(ctx, e.AccountID, api.TransactionListOptions{Limit: max(int(count), 1)})
+	case e.AccountID != "" && strings.HasPrefix(kind, "pendingTransactions"):
+		return src.ListPendingTransactions(ctx, e.AccountID)
+	case e.SynchronizationID != "" && strings.Contains(e.Type, ".synchronization."):
+		return src.GetSync(ctx, e.SynchronizationID)
+	case e.AccountID != "" && strings.Contains(e.Type, ".account.") && kind != "transactionsUpdated":
+		return src.GetAccount(ctx, e.AccountID)
+	default:
+		return nil, nil
+	}
+}
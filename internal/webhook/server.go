@@ -0,0 +1,129 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// maxBodySize bounds the webhook bodies the handler reads.
+const maxBodySize = 1 << 20
+
+// Capture is a received webhook saved for replaying with `webhooks verify`.
+// The body is kept verbatim because the signature covers its exact bytes.
+type Capture struct {
+	ReceivedAt time.Time `json:"receivedAt"`
+	Signature  string    `json:"signature"`
+	Body       string    `json:"body"`
+}
+
+// ReadCapture reads a capture file.
+func ReadCapture(path string) (*Capture, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read capture: %w", err)
+	}
+
+	var c Capture
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("parse capture %s: %w", path, err)
+	}
+
+	return &c, nil
+}
+
+// Save writes the capture to dir, named after the time it was received.
+func (c *Capture) Save(dir string) (string, error) {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal capture: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("create capture dir: %w", err)
+	}
+
+	path := filepath.Join(dir, c.ReceivedAt.UTC().Format("20060102T150405.000000000Z")+".json")
+
+	if err := os.WriteFile(path, append(b, '\n'), 0o600); err != nil {
+		return "", fmt.Errorf("write capture: %w", err)
+	}
+
+	return path, nil
+}
+
+// Handler is the http.Handler of `webhooks serve`. It verifies each POST,
+// decodes the event and passes it to Handle. Invalid signatures get 401,
+// undecodable bodies 400 and Handle errors 502, so Ponto retries them.
+type Handler struct {
+	Verifier *Verifier
+
+	// CaptureDir, when set, receives a Capture of every request, valid or
+	// not.
+	CaptureDir string
+
+	Handle func(ctx context.Context, e *Event) error
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, "read body", http.StatusRequestEntityTooLarge)
+
+		return
+	}
+
+	signature := r.Header.Get(SignatureHeader)
+
+	if h.CaptureDir != "" {
+		c := &Capture{ReceivedAt: time.Now(), Signature: signature, Body: string(body)}
+		if path, err := c.Save(h.CaptureDir); err != nil {
+			slog.Warn("capture webhook", "error", err)
+		} else {
+			slog.Info("captured webhook", "path", path)
+		}
+	}
+
+	if err := h.Verifier.Verify(r.Context(), body, signature); err != nil {
+		slog.Warn("rejected webhook", "remote", r.RemoteAddr, "error", err)
+
+		status := http.StatusUnauthorized
+		if !errors.Is(err, ErrInvalidSignature) {
+			status = http.StatusInternalServerError
+		}
+
+		http.Error(w, err.Error(), status)
+
+		return
+	}
+
+	e, err := Decode(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	if err := h.Handle(r.Context(), e); err != nil {
+		slog.Warn("handle webhook", "id", e.ID, "type", e.Type, "error", err)
+		http.Error(w, "handle event", http.StatusBadGateway)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,261 @@
+// Package webhook verifies and decodes the webhooks Ponto sends.
+package webhook
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dedene/ponto-cli/internal/api"
+)
+
+// SignatureHeader is the request header holding a webhook's signature.
+const SignatureHeader = "Signature"
+
+const (
+	// leeway absorbs clock skew when checking the signature's timestamps.
+	leeway = time.Minute
+
+	// refreshInterval limits how often unknown key IDs trigger a reload.
+	refreshInterval = time.Minute
+)
+
+// ErrInvalidSignature is wrapped by every verification failure.
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Verifier checks webhook signatures. A signature is an RS512 JWT whose
+// "digest" claim is the SHA-512 of the request body, signed with one of the
+// keys Ponto publishes.
+type Verifier struct {
+	// Keys loads the signing keys. It is called again when a signature
+	// uses a key ID that is not known yet, so rotated keys are picked up.
+	Keys func(ctx context.Context) ([]api.WebhookKey, error)
+
+	// Audience is the client ID the webhook must be addressed to; empty
+	// accepts any.
+	Audience string
+
+	// SkipExpiry accepts expired signatures, to replay captured webhooks.
+	SkipExpiry bool
+
+	// Now returns the current time; nil means time.Now.
+	Now func() time.Time
+
+	mu      sync.Mutex
+	keys    map[string]*rsa.PublicKey
+	fetched time.Time
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type jwtClaims struct {
+	Aud    audience `json:"aud"`
+	Iat    int64    `json:"iat"`
+	Exp    int64    `json:"exp"`
+	Digest string   `json:"digest"`
+}
+
+// audience is a JWT "aud" claim, a string or a list of strings.
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var one string
+	if err := json.Unmarshal(b, &one); err == nil {
+		*a = audience{one}
+
+		return nil
+	}
+
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return fmt.Errorf("aud: %w", err)
+	}
+
+	*a = many
+
+	return nil
+}
+
+// loadError is a failure to load the keys, which says nothing about the
+// signature.
+type loadError struct{ err error }
+
+func (e *loadError) Error() string { return "load webhook keys: " + e.err.Error() }
+func (e *loadError) Unwrap() error { return e.err }
+
+// Verify checks signature against body. Errors wrap ErrInvalidSignature
+// unless the keys could not be loaded.
+func (v *Verifier) Verify(ctx context.Context, body []byte, signature string) error {
+	err := v.verify(ctx, body, strings.TrimSpace(signature))
+
+	var le *loadError
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &le):
+		return err
+	default:
+		return fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+}
+
+func (v *Verifier) verify(ctx context.Context, body []byte, token string) error {
+	if token == "" {
+		return errors.New("no signature")
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return errors.New("signature is not a JWT")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return fmt.Errorf("header: %w", err)
+	}
+
+	if header.Alg != "RS512" {
+		return fmt.Errorf("unsupported algorithm %q", header.Alg)
+	}
+
+	key, err := v.key(ctx, header.Kid)
+	if err != nil {
+		return err
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("decode signature: %w", err)
+	}
+
+	sum := sha512.Sum512([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA512, sum[:], sig); err != nil {
+		return errors.New("signature does not match the key")
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return fmt.Errorf("claims: %w", err)
+	}
+
+	digest := sha512.Sum512(body)
+	if claims.Digest != "SHA-512="+base64.StdEncoding.EncodeToString(digest[:]) {
+		return errors.New("body does not match the signed digest")
+	}
+
+	if v.Audience != "" && !slices.Contains(claims.Aud, v.Audience) {
+		return fmt.Errorf("addressed to %v, not client %s", []string(claims.Aud), v.Audience)
+	}
+
+	now := v.now()
+
+	if claims.Iat != 0 && time.Unix(claims.Iat, 0).After(now.Add(leeway)) {
+		return errors.New("issued in the future")
+	}
+
+	if !v.SkipExpiry && claims.Exp != 0 && now.After(time.Unix(claims.Exp, 0).Add(leeway)) {
+		return fmt.Errorf("expired at %s", time.Unix(claims.Exp, 0).UTC().Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// key returns the public key with ID kid, reloading the keys when it is
+// not known.
+func (v *Verifier) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+
+	if v.keys != nil && v.now().Sub(v.fetched) < refreshInterval {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+
+	keys, err := v.Keys(ctx)
+	if err != nil {
+		return nil, &loadError{err}
+	}
+
+	v.keys = make(map[string]*rsa.PublicKey, len(keys))
+	v.fetched = v.now()
+
+	for _, k := range keys {
+		pub, err := publicKey(k)
+		if err != nil {
+			slog.Warn("skipping webhook key", "kid", k.ID, "error", err)
+
+			continue
+		}
+
+		v.keys[k.ID] = pub
+	}
+
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unknown key %q", kid)
+}
+
+func (v *Verifier) now() time.Time {
+	if v.Now != nil {
+		return v.Now()
+	}
+
+	return time.Now()
+}
+
+// publicKey builds the RSA public key of a JWK.
+func publicKey(k api.WebhookKey) (*rsa.PublicKey, error) {
+	if k.Kty != "" && k.Kty != "RSA" {
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("decode modulus: %w", err)
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("decode exponent: %w", err)
+	}
+
+	exp := new(big.Int).SetBytes(e)
+	if len(n) == 0 || !exp.IsInt64() || exp.Int64() < 3 || exp.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid RSA key")
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+}
+
+func decodeSegment(seg string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("parse: %w", err)
+	}
+
+	return nil
+}
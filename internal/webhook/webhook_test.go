@@ -0,0 +1,308 @@
+package webhook
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dedene/ponto-cli/internal/api"
+)
+
+const transactionsCreated = `{"data":{"type":"pontoConnect.account.transactionsCreated","id":"ev1",` +
+	`"attributes":{"count":2,"createdAt":"2024-03-01T10:00:00Z","synchronizationId":"s1"},` +
+	`"relationships":{"account":{"data":{"type":"account","id":"a1"}},"organization":{"data":{"type":"organization","id":"o1"}}}}}`
+
+var testKey = func() *rsa.PrivateKey {
+	k, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+
+	return k
+}()
+
+func jwk(kid string, k *rsa.PublicKey) api.WebhookKey {
+	return api.WebhookKey{
+		ID:  kid,
+		Kty: "RSA",
+		Alg: "RS512",
+		N:   base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+	}
+}
+
+// sign returns a signature of body in the shape Ponto sends.
+func sign(t *testing.T, kid string, body []byte, claims map[string]any) string {
+	t.Helper()
+
+	digest := sha512.Sum512(body)
+	all := map[string]any{
+		"aud":    "client-1",
+		"iat":    time.Now().Unix(),
+		"exp":    time.Now().Add(5 * time.Minute).Unix(),
+		"digest": "SHA-512=" + base64.StdEncoding.EncodeToString(digest[:]),
+	}
+
+	for k, v := range claims {
+		all[k] = v
+	}
+
+	seg := func(v any) string {
+		b, _ := json.Marshal(v)
+
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+
+	signed := seg(map[string]string{"alg": "RS512", "kid": kid, "typ": "JWT"}) + "." + seg(all)
+	sum := sha512.Sum512([]byte(signed))
+
+	sig, err := rsa.SignPKCS1v15(rand.Reader, testKey, crypto.SHA512, sum[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// forge returns the claims of signed under the signature of other.
+func forge(other, signed string) string {
+	o, s := strings.Split(other, "."), strings.Split(signed, ".")
+
+	return o[0] + "." + s[1] + "." + o[2]
+}
+
+func newVerifier(loads *int) *Verifier {
+	return &Verifier{
+		Audience: "client-1",
+		Keys: func(context.Context) ([]api.WebhookKey, error) {
+			*loads++
+
+			return []api.WebhookKey{jwk("k1", &testKey.PublicKey)}, nil
+		},
+	}
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(transactionsCreated)
+	expired := time.Now().Add(-time.Hour).Unix()
+
+	tests := []struct {
+		name      string
+		body      []byte
+		signature string
+		skipExp   bool
+		wantErr   string
+	}{
+		{name: "valid", body: body, signature: sign(t, "k1", body, nil)},
+		{name: "tampered body", body: []byte(strings.Replace(transactionsCreated, `"count":2`, `"count":3`, 1)), signature: sign(t, "k1", body, nil), wantErr: "digest"},
+		{name: "other client", body: body, signature: sign(t, "k1", body, map[string]any{"aud": "client-2"}), wantErr: "not client client-1"},
+		{name: "audience list", body: body, signature: sign(t, "k1", body, map[string]any{"aud": []string{"x", "client-1"}})},
+		{name: "expired", body: body, signature: sign(t, "k1", body, map[string]any{"exp": expired}), wantErr: "expired"},
+		{name: "expired replay", body: body, signature: sign(t, "k1", body, map[string]any{"exp": expired}), skipExp: true},
+		{name: "unknown key", body: body, signature: sign(t, "k2", body, nil), wantErr: `unknown key "k2"`},
+		{name: "forged claims", body: body, signature: forge(sign(t, "k1", body, nil), sign(t, "k1", body, map[string]any{"aud": "client-2"})), wantErr: "does not match the key"},
+		{name: "missing", body: body, wantErr: "no signature"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var loads int
+
+			v := newVerifier(&loads)
+			v.SkipExpiry = tt.skipExp
+
+			err := v.Verify(context.Background(), tt.body, tt.signature)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Verify() error = %v", err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("Verify() error = %v, want invalid signature with %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifierReloadsKeys(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	loads := 0
+	v := newVerifier(&loads)
+	v.Now = func() time.Time { return now }
+
+	body := []byte(transactionsCreated)
+	for range 3 {
+		_ = v.Verify(context.Background(), body, sign(t, "k1", body, nil))
+		_ = v.Verify(context.Background(), body, sign(t, "rotated", body, nil))
+	}
+
+	if loads != 1 {
+		t.Errorf("keys loaded %d times within a minute, want 1", loads)
+	}
+
+	now = now.Add(2 * refreshInterval)
+	_ = v.Verify(context.Background(), body, sign(t, "rotated", body, nil))
+
+	if loads != 2 {
+		t.Errorf("keys loaded %d times, want a reload for an unknown key after %s", loads, refreshInterval)
+	}
+}
+
+type fakeSource struct{}
+
+func (fakeSource) GetAccount(_ context.Context, id string) (*api.Account, error) {
+	return &api.Account{ID: id}, nil
+}
+
+func (fakeSource) ListTransactions(_ context.Context, _ string, opts api.TransactionListOptions) ([]api.Transaction, error) {
+	return make([]api.Transaction, opts.Limit), nil
+}
+
+func (fakeSource) ListPendingTransactions(context.Context, string) ([]api.PendingTransaction, error) {
+	return []api.PendingTransaction{{ID: "p1"}}, nil
+}
+
+func (fakeSource) GetSync(_ context.Context, id string) (*api.Synchronization, error) {
+	return &api.Synchronization{ID: id}, nil
+}
+
+func TestDecodeAndFetch(t *testing.T) {
+	t.Parallel()
+
+	e, err := Decode([]byte(transactionsCreated))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.ID != "ev1" || e.AccountID != "a1" || e.SynchronizationID != "s1" || e.OrganizationID != "o1" || e.CreatedAt != "2024-03-01T10:00:00Z" {
+		t.Errorf("Decode() = %+v", e)
+	}
+
+	tests := []struct {
+		event *Event
+		want  string
+	}{
+		{event: e, want: "[]api.Transaction 2"},
+		{event: &Event{Type: "pontoConnect.account.pendingTransactionsCreated", AccountID: "a1"}, want: "[]api.PendingTransaction 1"},
+		{event: &Event{Type: "pontoConnect.synchronization.failed", SynchronizationID: "s1", AccountID: "a1"}, want: "*api.Synchronization s1"},
+		{event: &Event{Type: "pontoConnect.account.detailsUpdated", AccountID: "a1"}, want: "*api.Account a1"},
+		{event: &Event{Type: "pontoConnect.organization.blocked", OrganizationID: "o1"}, want: "<nil>"},
+	}
+
+	for _, tt := range tests {
+		got, err := Fetch(context.Background(), fakeSource{}, tt.event)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		desc := fmt.Sprintf("%T", got)
+
+		switch v := got.(type) {
+		case []api.Transaction:
+			desc = fmt.Sprintf("%s %d", desc, len(v))
+		case []api.PendingTransaction:
+			desc = fmt.Sprintf("%s %d", desc, len(v))
+		case *api.Synchronization:
+			desc += " " + v.ID
+		case *api.Account:
+			desc += " " + v.ID
+		case nil:
+			desc = "<nil>"
+		}
+
+		if desc != tt.want {
+			t.Errorf("Fetch(%s) = %s, want %s", tt.event.Type, desc, tt.want)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	var (
+		loads    int
+		received []*Event
+	)
+
+	captures := t.TempDir()
+	h := &Handler{
+		Verifier:   newVerifier(&loads),
+		CaptureDir: captures,
+		Handle: func(_ context.Context, e *Event) error {
+			received = append(received, e)
+
+			return nil
+		},
+	}
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	body := []byte(transactionsCreated)
+
+	post := func(signature string) int {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(string(body)))
+		req.Header.Set(SignatureHeader, signature)
+
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp.Body.Close()
+
+		return resp.StatusCode
+	}
+
+	if got := post(sign(t, "k1", body, nil)); got != http.StatusAccepted {
+		t.Errorf("valid webhook status = %d, want 202", got)
+	}
+
+	if got := post("bogus"); got != http.StatusUnauthorized {
+		t.Errorf("bogus signature status = %d, want 401", got)
+	}
+
+	if len(received) != 1 || received[0].Type != "pontoConnect.account.transactionsCreated" {
+		t.Errorf("handled %v, want only the valid event", received)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(captures, "*.json"))
+	if len(files) != 2 {
+		t.Fatalf("captured %d requests, want 2", len(files))
+	}
+
+	c, err := ReadCapture(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Body != transactionsCreated {
+		t.Errorf("capture body = %q, want the request body verbatim", c.Body)
+	}
+
+	if err := h.Verifier.Verify(context.Background(), []byte(c.Body), c.Signature); err != nil {
+		t.Errorf("replaying the capture: %v", err)
+	}
+}